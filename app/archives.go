@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"nextui-game-manager/models"
+	"nextui-game-manager/state"
+	"nextui-game-manager/ui"
+	"nextui-game-manager/utils"
+)
+
+func init() {
+	registerTransition(models.ScreenNames.ArchiveList, handleArchiveListTransition)
+	registerTransition(models.ScreenNames.ArchiveManagement, handleArchiveManagementTransition)
+	registerTransition(models.ScreenNames.ArchiveGamesList, handleArchiveGamesListTransition)
+	registerTransition(models.ScreenNames.ArchiveOptions, handleArchiveOptionsTransition)
+}
+
+func handleArchiveListTransition(_ ui.ArchiveListScreen, result interface{}, code int) state.Navigation {
+	switch code {
+	case ExitCodeSuccess:
+		return state.Push(ui.InitArchiveManagementScreen(result.(shared.RomDirectory)))
+	default:
+		return state.Pop()
+	}
+}
+
+func handleArchiveManagementTransition(ams ui.ArchiveManagementScreen, result interface{}, code int) state.Navigation {
+	switch code {
+	case ExitCodeSuccess:
+		return state.Push(ui.InitArchiveGamesListScreen(ams.Archive, result.(shared.RomDirectory), ""))
+	case ExitCodeAction:
+		return state.Push(ui.InitArchiveOptionsScreen(ams.Archive))
+	default:
+		return state.Pop()
+	}
+}
+
+func handleArchiveGamesListTransition(agl ui.ArchiveGamesListScreen, result interface{}, code int) state.Navigation {
+	switch code {
+	case ExitCodeSuccess:
+		newRomDirectory := result.(shared.RomDirectory)
+
+		if newRomDirectory.Path != "" {
+			return state.Push(ui.InitArchiveGamesListScreenWithPreviousDirectory(agl.Archive, newRomDirectory, agl.RomDirectory, ""))
+		}
+
+		// Games were restored out of this list
+		return state.Redraw().ResetCursor()
+	case ExitCodeCancel:
+		if agl.PreviousRomDirectory.Path == "" && agl.SearchFilter != "" {
+			return state.Replace(ui.InitArchiveGamesListScreenWithPreviousDirectory(agl.Archive, agl.RomDirectory, agl.PreviousRomDirectory, "")).ResetCursor()
+		}
+
+		return state.Pop()
+	case ExitCodeAction, ExitCodeError:
+		searchFilter, _ := result.(string)
+
+		if searchFilter != "" {
+			return state.Replace(ui.InitArchiveGamesListScreenWithPreviousDirectory(agl.Archive, agl.RomDirectory, agl.PreviousRomDirectory, searchFilter)).ResetCursor()
+		}
+
+		return state.Replace(ui.InitArchiveGamesListScreenWithPreviousDirectory(agl.Archive, agl.RomDirectory, agl.PreviousRomDirectory, ""))
+	case ExitCodeEmpty:
+		if agl.SearchFilter != "" {
+			utils.ShowTimedMessage(fmt.Sprintf("No results found for %s!", agl.SearchFilter), shortMessageDelay)
+			return state.Replace(ui.InitArchiveGamesListScreenWithPreviousDirectory(agl.Archive, agl.RomDirectory, agl.PreviousRomDirectory, "")).ResetCursor()
+		}
+
+		utils.ShowTimedMessage(fmt.Sprintf("%s is empty!", agl.RomDirectory.DisplayName), longMessageDelay)
+		return state.Pop()
+	default:
+		return state.PopTo(models.ScreenNames.ArchiveManagement)
+	}
+}
+
+func handleArchiveOptionsTransition(aos ui.ArchiveOptionsScreen, result interface{}, code int) state.Navigation {
+	switch code {
+	case ExitCodeError, ExitCodeAction:
+		if result != nil {
+			// TODO: Update position around renamed archive
+			return state.Replace(ui.InitArchiveOptionsScreen(result.(shared.RomDirectory))).ResetCursor()
+		}
+		return state.Redraw()
+	case ExitCodeSuccess:
+		return state.PopTo(models.ScreenNames.ArchiveList)
+	default:
+		// The archive may have been renamed while its options were open
+		return state.Return(func(ams ui.ArchiveManagementScreen) models.Screen {
+			ams.Archive = aos.Archive
+			return ams
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"nextui-game-manager/models"
+	"nextui-game-manager/state"
+	"nextui-game-manager/ui"
+)
+
+func init() {
+	registerTransition(models.ScreenNames.CollectionsList, handleCollectionsListTransition)
+	registerTransition(models.ScreenNames.CollectionManagement, handleCollectionManagementTransition)
+	registerTransition(models.ScreenNames.CollectionOptions, handleCollectionOptionsTransition)
+}
+
+func handleCollectionsListTransition(_ ui.CollectionListScreen, result interface{}, code int) state.Navigation {
+	switch code {
+	case ExitCodeSuccess:
+		return state.Push(ui.InitCollectionManagement(result.(models.Collection)))
+	default:
+		return state.Pop()
+	}
+}
+
+func handleCollectionManagementTransition(cm ui.CollectionManagement, result interface{}, code int) state.Navigation {
+	switch code {
+	case ExitCodeSuccess:
+		//TODO: Update position around deleted game
+		return state.Replace(ui.InitCollectionManagement(result.(models.Collection))).ResetCursor()
+	case ExitCodeAction:
+		return state.Push(ui.InitCollectionOptions(cm.Collection, cm.SearchFilter))
+	default:
+		return state.Pop()
+	}
+}
+
+func handleCollectionOptionsTransition(co ui.CollectionOptionsScreen, result interface{}, code int) state.Navigation {
+	switch code {
+	case ExitCodeSuccess:
+		return state.PopTo(models.ScreenNames.CollectionsList, models.ScreenNames.GlobalSearch)
+	case ExitCodeCancel:
+		// The collection may have been renamed while its options were open
+		return state.Return(func(cm ui.CollectionManagement) models.Screen {
+			cm.Collection = co.Collection
+			return cm
+		})
+	case ExitCodeAction:
+		//TODO: Update position around renamed collection
+		return state.Replace(ui.InitCollectionOptions(result.(models.Collection), co.SearchFilter)).ResetCursor()
+	default:
+		return state.PopTo(models.ScreenNames.CollectionsList)
+	}
+}
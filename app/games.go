@@ -0,0 +1,407 @@
+package main
+
+import (
+	"fmt"
+	gaba "github.com/UncleJunVIP/gabagool/pkg/gabagool"
+	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"go.uber.org/zap"
+	"nextui-game-manager/models"
+	"nextui-game-manager/state"
+	"nextui-game-manager/ui"
+	"nextui-game-manager/utils"
+	"qlova.tech/sum"
+	"strings"
+)
+
+func init() {
+	registerTransition(models.ScreenNames.GamesList, handleGamesListTransition)
+	registerTransition(models.ScreenNames.SearchBox, handleSearchBoxTransition)
+	registerTransition(models.ScreenNames.Actions, handleActionsTransition)
+	registerTransition(models.ScreenNames.BulkActions, handleBulkActionsTransition)
+	registerTransition(models.ScreenNames.AddToCollection, handleAddToCollectionTransition)
+	registerTransition(models.ScreenNames.CollectionCreate, handleCollectionCreateTransition)
+	registerTransition(models.ScreenNames.DownloadArt, handleDownloadArtTransition)
+	registerTransition(models.ScreenNames.AddToArchive, handleAddToArchiveTransition)
+	registerTransition(models.ScreenNames.ArchiveCreate, handleArchiveCreateTransition)
+}
+
+func handleGamesListTransition(gl ui.GameList, result interface{}, code int) state.Navigation {
+	switch code {
+	case ExitCodeSuccess:
+		return handleGameSelection(gl, result)
+	case ExitCodeCancel:
+		return handleGameListBack(gl)
+	case ExitCodeAction:
+		return state.Push(ui.InitSearch(gl.RomDirectory))
+	case ExitCodeEmpty:
+		return handleEmptyGamesList(gl)
+	case ui.GameListSortExitCode:
+		return changeGameListSort(gl, result.(string))
+	default:
+		return state.PopToRoot()
+	}
+}
+
+func changeGameListSort(gl ui.GameList, sortKey string) state.Navigation {
+	logger := common.GetLoggerInstance()
+	appState := state.GetAppState()
+
+	if !utils.SetGameListSort(appState.Config, gl.RomDirectory.Path, sortKey) {
+		return state.Redraw()
+	}
+
+	if err := utils.SaveConfig(appState.Config); err != nil {
+		logger.Error("Unable to save games list sort order", zap.String("sort", sortKey), zap.Error(err))
+	}
+
+	state.UpdateAppState(appState)
+
+	// The games are in a new order, so the cursor goes back to the top
+	return state.Redraw().ResetCursor()
+}
+
+func handleGameSelection(gl ui.GameList, result interface{}) state.Navigation {
+	selections := result.(shared.Items)
+
+	if len(selections) == 0 {
+		return state.Redraw()
+	}
+
+	if len(selections) == 1 {
+		return handleSingleGameSelection(gl, selections[0])
+	}
+
+	return state.Push(ui.InitBulkOptionsScreen(selections, gl.RomDirectory, gl.PreviousRomDirectory, gl.SearchFilter))
+}
+
+func handleSingleGameSelection(gl ui.GameList, selection shared.Item) state.Navigation {
+	if selection.IsDirectory && !selection.IsMultiDiscDirectory && !selection.IsSelfContainedDirectory {
+		newRomDirectory := shared.RomDirectory{
+			DisplayName: selection.DisplayName,
+			Tag:         gl.RomDirectory.Tag,
+			Path:        selection.Path,
+		}
+		return state.Push(ui.InitGamesListWithPreviousDirectory(newRomDirectory, gl.RomDirectory, ""))
+	}
+
+	return state.Push(ui.InitActionsScreen(selection, gl.RomDirectory, gl.PreviousRomDirectory, gl.SearchFilter))
+}
+
+func handleGameListBack(gl ui.GameList) state.Navigation {
+	if gl.PreviousRomDirectory.Path == "" && gl.SearchFilter != "" {
+		return state.Replace(ui.InitGamesList(gl.RomDirectory, "")).ResetCursor()
+	}
+
+	return state.Pop()
+}
+
+func handleEmptyGamesList(gl ui.GameList) state.Navigation {
+	if gl.SearchFilter != "" {
+		utils.ShowTimedMessage(fmt.Sprintf("No results found for %s!", gl.SearchFilter), shortMessageDelay)
+		return state.Push(ui.InitSearch(gl.RomDirectory))
+	}
+
+	utils.ShowTimedMessage(fmt.Sprintf("%s is empty!", gl.RomDirectory.DisplayName), longMessageDelay)
+	return state.Pop()
+}
+
+func handleSearchBoxTransition(s ui.Search, result interface{}, code int) state.Navigation {
+	if code == ExitCodeSuccess && !isValidSearchQuery(result.(string)) {
+		return state.Replace(ui.InitSearchWithQuery(s.RomDirectory, result.(string)))
+	}
+
+	if s.RomDirectory.Path == "" {
+		return handleGlobalSearchBox(result, code)
+	}
+
+	searchFilter := ""
+
+	if code == ExitCodeSuccess {
+		searchFilter = result.(string)
+	}
+
+	return state.Return(func(gl ui.GameList) models.Screen {
+		return ui.InitGamesListWithPreviousDirectory(gl.RomDirectory, gl.PreviousRomDirectory, searchFilter)
+	})
+}
+
+func handleActionsTransition(as ui.ActionsScreen, result interface{}, code int) state.Navigation {
+	if code != ExitCodeSuccess {
+		return state.Pop()
+	}
+
+	action := models.ActionMap[result.(string)]
+	return executeGameAction(as, action)
+}
+
+func executeGameAction(as ui.ActionsScreen, action sum.Int[models.Action]) state.Navigation {
+	switch action {
+	case models.Actions.DownloadArt:
+		return state.Push(ui.InitDownloadArtScreen(as.Game, as.RomDirectory, as.PreviousRomDirectory, as.SearchFilter, state.GetAppState().Config.ArtDownloadType))
+	case models.Actions.DeleteArt:
+		return handleDeleteArtAction(as)
+	case models.Actions.RenameRom:
+		return handleRenameRomAction(as)
+	case models.Actions.CollectionAdd:
+		return state.Push(ui.InitAddToCollectionScreen([]shared.Item{as.Game}, as.RomDirectory, as.PreviousRomDirectory, as.SearchFilter))
+	case models.Actions.ClearGameTracker:
+		return handleClearGameTrackerAction(as)
+	case models.Actions.ArchiveRom:
+		return state.Push(ui.InitAddToArchiveScreen([]shared.Item{as.Game}, as.RomDirectory, as.PreviousRomDirectory, as.SearchFilter))
+	case models.Actions.DeleteRom:
+		return handleDeleteRomAction(as)
+	case models.Actions.Nuke:
+		return handleNukeAction(as)
+	case models.Actions.PlayHistoryOpen:
+		return state.Push(ui.InitPlayHistoryGameDetailsScreenFromActions(as.Game, as.RomDirectory, as.PreviousRomDirectory, as.SearchFilter))
+	default:
+		return state.Pop()
+	}
+}
+
+func handleDeleteArtAction(as ui.ActionsScreen) state.Navigation {
+	logger := common.GetLoggerInstance()
+
+	existingArtPath, err := utils.FindExistingArt(as.Game.Filename, as.RomDirectory)
+	if err != nil {
+		logger.Error("Failed to find existing art", zap.Error(err))
+		utils.ShowTimedMessage("Unable to delete art!", longMessageDelay)
+		return state.Redraw()
+	}
+
+	if confirmDeletion("Delete this beautiful art?", existingArtPath) {
+		utils.DeleteArtWithUndo([]shared.Item{as.Game}, as.RomDirectory)
+	}
+
+	return state.Redraw()
+}
+
+func handleRenameRomAction(as ui.ActionsScreen) state.Navigation {
+	newName, err := gaba.Keyboard(as.Game.DisplayName)
+	if err != nil {
+		utils.ShowTimedMessage("Unable to rename ROM!", longMessageDelay)
+		return state.Redraw()
+	}
+
+	if !newName.IsSome() {
+		return state.Redraw()
+	}
+
+	newFilename := newName.Unwrap()
+	newPath, err := utils.RenameRom(as.Game, newFilename, as.RomDirectory)
+	if err != nil {
+		utils.ShowTimedMessage("Unable to rename ROM!", longMessageDelay)
+		return state.Redraw()
+	}
+
+	utils.RecordUndo(fmt.Sprintf("Rename %s to %s", as.Game.DisplayName, newFilename),
+		utils.RenameRomUndoStep(as.RomDirectory, as.Game.Filename, newPath))
+
+	as.Game.DisplayName = newFilename
+	as.Game.Filename = newPath
+
+	return state.Replace(ui.InitActionsScreen(as.Game, as.RomDirectory, as.PreviousRomDirectory, as.SearchFilter))
+}
+
+func handleClearGameTrackerAction(as ui.ActionsScreen) state.Navigation {
+	message := fmt.Sprintf("Clear %s from Game Tracker?", as.Game.DisplayName)
+	if !utils.ConfirmAction(message) {
+		return state.Redraw()
+	}
+
+	success := utils.ClearGameTracker(as.Game.Filename, as.RomDirectory)
+	if success {
+		utils.ShowTimedMessage("Game Tracker data cleared!", longMessageDelay)
+	} else {
+		utils.ShowTimedMessage("Unable to clear Game Tracker data!", longMessageDelay)
+	}
+
+	return state.Redraw()
+}
+
+func handleDeleteRomAction(as ui.ActionsScreen) state.Navigation {
+	message := fmt.Sprintf("Delete %s?", as.Game.DisplayName)
+	if utils.ConfirmAction(message) {
+		entry, err := utils.DeleteRom(as.Game, as.RomDirectory)
+		if err != nil {
+			utils.ShowTimedMessage("Unable to delete ROM!", longMessageDelay)
+			return state.Redraw()
+		}
+
+		utils.RecordUndo(fmt.Sprintf("Delete %s", as.Game.DisplayName), utils.TrashUndoStep(entry))
+		//TODO: Update position around deleted rom
+		return state.Pop().ResetCursor()
+	}
+
+	return state.Redraw()
+}
+
+func handleNukeAction(as ui.ActionsScreen) state.Navigation {
+	message := fmt.Sprintf("Nuke %s?", as.Game.DisplayName)
+	if utils.ConfirmAction(message) {
+		entry, err := utils.Nuke(as.Game, as.RomDirectory)
+		if err != nil {
+			utils.ShowTimedMessage("Unable to nuke ROM!", longMessageDelay)
+			return state.Redraw()
+		}
+
+		utils.RecordUndo(fmt.Sprintf("Nuke %s", as.Game.DisplayName), utils.TrashUndoStep(entry))
+		//TODO: Update position around deleted rom
+		return state.Pop().ResetCursor()
+	}
+
+	return state.Redraw()
+}
+
+func handleBulkActionsTransition(ba ui.BulkOptionsScreen, result interface{}, code int) state.Navigation {
+	if code != ExitCodeSuccess {
+		return state.Pop()
+	}
+
+	action := models.ActionMap[result.(string)]
+
+	if action == models.Actions.CollectionAdd {
+		return state.Push(ui.InitAddToCollectionScreen(ba.Games, ba.RomDirectory, ba.PreviousRomDirectory, ba.SearchFilter))
+	}
+
+	return executeBulkAction(ba, action)
+}
+
+func executeBulkAction(ba ui.BulkOptionsScreen, action sum.Int[models.Action]) state.Navigation {
+	switch action {
+	case models.Actions.DownloadArt:
+		handleBulkDownloadArt(ba)
+	case models.Actions.DeleteArt:
+		handleBulkDeleteArt(ba)
+	case models.Actions.ArchiveRom:
+		return state.Push(ui.InitAddToArchiveScreen(ba.Games, ba.RomDirectory, ba.PreviousRomDirectory, ba.SearchFilter))
+	case models.Actions.DeleteRom:
+		handleBulkDelete(ba)
+	case models.Actions.Nuke:
+		handleBulkNuke(ba)
+	}
+	return state.Pop()
+}
+
+func handleBulkDownloadArt(ba ui.BulkOptionsScreen) {
+	var artPaths []string
+
+	gaba.ProcessMessage(
+		fmt.Sprintf("Downloading art for %d games...", len(ba.Games)),
+		gaba.ProcessMessageOptions{ShowThemeBackground: true},
+		func() (interface{}, error) {
+			for _, game := range ba.Games {
+				romDirectory := utils.RomDirectoryForItem(game, ba.RomDirectory)
+				if artPath := utils.FindArt(romDirectory, game, state.GetAppState().Config.ArtDownloadType, state.GetAppState().Config.FuzzySearchThreshold); artPath != "" {
+					artPaths = append(artPaths, artPath)
+				}
+			}
+			return nil, nil
+		},
+	)
+
+	showArtDownloadResult(artPaths, len(ba.Games))
+}
+
+func showArtDownloadResult(artPaths []string, totalGames int) {
+	if len(artPaths) == 0 {
+		utils.ShowTimedMessage("No art found!", standardMessageDelay)
+		return
+	}
+
+	if totalGames > 1 {
+		message := fmt.Sprintf("Art found for %d/%d games!", len(artPaths), totalGames)
+		utils.ShowTimedMessage(message, standardMessageDelay)
+	}
+}
+
+func handleBulkDeleteArt(ba ui.BulkOptionsScreen) {
+	if utils.ConfirmBulkAction("Delete art for the selected games?") {
+		utils.DeleteArtWithUndo(ba.Games, ba.RomDirectory)
+	}
+}
+
+func handleBulkDelete(ba ui.BulkOptionsScreen) {
+	if utils.ConfirmBulkAction("Delete the selected games?") {
+		trashBulkGames(ba, "Delete", utils.DeleteRom)
+	}
+}
+
+func handleBulkNuke(ba ui.BulkOptionsScreen) {
+	if utils.ConfirmBulkAction("Nuke the selected games?") {
+		trashBulkGames(ba, "Nuke", utils.Nuke)
+	}
+}
+
+// trashBulkGames trashes each selected game and records them as a single action to undo.
+func trashBulkGames(ba ui.BulkOptionsScreen, verb string, trash func(shared.Item, shared.RomDirectory) (models.TrashEntry, error)) {
+	var undoSteps []models.UndoStep
+	for _, game := range ba.Games {
+		entry, err := trash(game, utils.RomDirectoryForItem(game, ba.RomDirectory))
+		if err != nil {
+			continue
+		}
+		undoSteps = append(undoSteps, utils.TrashUndoStep(entry))
+	}
+
+	if len(undoSteps) > 0 {
+		description := fmt.Sprintf("%s %d games", verb, len(undoSteps))
+		if len(ba.Games) == 1 {
+			description = fmt.Sprintf("%s %s", verb, ba.Games[0].DisplayName)
+		}
+		utils.RecordUndo(description, undoSteps...)
+	}
+
+	if failed := len(ba.Games) - len(undoSteps); failed > 0 {
+		utils.ShowTimedMessage(fmt.Sprintf("Unable to %s %d of %d games!", strings.ToLower(verb), failed, len(ba.Games)), longMessageDelay)
+	}
+}
+
+func handleAddToCollectionTransition(atc ui.AddToCollectionScreen, _ interface{}, code int) state.Navigation {
+	switch code {
+	case ExitCodeSuccess:
+		//TODO: Update position around collection removed from list due to game added
+		return state.Redraw().ResetCursor()
+	case ExitCodeAction, ExitCodeEmpty:
+		return state.Push(ui.InitCreateCollectionScreen(atc.Games, atc.RomDirectory, atc.PreviousRomDirectory, atc.SearchFilter))
+	default:
+		return state.Pop()
+	}
+}
+
+func handleCollectionCreateTransition(_ ui.CreateCollectionScreen, _ interface{}, _ int) state.Navigation {
+	return state.Pop()
+}
+
+func handleAddToArchiveTransition(atas ui.AddToArchiveScreen, _ interface{}, code int) state.Navigation {
+	switch code {
+	case ExitCodeSuccess:
+		return state.PopTo(models.ScreenNames.GamesList, models.ScreenNames.GlobalSearch).ResetCursor()
+	case ExitCodeEmpty:
+		return state.Redraw()
+	case ExitCodeAction:
+		return state.Push(ui.InitArchiveCreateScreen(atas.Games, atas.RomDirectory, atas.PreviousRomDirectory, atas.SearchFilter))
+	default:
+		return state.Pop()
+	}
+}
+
+func handleArchiveCreateTransition(_ ui.ArchiveCreateScreen, _ interface{}, _ int) state.Navigation {
+	return state.Pop()
+}
+
+func handleDownloadArtTransition(_ ui.DownloadArtScreen, _ interface{}, _ int) state.Navigation {
+	return state.Pop()
+}
+
+func confirmDeletion(message, imagePath string) bool {
+	result, err := gaba.ConfirmationMessage(message, []gaba.FooterHelpItem{
+		{ButtonName: "B", HelpText: "I Changed My Mind"},
+		{ButtonName: "A", HelpText: "Trash It!"},
+	}, gaba.MessageOptions{
+		ImagePath: imagePath,
+	})
+
+	return err == nil && result.IsSome()
+}
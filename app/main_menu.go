@@ -0,0 +1,125 @@
+package main
+
+import (
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"nextui-game-manager/models"
+	"nextui-game-manager/state"
+	"nextui-game-manager/ui"
+	"nextui-game-manager/utils"
+)
+
+func init() {
+	registerTransition(models.ScreenNames.MainMenu, handleMainMenuTransition)
+	registerTransition(models.ScreenNames.Settings, handleSettingsTransition)
+	registerTransition(models.ScreenNames.Tools, handleToolsTransition)
+	registerTransition(models.ScreenNames.GlobalActions, handleGlobalActionsTransition)
+	registerTransition(models.ScreenNames.UndoHistory, handleUndoHistoryTransition)
+	registerTransition(models.ScreenNames.Trash, handleTrashTransition)
+	registerTransition(models.ScreenNames.CollectionCheck, handleCollectionCheckTransition)
+	registerTransition(models.ScreenNames.RecentlyPlayed, handleRecentlyPlayedTransition)
+}
+
+func handleMainMenuTransition(_ ui.MainMenu, result interface{}, code int) state.Navigation {
+	switch code {
+	case ExitCodeSuccess:
+		romDir := result.(shared.RomDirectory)
+		if romDir.DisplayName == "Collections" {
+			return state.Push(ui.InitCollectionList(""))
+		}
+		if romDir.DisplayName == "Archives" {
+			return state.Push(ui.InitArchiveListScreen())
+		}
+		return state.Push(ui.InitGamesList(romDir, ""))
+	case ExitCodeError, ExitCodeCancel:
+		return state.Exit()
+	case ExitCodeAction:
+		return state.Push(ui.InitSettingsScreen())
+	case ui.ToolsExitCode:
+		return state.Push(ui.InitToolsScreen())
+	case ui.SearchExitCode:
+		return state.Push(ui.InitSearch(shared.RomDirectory{}))
+	default:
+		return state.PopToRoot()
+	}
+}
+
+func handleSettingsTransition(_ ui.SettingsScreen, _ interface{}, _ int) state.Navigation {
+	return state.Pop()
+}
+
+func handleToolsTransition(_ ui.ToolsScreen, result interface{}, code int) state.Navigation {
+	switch code {
+	case ExitCodeSuccess:
+		switch result.(string) {
+		case "Global Actions":
+			return state.Push(ui.InitGlobalActionsScreen())
+		case "Check Collections":
+			return state.Push(ui.InitCollectionCheckScreen())
+		case "Recently Played":
+			return state.Push(ui.InitRecentlyPlayedScreen())
+		case "Play History":
+			return state.Push(ui.InitPlayHistoryListScreen())
+		case "Recent Actions":
+			return state.Push(ui.InitUndoHistoryScreen())
+		case "Trash":
+			return state.Push(ui.InitTrashScreen())
+		}
+		return state.Redraw()
+	default:
+		return state.Pop()
+	}
+}
+
+func handleGlobalActionsTransition(_ ui.GlobalActionsScreen, _ interface{}, code int) state.Navigation {
+	switch code {
+	case ExitCodeCancel:
+		return state.Pop()
+	default:
+		return state.Redraw()
+	}
+}
+
+func handleUndoHistoryTransition(_ ui.UndoHistoryScreen, _ interface{}, code int) state.Navigation {
+	switch code {
+	case ExitCodeSuccess, ExitCodeError:
+		return state.Redraw()
+	case ExitCodeEmpty:
+		utils.ShowTimedMessage("Nothing to undo!", standardMessageDelay)
+		return state.Pop()
+	default:
+		return state.Pop()
+	}
+}
+
+func handleTrashTransition(_ ui.TrashScreen, _ interface{}, code int) state.Navigation {
+	switch code {
+	case ExitCodeSuccess, ExitCodeError:
+		return state.Redraw()
+	case ExitCodeEmpty:
+		utils.ShowTimedMessage("The trash is empty!", standardMessageDelay)
+		return state.Pop()
+	default:
+		return state.Pop()
+	}
+}
+
+func handleCollectionCheckTransition(_ ui.CollectionCheckScreen, _ interface{}, code int) state.Navigation {
+	switch code {
+	case ExitCodeSuccess, ExitCodeError:
+		return state.Redraw()
+	case ExitCodeEmpty:
+		utils.ShowTimedMessage("No broken collection entries!", standardMessageDelay)
+		return state.Pop()
+	default:
+		return state.Pop()
+	}
+}
+
+func handleRecentlyPlayedTransition(_ ui.RecentlyPlayedScreen, _ interface{}, code int) state.Navigation {
+	switch code {
+	case ExitCodeSuccess, ExitCodeError:
+		return state.Redraw()
+	default:
+		return state.Pop()
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
+	"go.uber.org/zap"
+	"nextui-game-manager/models"
+	"nextui-game-manager/state"
+	"nextui-game-manager/ui"
+	"qlova.tech/sum"
+)
+
+type transition func(screen models.Screen, result interface{}, code int) state.Navigation
+
+var transitions = map[sum.Int[models.ScreenName]]transition{}
+
+// registerTransition wires up what happens when a screen finishes drawing. Each domain registers its own screens from
+// an init function, so adding a screen never means touching the application loop.
+func registerTransition[S models.Screen](name sum.Int[models.ScreenName], handler func(screen S, result interface{}, code int) state.Navigation) {
+	transitions[name] = func(screen models.Screen, result interface{}, code int) state.Navigation {
+		return handler(screen.(S), result, code)
+	}
+}
+
+func runApplicationLoop() {
+	logger := common.GetLoggerInstance()
+
+	screen := state.StartNavigation(ui.InitMainMenu())
+
+	for {
+		result, code, err := screen.Draw()
+		if err != nil {
+			logger.Error("Screen returned an error", zap.Int("code", code), zap.Error(err))
+		}
+
+		navigation := state.PopToRoot()
+		if handler, ok := transitions[screen.Name()]; ok {
+			navigation = handler(screen, result, code)
+		}
+
+		next, ok := state.Navigate(navigation)
+		if !ok {
+			return
+		}
+		screen = next
+	}
+}
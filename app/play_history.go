@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"nextui-game-manager/models"
+	"nextui-game-manager/state"
+	"nextui-game-manager/ui"
+	"nextui-game-manager/utils"
+)
+
+func init() {
+	registerTransition(models.ScreenNames.PlayHistoryList, handlePlayHistoryListTransition)
+	registerTransition(models.ScreenNames.PlayHistoryGameList, handlePlayHistoryGameListTransition)
+	registerTransition(models.ScreenNames.PlayHistoryGameDetails, handlePlayHistoryGameDetailsTransition)
+	registerTransition(models.ScreenNames.PlayHistoryGameHistory, handlePlayHistoryGameHistoryTransition)
+}
+
+func handlePlayHistoryListTransition(_ ui.PlayHistoryListScreen, result interface{}, code int) state.Navigation {
+	switch code {
+	case ExitCodeSuccess:
+		return state.Push(ui.InitPlayHistoryGamesListScreen(result.(string), ""))
+	default:
+		return state.Pop()
+	}
+}
+
+func handlePlayHistoryGameListTransition(ptgls ui.PlayHistoryGamesListScreen, result interface{}, code int) state.Navigation {
+	switch code {
+	case ExitCodeSuccess:
+		return state.Push(ui.InitPlayHistoryGameDetailsScreenFromPlayHistory(ptgls.Console, ptgls.SearchFilter, result.(models.PlayHistoryAggregate)))
+	case ExitCodeCancel:
+		if ptgls.SearchFilter != "" {
+			return state.Replace(ui.InitPlayHistoryGamesListScreen(ptgls.Console, "")).ResetCursor()
+		}
+
+		return state.Pop()
+	case ExitCodeAction, ExitCodeError:
+		searchFilter, _ := result.(string)
+
+		if searchFilter != "" {
+			return state.Replace(ui.InitPlayHistoryGamesListScreen(ptgls.Console, searchFilter)).ResetCursor()
+		}
+
+		return state.Replace(ui.InitPlayHistoryGamesListScreen(ptgls.Console, ""))
+	case ExitCodeEmpty:
+		if ptgls.SearchFilter != "" {
+			utils.ShowTimedMessage(fmt.Sprintf("No results found for %s!", ptgls.SearchFilter), shortMessageDelay)
+			return state.Replace(ui.InitPlayHistoryGamesListScreen(ptgls.Console, "")).ResetCursor()
+		}
+
+		utils.ShowTimedMessage(fmt.Sprintf("%s history is empty!", ptgls.Console), longMessageDelay)
+		return state.Pop()
+	default:
+		return state.Pop()
+	}
+}
+
+func handlePlayHistoryGameDetailsTransition(ptgds ui.PlayHistoryGameDetailsScreen, _ interface{}, code int) state.Navigation {
+	switch code {
+	case ExitCodeSuccess:
+		return state.Push(ui.InitPlayHistoryGameHistoryScreen(ptgds.Console, ptgds.SearchFilter, ptgds.GameAggregate,
+			ptgds.Game, ptgds.RomDirectory, ptgds.PreviousRomDirectory, ptgds.PlayHistoryOrigin))
+	default:
+		return state.Pop()
+	}
+}
+
+func handlePlayHistoryGameHistoryTransition(_ ui.PlayHistoryGameHistoryScreen, _ interface{}, _ int) state.Navigation {
+	return state.Pop()
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"nextui-game-manager/models"
+	"nextui-game-manager/state"
+	"nextui-game-manager/ui"
+	"nextui-game-manager/utils"
+)
+
+func init() {
+	registerTransition(models.ScreenNames.GlobalSearch, handleGlobalSearchTransition)
+}
+
+// handleGlobalSearchBox swaps the search box for the results, so backing out of the results returns to wherever the
+// search was started from.
+func handleGlobalSearchBox(result interface{}, code int) state.Navigation {
+	query, _ := result.(string)
+
+	if code != ExitCodeSuccess || query == "" {
+		return state.Pop()
+	}
+
+	return state.Replace(ui.InitGlobalSearchScreen(query)).ResetCursor()
+}
+
+// isValidSearchQuery explains what is wrong with a query that does not parse, so it can be fixed before searching.
+func isValidSearchQuery(query string) bool {
+	if _, err := utils.ParseSearchQuery(query); err != nil {
+		utils.ShowTimedMessage(fmt.Sprintf("Invalid search!\n%s", err.Error()), longMessageDelay)
+		return false
+	}
+	return true
+}
+
+func handleGlobalSearchTransition(gs ui.GlobalSearchScreen, result interface{}, code int) state.Navigation {
+	switch code {
+	case ExitCodeSuccess:
+		return handleGlobalSearchSelection(result)
+	case ExitCodeAction:
+		return state.Push(ui.InitSearch(shared.RomDirectory{}))
+	case ExitCodeEmpty:
+		utils.ShowTimedMessage(fmt.Sprintf("No results found for %s!", gs.Query), shortMessageDelay)
+		return state.Replace(ui.InitSearch(shared.RomDirectory{}))
+	case ExitCodeError:
+		// The search could not run, so the query goes back in the search box to be fixed or tried again
+		return state.Replace(ui.InitSearchWithQuery(shared.RomDirectory{}, gs.Query))
+	case ExitCodeCancel:
+		return state.Pop()
+	default:
+		return state.PopToRoot()
+	}
+}
+
+func handleGlobalSearchSelection(result interface{}) state.Navigation {
+	switch selected := result.(type) {
+	case models.IndexedRom:
+		romDirectory := utils.RomDirectoryForItem(selected.Item, shared.RomDirectory{})
+		return state.Push(ui.InitActionsScreen(selected.Item, romDirectory, shared.RomDirectory{}, ""))
+	case shared.Items:
+		if len(selected) == 0 {
+			return state.Redraw()
+		}
+		return state.Push(ui.InitBulkOptionsScreen(selected, shared.RomDirectory{}, shared.RomDirectory{}, ""))
+	case models.Collection:
+		return state.Push(ui.InitCollectionManagement(selected))
+	default:
+		// An archived game was restored in place
+		return state.Redraw()
+	}
+}
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	ExitCodeSuccess = 0 // Command completed
+	ExitCodeError   = 1 // Command failed
+	ExitCodeUsage   = 2 // Unknown command or bad arguments
+)
+
+type command struct {
+	Name  string
+	Usage string
+	Run   func(args []string) (interface{}, error)
+}
+
+type response struct {
+	Command string      `json:"command"`
+	OK      bool        `json:"ok"`
+	Result  interface{} `json:"result,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+type usageError struct {
+	message string
+}
+
+func (e usageError) Error() string {
+	return e.message
+}
+
+func usageErrorf(format string, args ...interface{}) error {
+	return usageError{message: fmt.Sprintf(format, args...)}
+}
+
+var stdout io.Writer = os.Stdout
+
+func commands() []command {
+	return []command{
+		{Name: "rename", Usage: "rename <rom path> <new name>", Run: runRename},
+		{Name: "archive", Usage: "archive <rom path> [archive name] [--note <reason>]", Run: runArchive},
+		{Name: "restore", Usage: "restore <archived rom path>", Run: runRestore},
+		{Name: "collection add", Usage: "collection add <collection name> <rom path>...", Run: runCollectionAdd},
+		{Name: "collection list", Usage: "collection list", Run: runCollectionList},
+		{Name: "collection refresh", Usage: "collection refresh", Run: runCollectionRefresh},
+		{Name: "collection export", Usage: "collection export <collection name> [--hash]", Run: runCollectionExport},
+		{Name: "collection import", Usage: "collection import <export file>", Run: runCollectionImport},
+		{Name: "art fetch", Usage: "art fetch <rom path>...", Run: runArtFetch},
+		{Name: "missing-art", Usage: "missing-art", Run: runMissingArt},
+		{Name: "stats", Usage: "stats", Run: runStats},
+	}
+}
+
+// Run executes a single headless command, writes a JSON response to stdout and returns the process exit code. setup
+// loads what the commands need, such as the config, once the command is known so its failure is reported like any other.
+func Run(args []string, setup func() error) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "--help" || args[0] == "-h" {
+		printUsage()
+		return ExitCodeUsage
+	}
+
+	cmd, rest, found := findCommand(args)
+	if !found {
+		writeResponse(response{
+			Command: strings.Join(args, " "),
+			Error:   "unknown command",
+		})
+		return ExitCodeUsage
+	}
+
+	if err := setup(); err != nil {
+		writeResponse(response{
+			Command: cmd.Name,
+			Error:   err.Error(),
+		})
+		return ExitCodeError
+	}
+
+	result, err := cmd.Run(rest)
+	if err != nil {
+		writeResponse(response{
+			Command: cmd.Name,
+			Error:   err.Error(),
+		})
+
+		if _, ok := err.(usageError); ok {
+			fmt.Fprintln(os.Stderr, "usage: game-manager "+cmd.Usage)
+			return ExitCodeUsage
+		}
+		return ExitCodeError
+	}
+
+	writeResponse(response{
+		Command: cmd.Name,
+		OK:      true,
+		Result:  result,
+	})
+	return ExitCodeSuccess
+}
+
+func findCommand(args []string) (command, []string, bool) {
+	for _, cmd := range commands() {
+		words := strings.Fields(cmd.Name)
+		if len(args) < len(words) {
+			continue
+		}
+
+		if strings.Join(args[:len(words)], " ") == cmd.Name {
+			return cmd, args[len(words):], true
+		}
+	}
+	return command{}, nil, false
+}
+
+func writeResponse(resp response) {
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(resp)
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "usage: game-manager <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, cmd := range commands() {
+		fmt.Fprintln(os.Stderr, "  "+cmd.Usage)
+	}
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/spf13/afero"
+	"nextui-game-manager/models"
+	"nextui-game-manager/utils"
+	"strings"
+	"testing"
+)
+
+// runCommand runs a headless command with nothing to set up and decodes the JSON response it wrote.
+func runCommand(t *testing.T, args ...string) (response, int) {
+	t.Helper()
+	return runCommandWithSetup(t, func() error { return nil }, args...)
+}
+
+func runCommandWithSetup(t *testing.T, setup func() error, args ...string) (response, int) {
+	t.Helper()
+
+	var output bytes.Buffer
+	previous := stdout
+	stdout = &output
+	t.Cleanup(func() { stdout = previous })
+
+	exitCode := Run(args, setup)
+
+	var resp response
+	if err := json.Unmarshal(output.Bytes(), &resp); err != nil {
+		t.Fatalf("response is not JSON: %v\n%s", err, output.String())
+	}
+	return resp, exitCode
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	resp, exitCode := runCommand(t, "collection", "shuffle")
+
+	if exitCode != ExitCodeUsage {
+		t.Errorf("exit code %d, want %d", exitCode, ExitCodeUsage)
+	}
+	if resp.OK || resp.Command != "collection shuffle" || resp.Error != "unknown command" {
+		t.Errorf("response %+v", resp)
+	}
+}
+
+func TestRunReportsSetupFailures(t *testing.T) {
+	setup := func() error {
+		return errors.New("unable to initialize configuration: yaml: line 3: did not find expected key")
+	}
+
+	resp, exitCode := runCommandWithSetup(t, setup, "collection", "list")
+	if exitCode != ExitCodeError {
+		t.Errorf("exit code %d, want %d", exitCode, ExitCodeError)
+	}
+	if resp.OK || resp.Command != "collection list" || !strings.Contains(resp.Error, "configuration") {
+		t.Errorf("response %+v", resp)
+	}
+
+	setupRan := false
+	_, exitCode = runCommandWithSetup(t, func() error { setupRan = true; return nil }, "collection", "shuffle")
+	if exitCode != ExitCodeUsage || setupRan {
+		t.Errorf("an unknown command exited with %d and set up: %v", exitCode, setupRan)
+	}
+}
+
+func TestRunReportsBadArgumentsAsUsageErrors(t *testing.T) {
+	resp, exitCode := runCommand(t, "rename", "Tetris.gb")
+
+	if exitCode != ExitCodeUsage {
+		t.Errorf("exit code %d, want %d", exitCode, ExitCodeUsage)
+	}
+	if resp.OK || resp.Command != "rename" || !strings.Contains(resp.Error, "new name") {
+		t.Errorf("response %+v", resp)
+	}
+}
+
+func TestRunReportsFailedCommands(t *testing.T) {
+	resp, exitCode := runCommand(t, "rename", "/nowhere/Tetris.gb", "Tetris DX")
+
+	if exitCode != ExitCodeError {
+		t.Errorf("exit code %d, want %d", exitCode, ExitCodeError)
+	}
+	if resp.OK || resp.Result != nil || !strings.Contains(resp.Error, "not inside the ROM directory") {
+		t.Errorf("response %+v", resp)
+	}
+}
+
+func TestRunListsCollections(t *testing.T) {
+	previousFileSystem, previousLayout := utils.GetFileSystem(), utils.GetLayout()
+	t.Cleanup(func() {
+		utils.SetFileSystem(previousFileSystem)
+		utils.SetLayout(previousLayout)
+	})
+
+	fs := afero.NewMemMapFs()
+	utils.SetFileSystem(fs)
+	utils.SetLayout(models.Layout{
+		RomDirectory:        "/mnt/SDCARD/Roms",
+		CollectionDirectory: "/mnt/SDCARD/Collections",
+	})
+
+	if err := afero.WriteFile(fs, "/mnt/SDCARD/Collections/Puzzle.txt", []byte("/Roms/Game Boy (GB)/Tetris.gb\n"), 0644); err != nil {
+		t.Fatalf("writing collection: %v", err)
+	}
+
+	resp, exitCode := runCommand(t, "collection", "list")
+
+	if exitCode != ExitCodeSuccess || !resp.OK || resp.Error != "" {
+		t.Fatalf("exit code %d, response %+v", exitCode, resp)
+	}
+
+	collections, ok := resp.Result.([]interface{})
+	if !ok || len(collections) != 1 {
+		t.Fatalf("result %#v, want one collection", resp.Result)
+	}
+	if collection := collections[0].(map[string]interface{}); collection["name"] != "Puzzle" {
+		t.Errorf("listed collection %v", collection)
+	}
+}
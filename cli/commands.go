@@ -0,0 +1,473 @@
+package cli
+
+import (
+	"fmt"
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"nextui-game-manager/models"
+	"nextui-game-manager/state"
+	"nextui-game-manager/utils"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const defaultArchiveName = ".Archive"
+
+type romResult struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+type renameResult struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type archiveResult struct {
+	Archive string `json:"archive"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+}
+
+type collectionResult struct {
+	Name  string   `json:"name"`
+	File  string   `json:"file"`
+	Games []string `json:"games"`
+}
+
+type collectionExportResult struct {
+	Name string `json:"name"`
+	File string `json:"file"`
+}
+
+type collectionImportResult struct {
+	Collection collectionResult `json:"collection"`
+	ByPath     int              `json:"by_path"`
+	ByName     int              `json:"by_name"`
+	ByHash     int              `json:"by_hash"`
+	ByFuzzy    int              `json:"by_fuzzy"`
+	Unresolved []string         `json:"unresolved"`
+}
+
+type artResult struct {
+	Rom   string `json:"rom"`
+	Art   string `json:"art,omitempty"`
+	Found bool   `json:"found"`
+}
+
+type missingArtResult struct {
+	Platform string      `json:"platform"`
+	Tag      string      `json:"tag"`
+	Path     string      `json:"path"`
+	Games    []romResult `json:"games"`
+}
+
+type platformStatisticsResult struct {
+	Platform   string `json:"platform"`
+	Tag        string `json:"tag"`
+	Roms       int    `json:"roms"`
+	Archived   int    `json:"archived"`
+	Size       int64  `json:"size"`
+	MissingArt int    `json:"missing_art"`
+	WithSaves  int    `json:"with_saves"`
+}
+
+type statisticsResult struct {
+	Roms       int                        `json:"roms"`
+	Archived   int                        `json:"archived"`
+	Size       int64                      `json:"size"`
+	MissingArt int                        `json:"missing_art"`
+	WithSaves  int                        `json:"with_saves"`
+	Platforms  []platformStatisticsResult `json:"platforms"`
+}
+
+func runRename(args []string) (interface{}, error) {
+	if len(args) != 2 {
+		return nil, usageErrorf("rename takes a ROM path and a new name")
+	}
+
+	romPath, err := resolveRomPath(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	game, err := itemFromPath(romPath)
+	if err != nil {
+		return nil, err
+	}
+
+	romDirectory := romDirectoryFromPath(romPath)
+	newFilename, err := utils.RenameRom(game, args[1], romDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	return renameResult{
+		From: romPath,
+		To:   filepath.Join(romDirectory.Path, newFilename),
+	}, nil
+}
+
+func runArchive(args []string) (interface{}, error) {
+	var note string
+	var paths []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--note" {
+			if i+1 == len(args) {
+				return nil, usageErrorf("--note takes the reason the ROM is archived")
+			}
+			note = args[i+1]
+			i++
+			continue
+		}
+		paths = append(paths, args[i])
+	}
+	args = paths
+
+	if len(args) < 1 || len(args) > 2 {
+		return nil, usageErrorf("archive takes a ROM path and an optional archive name")
+	}
+
+	archiveName := defaultArchiveName
+	if len(args) == 2 && filepath.IsAbs(args[1]) {
+		archiveName = filepath.Clean(args[1])
+		if !slices.Contains(utils.GetArchiveLocations(), filepath.Dir(archiveName)) {
+			return nil, usageErrorf("%s is not a folder in an archive location", archiveName)
+		}
+	} else if len(args) == 2 {
+		archiveName = utils.PrepArchiveName(args[1])
+		if archiveName == ".media" || strings.Contains(archiveName, "/") {
+			return nil, usageErrorf("%s is not a valid archive name", archiveName)
+		}
+	}
+
+	romPath, err := resolveRomPath(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := archiveFromPath(romPath); err == nil {
+		return nil, usageErrorf("%s is already archived", romPath)
+	}
+
+	game, err := itemFromPath(romPath)
+	if err != nil {
+		return nil, err
+	}
+
+	compress := state.GetAppState().Config.CompressArchives
+
+	romDirectory := romDirectoryFromPath(romPath)
+	if err := utils.ArchiveRom(game, romDirectory, archiveName, compress); err != nil {
+		return nil, err
+	}
+
+	archiveRoot := utils.GetArchiveRoot(archiveName)
+	archivedPath := filepath.Join(archiveRoot, strings.TrimPrefix(romPath, utils.GetRomDirectory()))
+	if compress {
+		archivedPath = utils.CompressedArchiveName(archivedPath)
+	}
+
+	if note != "" {
+		if err := utils.SetArchivedGameNote(archivedPath, note); err != nil {
+			return nil, err
+		}
+	}
+
+	return archiveResult{
+		Archive: archiveName,
+		From:    romPath,
+		To:      archivedPath,
+	}, nil
+}
+
+func runRestore(args []string) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, usageErrorf("restore takes an archived ROM path")
+	}
+
+	romPath, err := resolveRomPath(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := archiveFromPath(romPath)
+	if err != nil {
+		return nil, err
+	}
+
+	game, err := itemFromPath(romPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := utils.RestoreRom(game, romDirectoryFromPath(romPath), archive); err != nil {
+		return nil, err
+	}
+
+	return archiveResult{
+		Archive: archive.DisplayName,
+		From:    romPath,
+		To:      filepath.Join(utils.GetRomDirectory(), utils.UncompressedName(strings.TrimPrefix(romPath, archive.Path))),
+	}, nil
+}
+
+func runCollectionAdd(args []string) (interface{}, error) {
+	if len(args) < 2 {
+		return nil, usageErrorf("collection add takes a collection name and at least one ROM path")
+	}
+
+	collectionName := args[0]
+	if strings.Contains(collectionName, "/") {
+		return nil, usageErrorf("%s is not a valid collection name", collectionName)
+	}
+
+	var games []shared.Item
+	for _, arg := range args[1:] {
+		romPath, err := resolveRomPath(arg)
+		if err != nil {
+			return nil, err
+		}
+
+		game, err := itemFromPath(romPath)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, game)
+	}
+
+	collection, err := utils.AddCollectionGames(models.Collection{
+		DisplayName:    collectionName,
+		CollectionFile: filepath.Join(utils.GetCollectionDirectory(), collectionName+".txt"),
+	}, games)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildCollectionResult(collection), nil
+}
+
+func runCollectionList(args []string) (interface{}, error) {
+	if len(args) != 0 {
+		return nil, usageErrorf("collection list takes no arguments")
+	}
+
+	collections, _, err := utils.GenerateCollectionList("", false)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []collectionResult{}
+	for _, collection := range collections {
+		results = append(results, buildCollectionResult(collection))
+	}
+	return results, nil
+}
+
+// runCollectionRefresh rewrites the smart collections from their rules. A definition that could not be refreshed
+// fails the command, but the others are still written.
+func runCollectionRefresh(args []string) (interface{}, error) {
+	if len(args) != 0 {
+		return nil, usageErrorf("collection refresh takes no arguments")
+	}
+
+	collections, err := utils.RefreshSmartCollections()
+	if err != nil {
+		return nil, err
+	}
+
+	results := []collectionResult{}
+	for _, collection := range collections {
+		results = append(results, buildCollectionResult(collection))
+	}
+	return results, nil
+}
+
+func runCollectionExport(args []string) (interface{}, error) {
+	includeHashes := false
+	var names []string
+	for _, arg := range args {
+		if arg == "--hash" {
+			includeHashes = true
+			continue
+		}
+		names = append(names, arg)
+	}
+
+	if len(names) != 1 {
+		return nil, usageErrorf("collection export takes a collection name")
+	}
+
+	collection := models.Collection{
+		DisplayName:    names[0],
+		CollectionFile: filepath.Join(utils.GetCollectionDirectory(), names[0]+".txt"),
+	}
+
+	if strings.Contains(collection.DisplayName, "/") || !utils.DoesFileExists(collection.CollectionFile) {
+		return nil, fmt.Errorf("collection %s does not exist", collection.DisplayName)
+	}
+
+	exportPath, err := utils.ExportCollection(collection, includeHashes)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectionExportResult{Name: collection.DisplayName, File: exportPath}, nil
+}
+
+func runCollectionImport(args []string) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, usageErrorf("collection import takes an export file")
+	}
+
+	exportPath, err := filepath.Abs(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve %s: %w", args[0], err)
+	}
+
+	imported, err := utils.ImportCollection(exportPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := collectionImportResult{
+		Collection: buildCollectionResult(imported.Collection),
+		ByPath:     imported.ByPath,
+		ByName:     imported.ByName,
+		ByHash:     imported.ByHash,
+		ByFuzzy:    imported.ByFuzzy,
+		Unresolved: []string{},
+	}
+
+	for _, entry := range imported.Unresolved {
+		result.Unresolved = append(result.Unresolved, entry.Path)
+	}
+	return result, nil
+}
+
+func buildCollectionResult(collection models.Collection) collectionResult {
+	result := collectionResult{
+		Name:  collection.DisplayName,
+		File:  collection.CollectionFile,
+		Games: []string{},
+	}
+
+	for _, game := range collection.Games {
+		result.Games = append(result.Games, game.Path)
+	}
+	return result
+}
+
+func runArtFetch(args []string) (interface{}, error) {
+	if len(args) == 0 {
+		return nil, usageErrorf("art fetch takes at least one ROM path")
+	}
+
+	config := state.GetAppState().Config
+
+	// Matched the way the bulk download in the UI does it, listing the art of each platform once
+	var romPaths []string
+	var romDirectories []shared.RomDirectory
+	games := make(map[string]shared.Items)
+	for _, arg := range args {
+		romPath, err := resolveRomPath(arg)
+		if err != nil {
+			return nil, err
+		}
+
+		game, err := itemFromPath(romPath)
+		if err != nil {
+			return nil, err
+		}
+
+		romDirectory := romDirectoryFromPath(romPath)
+		if _, ok := games[romDirectory.Path]; !ok {
+			romDirectories = append(romDirectories, romDirectory)
+		}
+		games[romDirectory.Path] = append(games[romDirectory.Path], game)
+		romPaths = append(romPaths, romPath)
+	}
+
+	artPaths := make(map[string]string)
+	for _, romDirectory := range romDirectories {
+		for romPath, artPath := range utils.FetchAllArt(romDirectory, games[romDirectory.Path], config.ArtDownloadType, config.FuzzySearchThreshold) {
+			artPaths[romPath] = artPath
+		}
+	}
+
+	var results []artResult
+	for _, romPath := range romPaths {
+		results = append(results, artResult{
+			Rom:   romPath,
+			Art:   artPaths[romPath],
+			Found: artPaths[romPath] != "",
+		})
+	}
+
+	return results, nil
+}
+
+func runMissingArt(args []string) (interface{}, error) {
+	if len(args) != 0 {
+		return nil, usageErrorf("missing-art takes no arguments")
+	}
+
+	romsWithoutArt, err := utils.FindRomsWithoutArt()
+	if err != nil {
+		return nil, err
+	}
+
+	results := []missingArtResult{}
+	for romDirectory, games := range romsWithoutArt {
+		result := missingArtResult{
+			Platform: romDirectory.DisplayName,
+			Tag:      romDirectory.Tag,
+			Path:     romDirectory.Path,
+		}
+
+		for _, game := range games {
+			result.Games = append(result.Games, romResult{Name: game.DisplayName, Path: game.Path})
+		}
+		results = append(results, result)
+	}
+
+	slices.SortFunc(results, func(a, b missingArtResult) int {
+		return strings.Compare(a.Platform, b.Platform)
+	})
+
+	return results, nil
+}
+
+func runStats(args []string) (interface{}, error) {
+	if len(args) != 0 {
+		return nil, usageErrorf("stats takes no arguments")
+	}
+
+	statistics, err := utils.GetLibraryStatistics()
+	if err != nil {
+		return nil, err
+	}
+
+	result := statisticsResult{
+		Roms:       statistics.Roms,
+		Archived:   statistics.Archived,
+		Size:       statistics.Size,
+		MissingArt: statistics.MissingArt,
+		WithSaves:  statistics.WithSaves,
+		Platforms:  []platformStatisticsResult{},
+	}
+
+	for _, platform := range statistics.Platforms {
+		result.Platforms = append(result.Platforms, platformStatisticsResult{
+			Platform:   platform.Platform.DisplayName,
+			Tag:        platform.Platform.Tag,
+			Roms:       platform.Roms,
+			Archived:   platform.Archived,
+			Size:       platform.Size,
+			MissingArt: platform.MissingArt,
+			WithSaves:  platform.WithSaves,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"fmt"
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"nextui-game-manager/utils"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var platformTagPattern = regexp.MustCompile(`\([^()]*\)$`)
+
+func resolveRomPath(path string) (string, error) {
+	absolutePath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve %s: %w", path, err)
+	}
+
+	romRoot := filepath.Clean(utils.GetRomDirectory())
+	_, archive := utils.LibraryScope(absolutePath)
+	if !strings.HasPrefix(absolutePath, romRoot+string(filepath.Separator)) && archive == "" {
+		return "", fmt.Errorf("%s is not inside the ROM directory %s or an archive location", absolutePath, romRoot)
+	}
+
+	if !utils.DoesFileExists(absolutePath) {
+		return "", fmt.Errorf("%s does not exist", absolutePath)
+	}
+
+	return absolutePath, nil
+}
+
+func itemFromPath(path string) (shared.Item, error) {
+	item, err := utils.ItemFromPath(path)
+	if err != nil {
+		return shared.Item{}, fmt.Errorf("unable to stat %s: %w", path, err)
+	}
+	return item, nil
+}
+
+// romDirectoryFromPath builds the RomDirectory the UI would have open when the given ROM was selected.
+func romDirectoryFromPath(romPath string) shared.RomDirectory {
+	directory := filepath.Dir(romPath)
+	displayName := filepath.Base(directory)
+
+	if directory == platformDirectory(romPath) {
+		displayName = strings.TrimSpace(platformTagPattern.ReplaceAllString(displayName, ""))
+	}
+
+	return shared.RomDirectory{
+		DisplayName: displayName,
+		Tag:         platformTag(romPath),
+		Path:        directory,
+	}
+}
+
+// platformDirectory returns the top level folder below the ROM directory, skipping over the archive folder of archived
+// ROMs.
+func platformDirectory(romPath string) string {
+	platformPath, _ := utils.LibraryScope(romPath)
+	return platformPath
+}
+
+func platformTag(romPath string) string {
+	return platformTagPattern.FindString(filepath.Base(platformDirectory(romPath)))
+}
+
+// archiveFromPath splits an archived ROM path into the archive it lives in and its platform folder inside that archive.
+func archiveFromPath(romPath string) (shared.RomDirectory, error) {
+	_, archiveName := utils.LibraryScope(romPath)
+	if archiveName == "" || archiveName == ".media" {
+		return shared.RomDirectory{}, fmt.Errorf("%s is not inside an archive", romPath)
+	}
+
+	return shared.RomDirectory{
+		DisplayName: utils.ArchiveDisplayName(archiveName),
+		Path:        utils.GetArchiveRoot(archiveName),
+	}, nil
+}
@@ -8,6 +8,7 @@ require (
 	github.com/UncleJunVIP/nextui-pak-shared-functions v1.9.1
 	github.com/disintegration/imaging v1.6.2
 	github.com/mattn/go-sqlite3 v1.14.28
+	github.com/spf13/afero v1.14.0
 	github.com/spf13/viper v1.20.1
 	github.com/veandco/go-sdl2 v0.4.40
 	go.uber.org/atomic v1.11.0
@@ -28,7 +29,6 @@ require (
 	github.com/pelletier/go-toml/v2 v2.2.4 // indirect
 	github.com/sagikazarmark/locafero v0.9.0 // indirect
 	github.com/sourcegraph/conc v0.3.0 // indirect
-	github.com/spf13/afero v1.14.0 // indirect
 	github.com/spf13/cast v1.9.2 // indirect
 	github.com/spf13/pflag v1.0.6 // indirect
 	github.com/subosito/gotenv v1.6.0 // indirect
@@ -13,18 +13,29 @@ type Action struct {
 	ArchiveRom,
 	ArchiveRename,
 	ArchiveDelete,
+	ArchiveEmpty,
+	ArchiveDeleteContents,
 	DeleteRom,
 	Nuke,
 
 	CollectionRename,
 	CollectionDelete,
 	CollectionAdd,
+	CollectionExport,
+	CollectionDuplicate,
+	CollectionMerge,
+	CollectionIntersect,
+	CollectionSubtract,
+	CollectionSort,
+	CollectionArt,
 
 	PlayHistoryOpen,
 	PlayHistoryAdopt,
 
 	GlobalDownloadArt,
-	GlobalClearRecents sum.Int[Action]
+	GlobalClearRecents,
+	GlobalRefreshSmartCollections,
+	GlobalImportCollection sum.Int[Action]
 }
 
 var Actions = sum.Int[Action]{}.Sum()
@@ -37,19 +48,32 @@ var ActionMap = map[string]sum.Int[Action]{
 	"Archive ROM":        Actions.ArchiveRom,
 	"Rename Archive":     Actions.ArchiveRename,
 	"Delete Archive":     Actions.ArchiveDelete,
+	"Empty Archive":      Actions.ArchiveEmpty,
 	"Delete ROM":         Actions.DeleteRom,
 	"Nuclear Option":     Actions.Nuke,
 
+	"Delete Archive and Contents": Actions.ArchiveDeleteContents,
+
 	"Rename Collection": Actions.CollectionRename,
 	"Delete Collection": Actions.CollectionDelete,
 	"Add to Collection": Actions.CollectionAdd,
+	"Export Collection": Actions.CollectionExport,
+
+	"Duplicate Collection": Actions.CollectionDuplicate,
+	"Merge Into":           Actions.CollectionMerge,
+	"Intersect With":       Actions.CollectionIntersect,
+	"Subtract":             Actions.CollectionSubtract,
+	"Sort Collection":      Actions.CollectionSort,
+	"Set Collection Icon":  Actions.CollectionArt,
 
 	"View Play Details":	Actions.PlayHistoryOpen,
 }
 
 var GlobalActionMap = map[string]sum.Int[Action]{
-	"Download Missing Art":  Actions.GlobalDownloadArt,
-	"Clear Recently Played": Actions.GlobalClearRecents,
+	"Download Missing Art":      Actions.GlobalDownloadArt,
+	"Clear Recently Played":     Actions.GlobalClearRecents,
+	"Refresh Smart Collections": Actions.GlobalRefreshSmartCollections,
+	"Import Collection":         Actions.GlobalImportCollection,
 }
 
 var ActionKeys = []string{
@@ -64,6 +88,8 @@ var ActionKeys = []string{
 var GlobalActionKeys = []string{
 	"Download Missing Art",
 	"Clear Recently Played",
+	"Refresh Smart Collections",
+	"Import Collection",
 }
 
 var BulkActionKeys = []string{
@@ -78,12 +104,21 @@ var BulkActionKeys = []string{
 
 var CollectionActionKeys = []string{
 	"Rename Collection",
+	"Sort Collection",
+	"Set Collection Icon",
+	"Duplicate Collection",
+	"Merge Into",
+	"Intersect With",
+	"Subtract",
+	"Export Collection",
 	"Delete Collection",
 }
 
 var ArchiveActionKeys = []string{
 	"Rename Archive",
 	"Delete Archive",
+	"Empty Archive",
+	"Delete Archive and Contents",
 }
 
 var PlayHistoryActionKeys = []string{
@@ -7,7 +7,7 @@ import (
 type AppState struct {
 	Config *Config
 
-	MenuPositionList []MenuPositionPointer
+	NavigationStack []NavigationFrame
 
 	GamePlayMap 	map[string][]PlayHistoryAggregate
 	ConsolePlayMap 	map[string]int
@@ -22,6 +22,12 @@ func (a AppState) MarshalLogObject(enc zapcore.ObjectEncoder) error {
 	return nil
 }
 
+// NavigationFrame is a screen on the navigation stack together with where its cursor was left.
+type NavigationFrame struct {
+	Screen   Screen
+	Position MenuPositionPointer
+}
+
 type MenuPositionPointer struct {
 	SelectedIndex		int
 	SelectedPosition    int
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// ArchivedGame is what an archive remembers about a game put in it. Path is where the game is inside the archive and
+// OriginalPath where it was in the ROM directory. ArchivedAt is zero for games archived before archives kept a
+// manifest.
+type ArchivedGame struct {
+	Path         string    `yaml:"path"`
+	OriginalPath string    `yaml:"original_path"`
+	ArchivedAt   time.Time `yaml:"archived_at,omitempty"`
+	Size         int64     `yaml:"size"`
+	PlayTime     int       `yaml:"play_time"`
+	Note         string    `yaml:"note,omitempty"`
+}
+
+// ArchiveSummary describes what is in an archive before it is emptied or deleted. Platforms counts the games in each
+// platform folder and PlayedGames how many of them have play history.
+type ArchiveSummary struct {
+	Platforms   map[string]int
+	Games       int
+	Size        int64
+	PlayedGames int
+}
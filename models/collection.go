@@ -11,3 +11,39 @@ type Collection struct {
 func (c Collection) Value() interface{} {
 	return c
 }
+
+// CollectionExport is a collection written out to be imported on another SD card, where the ROM folders may be named
+// differently. Each game keeps enough about itself to be found again without its path.
+type CollectionExport struct {
+	Name  string                  `yaml:"name"`
+	Games []CollectionExportEntry `yaml:"games"`
+}
+
+type CollectionExportEntry struct {
+	Path        string `yaml:"path"`
+	Platform    string `yaml:"platform"`
+	DisplayName string `yaml:"display_name"`
+	Size        int64  `yaml:"size,omitempty"`
+	Hash        string `yaml:"sha1,omitempty"`
+}
+
+// CollectionImport reports how the games of an imported collection were found on this SD card.
+type CollectionImport struct {
+	Collection Collection
+	ByPath     int
+	ByName     int
+	ByHash     int
+	ByFuzzy    int
+	Unresolved []CollectionExportEntry
+}
+
+// CollectionProblem is a collection entry whose game is no longer where the entry says, along with where it may have
+// gone. Replacement is a game in the platform folder and Archived one in an archive, either left empty when none was
+// found.
+type CollectionProblem struct {
+	Collection  Collection
+	Entry       shared.Item
+	Replacement shared.Item
+	Archived    shared.Item
+	Archive     shared.RomDirectory
+}
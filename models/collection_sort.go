@@ -0,0 +1,53 @@
+package models
+
+import (
+	"qlova.tech/sum"
+	"time"
+)
+
+type CollectionSort struct {
+	Manual,
+	Name,
+	Platform,
+	PlayTime,
+	LastPlayed,
+	DateAdded sum.Int[CollectionSort]
+}
+
+var CollectionSorts = sum.Int[CollectionSort]{}.Sum()
+
+// CollectionSortMap maps the values saved in collection_order.yml to sort orders.
+var CollectionSortMap = map[string]sum.Int[CollectionSort]{
+	"manual":      CollectionSorts.Manual,
+	"name":        CollectionSorts.Name,
+	"platform":    CollectionSorts.Platform,
+	"play_time":   CollectionSorts.PlayTime,
+	"last_played": CollectionSorts.LastPlayed,
+	"date_added":  CollectionSorts.DateAdded,
+}
+
+// CollectionSortKeys is the order the sort orders are offered in.
+var CollectionSortKeys = []string{
+	"manual",
+	"name",
+	"platform",
+	"play_time",
+	"last_played",
+	"date_added",
+}
+
+var CollectionSortLabels = map[string]string{
+	"manual":      "Manual",
+	"name":        "Name",
+	"platform":    "Platform",
+	"play_time":   "Play Time",
+	"last_played": "Last Played",
+	"date_added":  "Date Added",
+}
+
+// CollectionOrder is how a collection is kept in order. NextUI collections are plain lists of paths, so when each game
+// was added is remembered alongside them.
+type CollectionOrder struct {
+	Sort  string               `yaml:"sort,omitempty"`
+	Added map[string]time.Time `yaml:"added,omitempty"`
+}
@@ -14,6 +14,10 @@ type Config struct {
 	LogLevel        			string                   		`yaml:"log_level"`
 	PlayHistoryShowCollections	bool                            `yaml:"play_history_show_collections"`
 	PlayHistoryShowArchives     bool                          	`yaml:"play_history_show_archives"`
+	TrashRetentionDays          int                             `yaml:"trash_retention_days"`
+	CompressArchives            bool                            `yaml:"compress_archives"`
+	ArchiveLocations            []string                        `yaml:"archive_locations"`
+	GameListSort                map[string]string               `yaml:"game_list_sort"`
 }
 
 func (c *Config) MarshalLogObject(enc zapcore.ObjectEncoder) error {
@@ -0,0 +1,43 @@
+package models
+
+import "qlova.tech/sum"
+
+type GameListSort struct {
+	Name,
+	Size,
+	DateAdded,
+	LastPlayed,
+	PlayTime,
+	MissingArt sum.Int[GameListSort]
+}
+
+var GameListSorts = sum.Int[GameListSort]{}.Sum()
+
+// GameListSortMap maps the values saved in config.yml to sort orders.
+var GameListSortMap = map[string]sum.Int[GameListSort]{
+	"name":        GameListSorts.Name,
+	"size":        GameListSorts.Size,
+	"date_added":  GameListSorts.DateAdded,
+	"last_played": GameListSorts.LastPlayed,
+	"play_time":   GameListSorts.PlayTime,
+	"missing_art": GameListSorts.MissingArt,
+}
+
+// GameListSortKeys is the order the games list offers its sort orders in.
+var GameListSortKeys = []string{
+	"name",
+	"size",
+	"date_added",
+	"last_played",
+	"play_time",
+	"missing_art",
+}
+
+var GameListSortLabels = map[string]string{
+	"name":        "Name",
+	"size":        "Size",
+	"date_added":  "Date Added",
+	"last_played": "Last Played",
+	"play_time":   "Play Time",
+	"missing_art": "Missing Art",
+}
@@ -0,0 +1,19 @@
+package models
+
+// Layout describes where NextUI keeps the library on the SD card and where the pak keeps its own state.
+type Layout struct {
+	RomDirectory           string
+	CollectionDirectory    string
+	SaveFileDirectory      string
+	UserDataDirectory      string
+	GameTrackerDBPath      string
+	RecentlyPlayedFile     string
+	TrashDirectory         string
+	LibraryIndexPath       string
+	ExportDirectory        string
+	OperationJournalPath   string
+	UndoHistoryPath        string
+	ArchivedReferencesPath string
+	CollectionOrderPath    string
+	RecentlyPlayedPinsPath string
+}
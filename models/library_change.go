@@ -0,0 +1,26 @@
+package models
+
+import "qlova.tech/sum"
+
+type LibraryChangeKind struct {
+	RomRenamed,
+	RomArchived,
+	RomRestored,
+	RomDeleted,
+
+	CollectionSaved,
+	CollectionRenamed,
+	CollectionDeleted,
+
+	GameTrackerChanged sum.Int[LibraryChangeKind]
+}
+
+var LibraryChangeKinds = sum.Int[LibraryChangeKind]{}.Sum()
+
+// LibraryChange describes something a util changed on the SD card. Path is where the ROM or collection file is now and
+// PreviousPath where it was before, for changes that move things around.
+type LibraryChange struct {
+	Kind         sum.Int[LibraryChangeKind]
+	Path         string
+	PreviousPath string
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"time"
+)
+
+// IndexedRom is a ROM as recorded in the library index. Archive holds the archive folder the ROM sits in and is empty
+// for ROMs that are still in the library.
+type IndexedRom struct {
+	Item        shared.Item
+	Platform    shared.RomDirectory
+	Archive     string
+	Size        int64
+	ModTime     time.Time
+	HasArt      bool
+	HasSave     bool
+	Collections []string
+}
+
+type PlatformStatistics struct {
+	Platform   shared.RomDirectory
+	Roms       int
+	Archived   int
+	Size       int64
+	MissingArt int
+	WithSaves  int
+}
+
+type LibraryStatistics struct {
+	Platforms  []PlatformStatistics
+	Roms       int
+	Archived   int
+	Size       int64
+	MissingArt int
+	WithSaves  int
+}
@@ -0,0 +1,9 @@
+package models
+
+// RecentlyPlayedEntry is a line of NextUI's recently played list. Path is in the same form as collection entries and
+// Alias is the name NextUI shows instead of the file name, if any. Pinned entries are kept at the top of the list.
+type RecentlyPlayedEntry struct {
+	Path   string
+	Alias  string
+	Pinned bool
+}
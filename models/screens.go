@@ -9,6 +9,7 @@ type ScreenName struct {
 
 	GamesList,
 	SearchBox,
+	GlobalSearch,
 	Actions,
 	BulkActions,
 	AddToCollection,
@@ -26,6 +27,7 @@ type ScreenName struct {
 	CollectionOptions,
 	CollectionManagement,
 	CollectionCreate,
+	CollectionCheck,
 
 	PlayHistoryActions,
 	PlayHistoryGameDetails,
@@ -33,7 +35,10 @@ type ScreenName struct {
 	PlayHistoryGameList,
 	PlayHistoryList,
 
-	GlobalActions sum.Int[ScreenName]
+	GlobalActions,
+	RecentlyPlayed,
+	UndoHistory,
+	Trash sum.Int[ScreenName]
 }
 
 var ScreenNames = sum.Int[ScreenName]{}.Sum()
@@ -0,0 +1,22 @@
+package models
+
+// SmartCollection is a collection whose games are picked by rules instead of by hand. It is written out as a regular
+// NextUI collection named Name so it shows up on the device like any other.
+type SmartCollection struct {
+	Name  string               `yaml:"name"`
+	Rules SmartCollectionRules `yaml:"rules"`
+	Sort  string               `yaml:"sort,omitempty"`
+	Limit int                  `yaml:"limit,omitempty"`
+}
+
+// SmartCollectionRules must all hold for a game to be picked. Rules left empty are ignored.
+type SmartCollectionRules struct {
+	Platforms        []string `yaml:"platforms,omitempty"`
+	Pattern          string   `yaml:"pattern,omitempty"`
+	Region           string   `yaml:"region,omitempty"`
+	MinPlayTime      string   `yaml:"min_play_time,omitempty"`
+	MaxPlayTime      string   `yaml:"max_play_time,omitempty"`
+	PlayedWithinDays int      `yaml:"played_within_days,omitempty"`
+	NeverPlayed      bool     `yaml:"never_played,omitempty"`
+	MissingArt       bool     `yaml:"missing_art,omitempty"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+const (
+	TrashKindRom            = "ROM"
+	TrashKindArt            = "Art"
+	TrashKindCollection     = "Collection"
+	TrashKindRecentlyPlayed = "Recents"
+)
+
+// TrashEntry is one deletion held in the trash. Every file that went in together is restored together.
+type TrashEntry struct {
+	ID          string        `yaml:"id"`
+	Kind        string        `yaml:"kind"`
+	Description string        `yaml:"description"`
+	DeletedAt   time.Time     `yaml:"deleted_at"`
+	Files       []TrashedFile `yaml:"files"`
+}
+
+type TrashedFile struct {
+	OriginalPath string `yaml:"original_path"`
+	StoredPath   string `yaml:"stored_path"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"time"
+)
+
+const (
+	UndoRenameRom         = "rename_rom"
+	UndoArchiveRom        = "archive_rom"
+	UndoRestoreCollection = "restore_collection"
+	UndoRenameCollection  = "rename_collection"
+	UndoRenameArchive     = "rename_archive"
+	UndoRestoreTrash      = "restore_trash"
+	UndoRestoreRecents    = "restore_recents"
+)
+
+// UndoEntry is one user action in the undo history. Steps hold the inverse operations and are replayed in reverse.
+type UndoEntry struct {
+	ID          int64      `yaml:"id"`
+	Description string     `yaml:"description"`
+	CreatedAt   time.Time  `yaml:"created_at"`
+	Steps       []UndoStep `yaml:"steps"`
+}
+
+type UndoStep struct {
+	Kind              string              `yaml:"kind"`
+	RomDirectory      shared.RomDirectory `yaml:"rom_directory,omitempty"`
+	Filename          string              `yaml:"filename,omitempty"`
+	PreviousFilename  string              `yaml:"previous_filename,omitempty"`
+	Archive           string              `yaml:"archive,omitempty"`
+	CollectionFile    string              `yaml:"collection_file,omitempty"`
+	CollectionEntries []string            `yaml:"collection_entries,omitempty"`
+	Path              string              `yaml:"path,omitempty"`
+	PreviousPath      string              `yaml:"previous_path,omitempty"`
+	TrashID           string              `yaml:"trash_id,omitempty"`
+	RecentsLines      []string            `yaml:"recents_lines,omitempty"`
+}
@@ -0,0 +1,66 @@
+package state
+
+import (
+	"nextui-game-manager/models"
+	"nextui-game-manager/utils"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+func init() {
+	utils.SubscribeLibraryChanges(onLibraryChange)
+}
+
+// onLibraryChange keeps the cached maps in step with the SD card. Caches that have not been built yet are left alone
+// since they will be generated fresh the first time they are read.
+func onLibraryChange(change models.LibraryChange) {
+	switch change.Kind {
+	case models.LibraryChangeKinds.CollectionSaved,
+		models.LibraryChangeKinds.CollectionRenamed,
+		models.LibraryChangeKinds.CollectionDeleted:
+		refreshCollectionInMap(change.PreviousPath, change.Path)
+	case models.LibraryChangeKinds.GameTrackerChanged:
+		ClearPlayMaps()
+	}
+}
+
+// refreshCollectionInMap drops every mention of a collection file from the collection map and, if the collection
+// still exists, adds it back under the games it now holds.
+func refreshCollectionInMap(previousFile string, currentFile string) {
+	temp := GetAppState()
+	if temp.CollectionMap == nil {
+		return
+	}
+
+	for gameName, collections := range temp.CollectionMap {
+		collections = slices.DeleteFunc(collections, func(collection models.Collection) bool {
+			return collection.CollectionFile == currentFile || (previousFile != "" && collection.CollectionFile == previousFile)
+		})
+
+		if len(collections) == 0 {
+			delete(temp.CollectionMap, gameName)
+		} else {
+			temp.CollectionMap[gameName] = collections
+		}
+	}
+
+	if utils.DoesFileExists(currentFile) {
+		collection, err := utils.ReadCollection(models.Collection{
+			DisplayName:    strings.TrimSuffix(filepath.Base(currentFile), filepath.Ext(currentFile)),
+			CollectionFile: currentFile,
+		})
+
+		if err == nil {
+			for _, game := range collection.Games {
+				collections := append(temp.CollectionMap[game.DisplayName], collection)
+				slices.SortFunc(collections, func(a, b models.Collection) int {
+					return strings.Compare(a.DisplayName, b.DisplayName)
+				})
+				temp.CollectionMap[game.DisplayName] = collections
+			}
+		}
+	}
+
+	UpdateAppState(temp)
+}
@@ -0,0 +1,169 @@
+package state
+
+import (
+	"nextui-game-manager/models"
+	"qlova.tech/sum"
+	"slices"
+)
+
+type navigationKind int
+
+const (
+	navigationRedraw navigationKind = iota
+	navigationPush
+	navigationPop
+	navigationPopTo
+	navigationPopToRoot
+	navigationReplace
+	navigationReturn
+	navigationExit
+)
+
+// Navigation describes how the stack changes once a screen has finished drawing. Transition handlers return one and
+// the application loop applies it with Navigate, so no handler has to count menu positions by hand.
+type Navigation struct {
+	kind        navigationKind
+	screen      models.Screen
+	targets     []sum.Int[models.ScreenName]
+	update      func(parent models.Screen) models.Screen
+	resetCursor bool
+}
+
+// Redraw draws the current screen again, keeping its cursor.
+func Redraw() Navigation {
+	return Navigation{kind: navigationRedraw}
+}
+
+// Push opens a screen on top of the current one with a fresh cursor.
+func Push(screen models.Screen) Navigation {
+	return Navigation{kind: navigationPush, screen: screen}
+}
+
+// Pop goes back to the exact screen below, cursor and all.
+func Pop() Navigation {
+	return Navigation{kind: navigationPop}
+}
+
+// PopTo goes back to the closest screen with one of the given names, or to the root if there is none.
+func PopTo(targets ...sum.Int[models.ScreenName]) Navigation {
+	return Navigation{kind: navigationPopTo, targets: targets}
+}
+
+func PopToRoot() Navigation {
+	return Navigation{kind: navigationPopToRoot}
+}
+
+// Replace swaps the current screen for another, keeping its cursor. Used when the same screen needs new inputs, e.g.
+// after a rename or a new search filter.
+func Replace(screen models.Screen) Navigation {
+	return Navigation{kind: navigationReplace, screen: screen}
+}
+
+// Return goes back a screen and hands the screen below to update so it can take on the result, e.g. a search box
+// giving its filter to the list that opened it. If the screen below is not an S it is shown unchanged.
+func Return[S models.Screen](update func(parent S) models.Screen) Navigation {
+	return Navigation{
+		kind: navigationReturn,
+		update: func(parent models.Screen) models.Screen {
+			if typed, ok := parent.(S); ok {
+				return update(typed)
+			}
+			return parent
+		},
+	}
+}
+
+// Exit leaves the application loop.
+func Exit() Navigation {
+	return Navigation{kind: navigationExit}
+}
+
+// ResetCursor moves the cursor of whichever screen ends up on top back to the first item, for lists that lost entries.
+func (n Navigation) ResetCursor() Navigation {
+	n.resetCursor = true
+	return n
+}
+
+// StartNavigation clears the stack and makes root the bottom screen.
+func StartNavigation(root models.Screen) models.Screen {
+	temp := GetAppState()
+	temp.NavigationStack = []models.NavigationFrame{{Screen: root}}
+	UpdateAppState(temp)
+
+	return root
+}
+
+// Navigate applies a navigation to the stack and returns the screen to draw next. It returns false on Exit.
+func Navigate(navigation Navigation) (models.Screen, bool) {
+	if navigation.kind == navigationExit {
+		return nil, false
+	}
+
+	temp := GetAppState()
+	stack := temp.NavigationStack
+
+	switch navigation.kind {
+	case navigationPush:
+		stack = append(stack, models.NavigationFrame{Screen: navigation.screen})
+	case navigationPop:
+		stack = popFrames(stack, 1)
+	case navigationPopTo:
+		target := 0
+		for i := len(stack) - 1; i >= 0; i-- {
+			if slices.Contains(navigation.targets, stack[i].Screen.Name()) {
+				target = i
+				break
+			}
+		}
+		stack = stack[:target+1]
+	case navigationPopToRoot:
+		stack = stack[:1]
+	case navigationReplace:
+		stack[len(stack)-1].Screen = navigation.screen
+	case navigationReturn:
+		stack = popFrames(stack, 1)
+		stack[len(stack)-1].Screen = navigation.update(stack[len(stack)-1].Screen)
+	}
+
+	if navigation.resetCursor {
+		stack[len(stack)-1].Position = models.MenuPositionPointer{}
+	}
+
+	temp.NavigationStack = stack
+	UpdateAppState(temp)
+
+	return stack[len(stack)-1].Screen, true
+}
+
+// popFrames never removes the root screen.
+func popFrames(stack []models.NavigationFrame, count int) []models.NavigationFrame {
+	return stack[:max(1, len(stack)-count)]
+}
+
+func UpdateCurrentMenuPosition(newIndex int, newPosition int) {
+	temp := GetAppState()
+	if len(temp.NavigationStack) == 0 {
+		return
+	}
+
+	temp.NavigationStack[len(temp.NavigationStack)-1].Position = models.MenuPositionPointer{
+		SelectedIndex:    newIndex,
+		SelectedPosition: newPosition,
+	}
+	UpdateAppState(temp)
+}
+
+func GetCurrentMenuPosition() (int, int) {
+	stack := GetAppState().NavigationStack
+	if len(stack) == 0 {
+		return 0, 0
+	}
+
+	currentPosition := stack[len(stack)-1].Position
+	selectedIndex := currentPosition.SelectedIndex
+	selectedPosition := currentPosition.SelectedPosition
+
+	selectedPosition = max(0, selectedIndex-selectedPosition)
+
+	return selectedIndex, selectedPosition
+}
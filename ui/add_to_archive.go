@@ -49,7 +49,7 @@ func (atas AddToArchiveScreen) Draw() (item interface{}, exitCode int, e error)
 	var archiveFolderEntries []gaba.MenuItem
 	for _, item := range archiveFolders {
 		archiveFolderEntries = append(archiveFolderEntries, gaba.MenuItem{
-			Text:               item,
+			Text:               utils.ArchiveDisplayName(item),
 			Selected:           false,
 			Focused:            false,
 			Metadata:           item,
@@ -80,28 +80,35 @@ func (atas AddToArchiveScreen) Draw() (item interface{}, exitCode int, e error)
 
 	if selection.IsSome() && !selection.Unwrap().ActionTriggered && selection.Unwrap().SelectedIndex != -1 {
 		state.UpdateCurrentMenuPosition(selection.Unwrap().SelectedIndex, selection.Unwrap().VisiblePosition)
-		archiveFolder := selection.Unwrap().SelectedItem.Text
+		archiveFolder := selection.Unwrap().SelectedItem.Metadata.(string)
+		archiveName := selection.Unwrap().SelectedItem.Text
 
-		message := fmt.Sprintf("Archive %s into %s?", atas.Games[0].DisplayName, archiveFolder)
+		message := fmt.Sprintf("Archive %s into %s?", atas.Games[0].DisplayName, archiveName)
 		if bulk {
-			message = fmt.Sprintf("Archive %d games into %s?", len(atas.Games), archiveFolder)
+			message = fmt.Sprintf("Archive %d games into %s?", len(atas.Games), archiveName)
 		}
 
 		if !utils.ConfirmAction(message) {
 			return nil, 404, nil
 		}
 
+		successMessage := fmt.Sprintf("Added %s To Archive %s!", atas.Games[0].DisplayName, archiveName)
+		if bulk {
+			successMessage = fmt.Sprintf("Added %d Games To Archive %s!", len(atas.Games), archiveName)
+		}
+
+		var undoSteps []models.UndoStep
 		for _, game := range atas.Games {
-			if err := utils.ArchiveRom(game, atas.RomDirectory, archiveFolder); err != nil {
+			romDirectory := utils.RomDirectoryForItem(game, atas.RomDirectory)
+			if err := utils.ArchiveRom(game, romDirectory, archiveFolder, state.GetAppState().Config.CompressArchives); err != nil {
+				utils.RecordUndo(successMessage, undoSteps...)
 				utils.ShowTimedMessage(fmt.Sprintf("Unable to archive %s!", game.DisplayName), time.Second*3)
 				return nil, 404, err
 			}
+			undoSteps = append(undoSteps, utils.ArchiveRomUndoStep(game, romDirectory, archiveFolder))
 		}
 
-		successMessage := fmt.Sprintf("Added %s To Archive %s!", atas.Games[0].DisplayName, archiveFolder)
-		if bulk {
-			successMessage = fmt.Sprintf("Added %d Games To Archive %s!", len(atas.Games), archiveFolder)
-		}
+		utils.RecordUndo(successMessage, undoSteps...)
 
 		utils.ShowTimedMessage(successMessage, time.Second*2)
 
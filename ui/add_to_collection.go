@@ -64,6 +64,11 @@ func (a AddToCollectionScreen) Draw() (collection interface{}, exitCode int, e e
 			CollectionFile: item.Path,
 		}
 
+		// Smart collections are rewritten from their rules, so anything added here would not last
+		if utils.IsSmartCollection(collection) {
+			continue
+		}
+
 		var err error
 		collection, err = utils.ReadCollection(collection)
 		if err != nil {
@@ -161,7 +166,6 @@ func (a AddToCollectionScreen) Draw() (collection interface{}, exitCode int, e e
 
 	if selection.IsSome() && !selection.Unwrap().ActionTriggered && selection.Unwrap().SelectedIndex != -1 {
 		state.UpdateCurrentMenuPosition(selection.Unwrap().SelectedIndex, selection.Unwrap().VisiblePosition)
-		state.ClearCollectionMap()
 		selectedCol := selection.Unwrap().SelectedItem.Metadata.(models.Collection)
 
 		_, err := utils.AddCollectionGames(selectedCol, a.Games)
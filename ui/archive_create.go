@@ -6,11 +6,14 @@ import (
 	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
 	"nextui-game-manager/models"
 	"nextui-game-manager/utils"
+	"path/filepath"
 	"qlova.tech/sum"
 	"strings"
 	"time"
 )
 
+const archiveLocationRomFolder = "ROM Folder (Hidden)"
+
 type ArchiveCreateScreen struct {
 	Games                []shared.Item
 	RomDirectory         shared.RomDirectory
@@ -45,14 +48,30 @@ func (acs ArchiveCreateScreen) Draw() (value interface{}, exitCode int, e error)
 			return nil, 2, nil
 		}
 
-		newArchiveName = utils.PrepArchiveName(newArchiveName)
+		location, err := pickArchiveLocation()
+		if err != nil {
+			return nil, -1, err
+		}
+
+		if location == "" {
+			return nil, 2, nil
+		}
+
+		// Archives elsewhere are not hidden, and a hidden one would not be listed
+		newArchiveName = strings.TrimLeft(newArchiveName, ".")
+		archiveRoot := filepath.Join(location, newArchiveName)
+		if location == utils.GetRomDirectory() {
+			newArchiveName = utils.PrepArchiveName(newArchiveName)
 
-		if newArchiveName == ".media" {
-			utils.ShowTimedMessage(".media folder is reserved for themes.\nPlease choose a different name.", time.Second*2)
-			return nil, 0, nil
+			if newArchiveName == ".media" {
+				utils.ShowTimedMessage(".media folder is reserved for themes.\nPlease choose a different name.", time.Second*2)
+				return nil, 0, nil
+			}
+
+			archiveRoot = utils.GetArchiveRoot(newArchiveName)
 		}
 
-		dirErr := utils.EnsureDirectoryExists(utils.GetArchiveRoot(newArchiveName))
+		dirErr := utils.EnsureDirectoryExists(archiveRoot)
 
 		message := fmt.Sprintf("Created %s!", newArchiveName)
 
@@ -67,3 +86,18 @@ func (acs ArchiveCreateScreen) Draw() (value interface{}, exitCode int, e error)
 
 	return nil, 2, nil
 }
+
+// pickArchiveLocation asks where a new archive goes when archive locations are set up, otherwise it goes in the ROM
+// directory. It returns an empty location when backed out of.
+func pickArchiveLocation() (string, error) {
+	locations := utils.GetArchiveLocations()
+	if len(locations) == 0 {
+		return utils.GetRomDirectory(), nil
+	}
+
+	location, err := pickOption("Create Archive In", append([]string{archiveLocationRomFolder}, locations...))
+	if location == archiveLocationRomFolder {
+		return utils.GetRomDirectory(), err
+	}
+	return location, err
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	gaba "github.com/UncleJunVIP/gabagool/pkg/gabagool"
 	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
-	"github.com/UncleJunVIP/nextui-pak-shared-functions/filebrowser"
 	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
 	"go.uber.org/zap"
 	"nextui-game-manager/models"
@@ -16,6 +15,12 @@ import (
 	"time"
 )
 
+const (
+	archivedGameRestore = "Restore"
+	archivedGameDetails = "Details"
+	archivedGameNote    = "Edit Note"
+)
+
 type ArchiveGamesListScreen struct {
 	Archive              shared.RomDirectory
 	RomDirectory         shared.RomDirectory
@@ -44,9 +49,7 @@ func (agl ArchiveGamesListScreen) Draw() (item interface{}, exitCode int, e erro
 	logger := common.GetLoggerInstance()
 	title := agl.Archive.DisplayName + " : " + agl.RomDirectory.DisplayName
 
-	fb := filebrowser.NewFileBrowser(logger)
-
-	err := fb.CWD(agl.RomDirectory.Path, false)
+	roms, err := utils.ListLibraryDirectory(agl.RomDirectory.Path)
 	if err != nil {
 		logger.Info("Unable to fetch ROM directory! Continuing without them",
 			zap.String("rom_directory", agl.RomDirectory.Path),
@@ -54,11 +57,9 @@ func (agl ArchiveGamesListScreen) Draw() (item interface{}, exitCode int, e erro
 		return shared.Item{}, 1, err
 	}
 
-	roms := fb.Items
-
 	if agl.SearchFilter != "" {
 		title = "[Search: \"" + agl.SearchFilter + "\"]"
-		roms = utils.FilterList(roms, agl.SearchFilter)
+		roms = utils.FuzzyFilterList(roms, agl.SearchFilter)
 	}
 
 	var directoryEntries []gaba.MenuItem
@@ -69,7 +70,8 @@ func (agl ArchiveGamesListScreen) Draw() (item interface{}, exitCode int, e erro
 			continue
 		}
 
-		itemName := strings.TrimSuffix(item.Filename, filepath.Ext(item.Filename))
+		uncompressedName := utils.UncompressedName(item.Filename)
+		itemName := strings.TrimSuffix(uncompressedName, filepath.Ext(uncompressedName))
 
 		if !item.IsSelfContainedDirectory && !item.IsMultiDiscDirectory && item.IsDirectory {
 			itemName = "/" + itemName
@@ -106,12 +108,13 @@ func (agl ArchiveGamesListScreen) Draw() (item interface{}, exitCode int, e erro
 		{ButtonName: "B", HelpText: "Back"},
 		{ButtonName: "X", HelpText: "Search"},
 		{ButtonName: "Menu", HelpText: "Help"},
-		{ButtonName: "A", HelpText: "Restore"},
+		{ButtonName: "A", HelpText: "Select"},
 	}
 
 	options.EnableHelp = true
 	options.HelpTitle = "Archive ROMs List Controls"
 	options.HelpText = []string{
+		"• A: Restore, view Details or Edit Note",
 		"• X: Open Options",
 		"• Select: Toggle Multi-Select",
 		"• Start: Confirm Multi-Selection",
@@ -155,6 +158,20 @@ func (agl ArchiveGamesListScreen) Draw() (item interface{}, exitCode int, e erro
 				}
 				return newRomDirectory, 0, nil
 			}
+
+			choice, err := pickOption(firstItem.DisplayName, []string{archivedGameRestore, archivedGameDetails, archivedGameNote})
+			if err != nil {
+				return nil, -1, err
+			}
+
+			switch choice {
+			case "":
+				return agl.SearchFilter, 4, nil
+			case archivedGameDetails:
+				return agl.showDetails(firstItem)
+			case archivedGameNote:
+				return agl.editNote(firstItem)
+			}
 		}
 
 		if !utils.ConfirmAction(confirmMessage) {
@@ -177,3 +194,73 @@ func (agl ArchiveGamesListScreen) Draw() (item interface{}, exitCode int, e erro
 
 	return nil, 2, nil
 }
+
+// showDetails shows what the archive remembers about a game: where it came from, when it was archived, how big it is
+// and how long it had been played.
+func (agl ArchiveGamesListScreen) showDetails(game shared.Item) (interface{}, int, error) {
+	logger := common.GetLoggerInstance()
+
+	archived, found := utils.FindArchivedGame(game.Path)
+	if !found {
+		utils.ShowTimedMessage(fmt.Sprintf("No details recorded for %s!", game.DisplayName), time.Second*2)
+		return agl.SearchFilter, 4, nil
+	}
+
+	archivedAt := "Unknown"
+	if !archived.ArchivedAt.IsZero() {
+		archivedAt = archived.ArchivedAt.Format(time.UnixDate)
+	}
+
+	note := archived.Note
+	if note == "" {
+		note = "None"
+	}
+
+	options := gaba.DefaultInfoScreenOptions()
+	options.Sections = []gaba.Section{
+		gaba.NewInfoSection(game.DisplayName, []gaba.MetadataItem{
+			{Label: "Archived From", Value: strings.TrimPrefix(archived.OriginalPath, utils.GetRomDirectory()+"/")},
+			{Label: "Archived On", Value: archivedAt},
+			{Label: "Size", Value: utils.FormatSize(archived.Size)},
+			{Label: "Play Time", Value: utils.ConvertSecondsToHumanReadable(archived.PlayTime)},
+			{Label: "Note", Value: note},
+		}),
+	}
+	options.ShowThemeBackground = false
+
+	footerItems := []gaba.FooterHelpItem{
+		{ButtonName: "B", HelpText: "Back"},
+	}
+
+	if _, err := gaba.DetailScreen("Archived Game", options, footerItems); err != nil {
+		logger.Error("Unable to display archived game details", zap.Error(err))
+		return nil, -1, err
+	}
+
+	return agl.SearchFilter, 4, nil
+}
+
+// editNote records why a game was archived in the archive manifest. Clearing the text removes the note.
+func (agl ArchiveGamesListScreen) editNote(game shared.Item) (interface{}, int, error) {
+	logger := common.GetLoggerInstance()
+
+	archived, _ := utils.FindArchivedGame(game.Path)
+
+	note, err := gaba.Keyboard(archived.Note)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	if note.IsNone() {
+		return agl.SearchFilter, 4, nil
+	}
+
+	if err := utils.SetArchivedGameNote(game.Path, note.Unwrap()); err != nil {
+		logger.Error("Unable to save archive note", zap.String("game", game.Path), zap.Error(err))
+		utils.ShowTimedMessage(fmt.Sprintf("Unable to save the note for %s!", game.DisplayName), time.Second*2)
+		return agl.SearchFilter, 4, nil
+	}
+
+	utils.ShowTimedMessage(fmt.Sprintf("Saved the note for %s!", game.DisplayName), time.Second*2)
+	return agl.SearchFilter, 4, nil
+}
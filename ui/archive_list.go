@@ -37,7 +37,7 @@ func (als ArchiveListScreen) Draw() (item interface{}, exitCode int, e error) {
 	var menuItems []gaba.MenuItem
 	for _, archiveFolder := range archiveFolders {
 		archive := gaba.MenuItem{
-			Text:     archiveFolder,
+			Text:     utils.ArchiveDisplayName(archiveFolder),
 			Selected: false,
 			Focused:  false,
 			Metadata: archiveFolder,
@@ -66,7 +66,7 @@ func (als ArchiveListScreen) Draw() (item interface{}, exitCode int, e error) {
 		state.UpdateCurrentMenuPosition(selection.Unwrap().SelectedIndex, selection.Unwrap().VisiblePosition)
 		archive := selection.Unwrap().SelectedItem.Metadata.(string)
 		archiveDirectory := shared.RomDirectory{
-			DisplayName: archive,
+			DisplayName: utils.ArchiveDisplayName(archive),
 			Path: 		 utils.GetArchiveRoot(archive),
 		}
 		return archiveDirectory, 0, nil
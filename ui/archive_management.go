@@ -1,13 +1,14 @@
 package ui
 
 import (
+	"fmt"
 	gaba "github.com/UncleJunVIP/gabagool/pkg/gabagool"
 	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
-	"github.com/UncleJunVIP/nextui-pak-shared-functions/filebrowser"
 	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
 	"go.uber.org/zap"
 	"nextui-game-manager/models"
 	"nextui-game-manager/state"
+	"nextui-game-manager/utils"
 	"qlova.tech/sum"
 )
 
@@ -25,14 +26,13 @@ func (am ArchiveManagementScreen) Name() sum.Int[models.ScreenName] {
 	return models.ScreenNames.ArchiveManagement
 }
 
-// Displays console folders in the selected archive folder and allows for archive deletion if all folders are empty
+// Displays console folders in the selected archive folder and allows for archive deletion if all folders are empty. The
+// title shows how much space compressing the archived games has saved
 func (am ArchiveManagementScreen) Draw() (value interface{}, exitCode int, e error) {
 	logger := common.GetLoggerInstance()
 	title := am.Archive.DisplayName
 
-	fb := filebrowser.NewFileBrowser(logger)
-
-	err := fb.CWD(am.Archive.Path, false)
+	items, err := utils.ListLibraryDirectory(am.Archive.Path)
 	if err != nil {
 		logger.Info("Unable to fetch console directory! Continuing without them",
 			zap.String("rom_directory", am.Archive.Path),
@@ -40,9 +40,16 @@ func (am ArchiveManagementScreen) Draw() (value interface{}, exitCode int, e err
 		return shared.Item{}, 1, err
 	}
 
+	saved, err := utils.ArchiveSpaceSaved(am.Archive.Path)
+	if err != nil {
+		logger.Error("Unable to work out space saved by compression", zap.String("archive", am.Archive.Path), zap.Error(err))
+	} else if saved > 0 {
+		title = fmt.Sprintf("%s (%s Saved)", title, utils.FormatSize(saved))
+	}
+
 	var consoles []gaba.MenuItem
 
-	for _, item := range fb.Items {
+	for _, item := range items {
 		if !item.IsSelfContainedDirectory && !item.IsMultiDiscDirectory && item.IsDirectory {
 			romDirectory := shared.RomDirectory{
 				DisplayName: item.DisplayName,
@@ -9,10 +9,17 @@ import (
 	"nextui-game-manager/models"
 	"nextui-game-manager/state"
 	"nextui-game-manager/utils"
+	"path/filepath"
 	"qlova.tech/sum"
+	"slices"
 	"time"
 )
 
+const (
+	archiveContentsDelete = "Delete Permanently"
+	archiveContentsMove   = "Move to Another Archive"
+)
+
 type ArchiveOptionsScreen struct {
 	Archive   shared.RomDirectory
 }
@@ -64,7 +71,7 @@ func (aos ArchiveOptionsScreen) Draw() (screenReturn interface{}, exitCode int,
 
 		switch action {
 		case models.Actions.ArchiveRename:
-			oldArchive := utils.CleanArchiveName(aos.Archive.DisplayName)
+			oldArchive := utils.CleanArchiveName(filepath.Base(aos.Archive.Path))
 			res, err := gabagool.Keyboard(oldArchive)
 
 			if err != nil {
@@ -74,10 +81,10 @@ func (aos ArchiveOptionsScreen) Draw() (screenReturn interface{}, exitCode int,
 			if res.IsSome() {
 				newArchive := res.Unwrap()
 				if newArchive != oldArchive {
-					newArchive = utils.PrepArchiveName(newArchive)
-					newArchivePath := utils.GetArchiveRoot(newArchive)
+					newArchivePath := utils.RenamedArchiveRoot(aos.Archive.Path, newArchive)
+					newArchive = utils.ArchiveDisplayName(utils.ArchiveNameForRoot(newArchivePath))
 
-					err := utils.MoveFile(aos.Archive.Path, newArchivePath)
+					err := utils.RenameArchive(aos.Archive.Path, newArchivePath)
 
 					if err != nil {
 						logger.Error("Failed to rename archive", zap.Error(err))
@@ -85,6 +92,9 @@ func (aos ArchiveOptionsScreen) Draw() (screenReturn interface{}, exitCode int,
 						return nil, 1, err
 					}
 
+					utils.RecordUndo(fmt.Sprintf("Rename archive %s to %s", aos.Archive.DisplayName, newArchive),
+						utils.RenameArchiveUndoStep(aos.Archive.Path, newArchivePath))
+
 					archiveDirectory := shared.RomDirectory{
 						DisplayName: newArchive,
 						Path: 		 newArchivePath,
@@ -115,13 +125,15 @@ func (aos ArchiveOptionsScreen) Draw() (screenReturn interface{}, exitCode int,
 				}
 
 				if res != "" {
-					utils.ShowTimedMessage(fmt.Sprintf("Cannot delete while file exists in archive\n%s", res), time.Second * 2)
+					utils.ShowTimedMessage(fmt.Sprintf("Cannot delete while file exists in archive\n%s\nUse Delete Archive and Contents instead.", res), time.Second * 3)
 					return aos.Archive, 2, nil
 				}
 
 				return nil, 0, nil
 			}
 
+		case models.Actions.ArchiveEmpty, models.Actions.ArchiveDeleteContents:
+			return aos.clearArchive(action == models.Actions.ArchiveDeleteContents)
 		}
 
 		return aos.Archive, 2, nil
@@ -129,3 +141,185 @@ func (aos ArchiveOptionsScreen) Draw() (screenReturn interface{}, exitCode int,
 
 	return aos.Archive, 2, nil
 }
+
+// clearArchive shows what is in the archive and then either permanently deletes it or moves it into another archive,
+// deleting the archive itself afterwards when asked to
+func (aos ArchiveOptionsScreen) clearArchive(deleteArchive bool) (interface{}, int, error) {
+	logger := common.GetLoggerInstance()
+
+	summaryRes, _ := gabagool.ProcessMessage(fmt.Sprintf("Reading %s...", aos.Archive.DisplayName), gabagool.ProcessMessageOptions{}, func() (interface{}, error) {
+		return utils.SummarizeArchive(aos.Archive)
+	})
+
+	summary, ok := summaryRes.Result.(models.ArchiveSummary)
+	if !ok || summaryRes.Error != nil {
+		logger.Error("Unable to read archive", zap.String("archive", aos.Archive.Path), zap.Error(summaryRes.Error))
+		utils.ShowTimedMessage("Unable to read the archive!", time.Second*2)
+		return aos.Archive, 2, nil
+	}
+
+	if summary.Games == 0 && !deleteArchive {
+		utils.ShowTimedMessage(fmt.Sprintf("%s is already empty!", aos.Archive.DisplayName), time.Second*2)
+		return aos.Archive, 2, nil
+	}
+
+	choice := archiveContentsDelete
+	if summary.Games > 0 {
+		if !aos.showSummary(summary) {
+			return aos.Archive, 2, nil
+		}
+
+		var err error
+		choice, err = pickOption(fmt.Sprintf("%d Games in %s", summary.Games, aos.Archive.DisplayName), []string{archiveContentsDelete, archiveContentsMove})
+		if err != nil || choice == "" {
+			return aos.Archive, 2, err
+		}
+	}
+
+	if choice == archiveContentsMove {
+		return aos.moveContents(summary, deleteArchive)
+	}
+
+	message := fmt.Sprintf("Permanently delete the %d games in %s\nwith their art and saves?", summary.Games, aos.Archive.DisplayName)
+	if deleteArchive {
+		message = fmt.Sprintf("Permanently delete the archive %s\nand the %d games in it?", aos.Archive.DisplayName, summary.Games)
+	}
+
+	confirm, _ := gabagool.ConfirmationMessage(message+"\nThis cannot be undone.", []gabagool.FooterHelpItem{
+		{ButtonName: "B", HelpText: "Cancel"},
+		{ButtonName: "X", HelpText: "Delete"},
+	}, gabagool.MessageOptions{
+		ImagePath:     "",
+		ConfirmButton: gabagool.ButtonX,
+	})
+
+	if confirm.IsNone() || confirm.Unwrap().Cancelled {
+		return aos.Archive, 2, nil
+	}
+
+	deleteRes, _ := gabagool.ProcessMessage(fmt.Sprintf("Deleting %s...", aos.Archive.DisplayName), gabagool.ProcessMessageOptions{}, func() (interface{}, error) {
+		if deleteArchive {
+			return utils.DeleteArchiveAndContents(aos.Archive)
+		}
+		return utils.EmptyArchive(aos.Archive)
+	})
+
+	if deleteRes.Error != nil {
+		logger.Error("Unable to delete archive contents", zap.String("archive", aos.Archive.Path), zap.Error(deleteRes.Error))
+		utils.ShowTimedMessage("Unable to delete the archive contents!", time.Second*2)
+		return aos.Archive, 2, nil
+	}
+
+	deleted, _ := deleteRes.Result.(int)
+	if deleteArchive {
+		utils.ShowTimedMessage(fmt.Sprintf("Deleted %s and %d games!", aos.Archive.DisplayName, deleted), time.Second*2)
+		return nil, 0, nil
+	}
+
+	utils.ShowTimedMessage(fmt.Sprintf("Deleted %d games from %s!", deleted, aos.Archive.DisplayName), time.Second*2)
+	return aos.Archive, 2, nil
+}
+
+// showSummary lists the games in the archive per platform with their total size and how many have been played, and
+// reports whether to go on
+func (aos ArchiveOptionsScreen) showSummary(summary models.ArchiveSummary) bool {
+	logger := common.GetLoggerInstance()
+
+	var metadata []gabagool.MetadataItem
+	platforms := make([]string, 0, len(summary.Platforms))
+	for platform := range summary.Platforms {
+		platforms = append(platforms, platform)
+	}
+	slices.Sort(platforms)
+
+	for _, platform := range platforms {
+		metadata = append(metadata, gabagool.MetadataItem{Label: platform, Value: fmt.Sprintf("%d Games", summary.Platforms[platform])})
+	}
+
+	metadata = append(metadata,
+		gabagool.MetadataItem{Label: "Total Games", Value: fmt.Sprintf("%d", summary.Games)},
+		gabagool.MetadataItem{Label: "Total Size", Value: utils.FormatSize(summary.Size)},
+		gabagool.MetadataItem{Label: "With Play History", Value: fmt.Sprintf("%d", summary.PlayedGames)},
+	)
+
+	options := gabagool.DefaultInfoScreenOptions()
+	options.Sections = []gabagool.Section{gabagool.NewInfoSection(aos.Archive.DisplayName, metadata)}
+	options.ShowThemeBackground = false
+
+	footerItems := []gabagool.FooterHelpItem{
+		{ButtonName: "B", HelpText: "Cancel"},
+		{ButtonName: "A", HelpText: "Continue"},
+	}
+
+	sel, err := gabagool.DetailScreen("Archive Contents", options, footerItems)
+	if err != nil {
+		logger.Error("Unable to display archive contents", zap.Error(err))
+		return false
+	}
+
+	return sel.IsSome() && !sel.Unwrap().Cancelled
+}
+
+// moveContents moves every game into an archive picked from the others. The archive is only deleted afterwards when
+// every game made it across
+func (aos ArchiveOptionsScreen) moveContents(summary models.ArchiveSummary, deleteArchive bool) (interface{}, int, error) {
+	logger := common.GetLoggerInstance()
+
+	archives, err := utils.GetArchiveFileListBasic()
+	if err != nil {
+		return aos.Archive, 2, err
+	}
+
+	archiveNames := make(map[string]string)
+	var choices []string
+	for _, archive := range archives {
+		if utils.GetArchiveRoot(archive) == aos.Archive.Path {
+			continue
+		}
+		displayName := utils.ArchiveDisplayName(archive)
+		archiveNames[displayName] = archive
+		choices = append(choices, displayName)
+	}
+
+	if len(choices) == 0 {
+		utils.ShowTimedMessage("No other archives to move to!", time.Second*2)
+		return aos.Archive, 2, nil
+	}
+
+	choice, err := pickOption("Move to Archive", choices)
+	if err != nil || choice == "" {
+		return aos.Archive, 2, err
+	}
+
+	type moveResult struct{ moved, left int }
+	moveRes, _ := gabagool.ProcessMessage(fmt.Sprintf("Moving %d games to %s...", summary.Games, choice), gabagool.ProcessMessageOptions{}, func() (interface{}, error) {
+		moved, left, err := utils.MoveArchiveContents(aos.Archive, archiveNames[choice])
+		return moveResult{moved, left}, err
+	})
+
+	result, _ := moveRes.Result.(moveResult)
+	if moveRes.Error != nil {
+		logger.Error("Unable to move archive contents", zap.String("archive", aos.Archive.Path), zap.Error(moveRes.Error))
+		utils.ShowTimedMessage("Unable to move the archive contents!", time.Second*2)
+		return aos.Archive, 2, nil
+	}
+
+	if result.left > 0 {
+		utils.ShowTimedMessage(fmt.Sprintf("Moved %d games to %s!\n%d could not be moved.", result.moved, choice, result.left), time.Second*3)
+		return aos.Archive, 2, nil
+	}
+
+	if !deleteArchive {
+		utils.ShowTimedMessage(fmt.Sprintf("Moved %d games to %s!", result.moved, choice), time.Second*2)
+		return aos.Archive, 2, nil
+	}
+
+	if _, err := utils.DeleteArchiveAndContents(aos.Archive); err != nil {
+		logger.Error("Unable to delete archive", zap.String("archive", aos.Archive.Path), zap.Error(err))
+		utils.ShowTimedMessage(fmt.Sprintf("Moved %d games to %s!\nUnable to delete the archive.", result.moved, choice), time.Second*3)
+		return aos.Archive, 2, nil
+	}
+
+	utils.ShowTimedMessage(fmt.Sprintf("Moved %d games to %s and deleted %s!", result.moved, choice, aos.Archive.DisplayName), time.Second*2)
+	return nil, 0, nil
+}
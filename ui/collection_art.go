@@ -0,0 +1,179 @@
+package ui
+
+import (
+	"fmt"
+	"github.com/UncleJunVIP/gabagool/pkg/gabagool"
+	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"go.uber.org/zap"
+	"nextui-game-manager/models"
+	"nextui-game-manager/utils"
+	"path/filepath"
+	"time"
+)
+
+const (
+	collectionArtFromGame  = "Use a Game's Art"
+	collectionArtGrid      = "Grid of the First Four Games"
+	collectionArtFromImage = "Image on the SD Card"
+	collectionArtRemove    = "Remove Icon"
+)
+
+// setCollectionArt offers the ways to give a collection art and writes it where NextUI shows it.
+func setCollectionArt(collection models.Collection) error {
+	logger := common.GetLoggerInstance()
+
+	sources := []string{collectionArtFromGame, collectionArtGrid, collectionArtFromImage}
+	if utils.DoesFileExists(utils.CollectionArtPath(collection)) {
+		sources = append(sources, collectionArtRemove)
+	}
+
+	var sourceEntries []gabagool.MenuItem
+	for _, source := range sources {
+		sourceEntries = append(sourceEntries, gabagool.MenuItem{
+			Text:     source,
+			Selected: false,
+			Focused:  false,
+			Metadata: source,
+		})
+	}
+
+	options := gabagool.DefaultListOptions(fmt.Sprintf("%s Icon", collection.DisplayName), sourceEntries)
+	options.SmallTitle = true
+	options.FooterHelpItems = []gabagool.FooterHelpItem{
+		{ButtonName: "B", HelpText: "Back"},
+		{ButtonName: "A", HelpText: "Select"},
+	}
+
+	selection, err := gabagool.List(options)
+	if err != nil {
+		return err
+	}
+
+	if !selection.IsSome() || selection.Unwrap().SelectedIndex == -1 {
+		return nil
+	}
+
+	switch selection.Unwrap().SelectedItem.Metadata.(string) {
+	case collectionArtFromGame:
+		art, listErr := utils.ListCollectionGameArt(collection)
+		if listErr != nil || len(art) == 0 {
+			utils.ShowTimedMessage(fmt.Sprintf("No games in %s have art!", collection.DisplayName), time.Second*2)
+			return nil
+		}
+
+		picked, pickErr := pickImage("Use Art From", art, true)
+		if pickErr != nil || picked.Path == "" {
+			return pickErr
+		}
+		err = utils.SetCollectionArt(collection, picked.Path)
+	case collectionArtGrid:
+		err = utils.SetCollectionArtGrid(collection)
+	case collectionArtFromImage:
+		imagePath, browseErr := browseImages(filepath.Dir(utils.GetRomDirectory()))
+		if browseErr != nil || imagePath == "" {
+			return browseErr
+		}
+		err = utils.SetCollectionArt(collection, imagePath)
+	case collectionArtRemove:
+		entry, deleteErr := utils.DeleteCollectionArt(collection)
+		if deleteErr != nil {
+			logger.Error("failed to remove collection icon", zap.Error(deleteErr))
+			utils.ShowTimedMessage(fmt.Sprintf("Unable to remove the icon for %s!", collection.DisplayName), time.Second*2)
+			return nil
+		}
+
+		utils.RecordUndo(fmt.Sprintf("Remove icon for %s", collection.DisplayName), utils.TrashUndoStep(entry))
+		utils.ShowTimedMessage(fmt.Sprintf("Removed the icon for %s!", collection.DisplayName), time.Second*2)
+		return nil
+	}
+
+	if err != nil {
+		logger.Error("failed to set collection icon", zap.Error(err))
+		utils.ShowTimedMessage(fmt.Sprintf("Unable to set the icon for %s!", collection.DisplayName), time.Second*2)
+		return nil
+	}
+
+	utils.ShowTimedMessage(fmt.Sprintf("Set the icon for %s!", collection.DisplayName), time.Second*2)
+	return nil
+}
+
+// browseImages walks the SD card from root for a PNG, returning an empty path when backed out of at the top.
+func browseImages(root string) (string, error) {
+	dirPath := root
+
+	for {
+		images, err := utils.ListImages(dirPath)
+		if err != nil {
+			return "", err
+		}
+
+		title := filepath.Base(dirPath)
+		if dirPath == root {
+			title = "SD Card"
+		}
+
+		picked, err := pickImage(title, images, false)
+		if err != nil {
+			return "", err
+		}
+
+		switch {
+		case picked.Path == "" && dirPath == root:
+			return "", nil
+		case picked.Path == "":
+			dirPath = filepath.Dir(dirPath)
+		case picked.IsDirectory:
+			dirPath = picked.Path
+		default:
+			return picked.Path, nil
+		}
+	}
+}
+
+// pickImage lists images, and folders when browsing, showing each image as it is highlighted. It returns what was
+// picked, or an empty item when backed out of.
+func pickImage(title string, images []shared.Item, showNames bool) (shared.Item, error) {
+	var imageEntries []gabagool.MenuItem
+	for _, image := range images {
+		text := image.Filename
+		if showNames {
+			text = image.DisplayName
+		}
+
+		entry := gabagool.MenuItem{
+			Text:     text,
+			Selected: false,
+			Focused:  false,
+			Metadata: image,
+		}
+
+		if image.IsDirectory {
+			entry.Text = "/" + entry.Text
+		} else {
+			entry.ImageFilename = image.Path
+		}
+
+		imageEntries = append(imageEntries, entry)
+	}
+
+	options := gabagool.DefaultListOptions(title, imageEntries)
+	options.SmallTitle = true
+	options.EnableImages = true
+	options.EmptyMessage = "No PNG images here"
+	options.FooterHelpItems = []gabagool.FooterHelpItem{
+		{ButtonName: "B", HelpText: "Back"},
+		{ButtonName: "A", HelpText: "Select"},
+	}
+
+	selection, err := gabagool.List(options)
+	if err != nil {
+		return shared.Item{}, err
+	}
+
+	if selection.IsSome() && selection.Unwrap().SelectedIndex != -1 {
+		return selection.Unwrap().SelectedItem.Metadata.(shared.Item), nil
+	}
+
+	return shared.Item{}, nil
+}
@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"fmt"
+	gaba "github.com/UncleJunVIP/gabagool/pkg/gabagool"
+	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
+	"go.uber.org/zap"
+	"nextui-game-manager/models"
+	"nextui-game-manager/state"
+	"nextui-game-manager/utils"
+	"qlova.tech/sum"
+	"time"
+)
+
+type CollectionCheckScreen struct {
+}
+
+func InitCollectionCheckScreen() CollectionCheckScreen {
+	return CollectionCheckScreen{}
+}
+
+func (cc CollectionCheckScreen) Name() sum.Int[models.ScreenName] {
+	return models.ScreenNames.CollectionCheck
+}
+
+type collectionRepair int
+
+const (
+	collectionRepairReplace collectionRepair = iota
+	collectionRepairRestore
+	collectionRepairRemove
+)
+
+// Lists the collection entries whose game is missing. Selecting one offers to point it at the game found in the
+// platform folder, to restore the game found in an archive, or to remove the entry
+func (cc CollectionCheckScreen) Draw() (value interface{}, exitCode int, e error) {
+	logger := common.GetLoggerInstance()
+
+	checkRes, _ := gaba.ProcessMessage("Checking collections...", gaba.ProcessMessageOptions{}, func() (interface{}, error) {
+		problems, err := utils.CheckCollections()
+		if err != nil {
+			logger.Error("Unable to check collections", zap.Error(err))
+			return nil, nil
+		}
+		return problems, nil
+	})
+
+	problems, ok := checkRes.Result.([]models.CollectionProblem)
+	if !ok {
+		utils.ShowTimedMessage("Unable to check collections!", time.Second*2)
+		return nil, 2, nil
+	}
+
+	if len(problems) == 0 {
+		return nil, 404, nil
+	}
+
+	var menuItems []gaba.MenuItem
+	for _, problem := range problems {
+		menuItems = append(menuItems, gaba.MenuItem{
+			Text:     fmt.Sprintf("[%s] %s", problem.Collection.DisplayName, problem.Entry.DisplayName),
+			Selected: false,
+			Focused:  false,
+			Metadata: problem,
+		})
+	}
+
+	options := gaba.DefaultListOptions(fmt.Sprintf("Broken Entries (%d)", len(problems)), menuItems)
+
+	selectedIndex, visibleStartIndex := state.GetCurrentMenuPosition()
+	options.SelectedIndex = selectedIndex
+	options.VisibleStartIndex = visibleStartIndex
+
+	options.SmallTitle = true
+	options.FooterHelpItems = []gaba.FooterHelpItem{
+		{ButtonName: "B", HelpText: "Back"},
+		{ButtonName: "A", HelpText: "Fix"},
+	}
+
+	selection, err := gaba.List(options)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	if !selection.IsSome() || selection.Unwrap().SelectedIndex == -1 {
+		return nil, 2, nil
+	}
+
+	state.UpdateCurrentMenuPosition(selection.Unwrap().SelectedIndex, selection.Unwrap().VisiblePosition)
+	problem := selection.Unwrap().SelectedItem.Metadata.(models.CollectionProblem)
+
+	var repairItems []gaba.MenuItem
+	if problem.Replacement.Path != "" {
+		repairItems = append(repairItems, gaba.MenuItem{
+			Text:     fmt.Sprintf("Repair: %s", problem.Replacement.Filename),
+			Metadata: collectionRepairReplace,
+		})
+	}
+
+	if problem.Archived.Path != "" {
+		repairItems = append(repairItems, gaba.MenuItem{
+			Text:     fmt.Sprintf("Restore from %s: %s", problem.Archive.DisplayName, problem.Archived.Filename),
+			Metadata: collectionRepairRestore,
+		})
+	}
+
+	repairItems = append(repairItems, gaba.MenuItem{
+		Text:     fmt.Sprintf("Remove from %s", problem.Collection.DisplayName),
+		Metadata: collectionRepairRemove,
+	})
+
+	repairOptions := gaba.DefaultListOptions(problem.Entry.DisplayName, repairItems)
+	repairOptions.SmallTitle = true
+	repairOptions.FooterHelpItems = []gaba.FooterHelpItem{
+		{ButtonName: "B", HelpText: "Back"},
+		{ButtonName: "A", HelpText: "Select"},
+	}
+
+	repairSelection, err := gaba.List(repairOptions)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	if !repairSelection.IsSome() || repairSelection.Unwrap().SelectedIndex == -1 {
+		return nil, 0, nil
+	}
+
+	undoSteps := []models.UndoStep{utils.CollectionUndoStep(problem.Collection)}
+
+	var message string
+	switch repairSelection.Unwrap().SelectedItem.Metadata.(collectionRepair) {
+	case collectionRepairReplace:
+		message = fmt.Sprintf("Repaired %s in %s", problem.Entry.DisplayName, problem.Collection.DisplayName)
+		err = utils.RepairCollectionEntry(problem, problem.Replacement)
+	case collectionRepairRestore:
+		message = fmt.Sprintf("Restored %s from archive %s", problem.Archived.DisplayName, problem.Archive.DisplayName)
+		err = utils.RestoreCollectionEntry(problem)
+		// Restoring is not undone elsewhere either, and undoing only the entry would break it again
+		undoSteps = nil
+	case collectionRepairRemove:
+		message = fmt.Sprintf("Removed %s from %s", problem.Entry.DisplayName, problem.Collection.DisplayName)
+		err = utils.RemoveCollectionEntry(problem)
+	}
+
+	if err != nil {
+		logger.Error("Unable to fix collection entry", zap.String("entry", problem.Entry.Path), zap.Error(err))
+		utils.ShowTimedMessage(fmt.Sprintf("Unable to fix %s!", problem.Entry.DisplayName), time.Second*2)
+		return nil, 1, nil
+	}
+
+	utils.RecordUndo(message, undoSteps...)
+	utils.ShowTimedMessage(message+"!", time.Second*2)
+	return problem, 0, nil
+}
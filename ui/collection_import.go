@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"fmt"
+	"github.com/UncleJunVIP/gabagool/pkg/gabagool"
+	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"go.uber.org/zap"
+	"nextui-game-manager/models"
+	"nextui-game-manager/utils"
+	"time"
+)
+
+// importCollection lets a collection export from the export directory be picked and imported, then lists the games
+// that could not be found on this SD card.
+func importCollection() error {
+	logger := common.GetLoggerInstance()
+
+	exports, err := utils.ListCollectionExports()
+	if err != nil || len(exports) == 0 {
+		utils.ShowTimedMessage(fmt.Sprintf("No collection exports found in\n%s", utils.GetExportDirectory()), time.Second*3)
+		return nil
+	}
+
+	var exportEntries []gabagool.MenuItem
+	for _, export := range exports {
+		exportEntries = append(exportEntries, gabagool.MenuItem{
+			Text:     export.DisplayName,
+			Selected: false,
+			Focused:  false,
+			Metadata: export,
+		})
+	}
+
+	options := gabagool.DefaultListOptions("Import Collection", exportEntries)
+	options.FooterHelpItems = []gabagool.FooterHelpItem{
+		{ButtonName: "B", HelpText: "Back"},
+		{ButtonName: "A", HelpText: "Import"},
+	}
+
+	selection, err := gabagool.List(options)
+	if err != nil {
+		return err
+	}
+
+	if !selection.IsSome() || selection.Unwrap().SelectedIndex == -1 {
+		return nil
+	}
+
+	export := selection.Unwrap().SelectedItem.Metadata.(shared.Item)
+
+	importRes, _ := gabagool.ProcessMessage(fmt.Sprintf("Importing %s...", export.DisplayName), gabagool.ProcessMessageOptions{}, func() (interface{}, error) {
+		result, err := utils.ImportCollection(export.Path)
+		if err != nil {
+			logger.Error("Unable to import collection", zap.String("export", export.Path), zap.Error(err))
+			return nil, nil
+		}
+		return result, nil
+	})
+
+	result, ok := importRes.Result.(models.CollectionImport)
+	if !ok {
+		utils.ShowTimedMessage("Unable to import collection!", time.Second*2)
+		return nil
+	}
+
+	found := result.ByPath + result.ByName + result.ByHash + result.ByFuzzy
+	total := found + len(result.Unresolved)
+
+	utils.ShowTimedMessage(fmt.Sprintf("Imported %d of %d games into\n%s!", found, total, result.Collection.DisplayName), time.Second*2)
+
+	if len(result.Unresolved) == 0 {
+		return nil
+	}
+
+	var unresolvedEntries []gabagool.MenuItem
+	for _, entry := range result.Unresolved {
+		unresolvedEntries = append(unresolvedEntries, gabagool.MenuItem{
+			Text:               fmt.Sprintf("[%s] %s", entry.Platform, entry.DisplayName),
+			Selected:           false,
+			Focused:            false,
+			Metadata:           entry,
+			NotMultiSelectable: true,
+		})
+	}
+
+	unresolvedOptions := gabagool.DefaultListOptions(fmt.Sprintf("Not Found (%d)", len(result.Unresolved)), unresolvedEntries)
+	unresolvedOptions.SmallTitle = true
+	unresolvedOptions.FooterHelpItems = []gabagool.FooterHelpItem{
+		{ButtonName: "B", HelpText: "Done"},
+	}
+
+	_, err = gabagool.List(unresolvedOptions)
+	return err
+}
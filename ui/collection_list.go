@@ -37,10 +37,11 @@ func (c CollectionListScreen) Draw() (collection interface{}, exitCode int, e er
 	var menuItems []gaba.MenuItem
 	for _, collection := range collectionList {
 		menuItems = append(menuItems, gaba.MenuItem{
-			Text:     collection.DisplayName,
-			Selected: false,
-			Focused:  false,
-			Metadata: collection,
+			Text:          collection.DisplayName,
+			Selected:      false,
+			Focused:       false,
+			Metadata:      collection,
+			ImageFilename: utils.CollectionArtPath(collection),
 		})
 	}
 
@@ -55,6 +56,10 @@ func (c CollectionListScreen) Draw() (collection interface{}, exitCode int, e er
 	options.VisibleStartIndex = visibleStartIndex
 
 	options.EnableAction = true
+
+	if state.GetAppState().Config.ShowArt {
+		options.EnableImages = true
+	}
 	options.FooterHelpItems = []gaba.FooterHelpItem{
 		{ButtonName: "B", HelpText: "Back"},
 		{ButtonName: "A", HelpText: "Select"},
@@ -12,6 +12,7 @@ import (
 	"nextui-game-manager/utils"
 	"qlova.tech/sum"
 	"slices"
+	"strings"
 )
 
 type CollectionManagement struct {
@@ -69,7 +70,12 @@ func (c CollectionManagement) Draw() (value interface{}, exitCode int, e error)
 		"• X: Open Options",
 	}
 
-	if len(menuItems) > 1 {
+	// A kept sort order is applied again on every save, so reordering by hand would not stick
+	sortKey := utils.GetCollectionSort(c.Collection)
+	if models.CollectionSortMap[sortKey] != models.CollectionSorts.Manual {
+		options.HelpText = append(options.HelpText,
+			fmt.Sprintf("• Sorted by %s, pick Manual in Sort Collection to reorder", models.CollectionSortLabels[sortKey]))
+	} else if len(menuItems) > 1 {
 		options.EnableReordering = true
 		options.ReorderKey = sdl.K_y
 		options.ReorderButton = gaba.ButtonY
@@ -114,9 +120,12 @@ func (c CollectionManagement) Draw() (value interface{}, exitCode int, e error)
 				}
 			}
 
+			undoStep := utils.CollectionUndoStep(c.Collection)
 			c.Collection.Games = games
 
-			utils.SaveCollection(c.Collection)
+			if utils.SaveCollection(c.Collection) == nil {
+				utils.RecordUndo(strings.TrimSuffix(message, "?"), undoStep)
+			}
 		}
 
 		return c.Collection, 0, nil
@@ -9,6 +9,7 @@ import (
 	"nextui-game-manager/state"
 	"nextui-game-manager/utils"
 	"qlova.tech/sum"
+	"time"
 )
 
 type CollectionOptionsScreen struct {
@@ -30,8 +31,15 @@ func (c CollectionOptionsScreen) Name() sum.Int[models.ScreenName] {
 func (c CollectionOptionsScreen) Draw() (screenReturn interface{}, exitCode int, e error) {
 	logger := common.GetLoggerInstance()
 
+	isSmart := utils.IsSmartCollection(c.Collection)
+
 	var actions []gabagool.MenuItem
 	for _, action := range models.CollectionActionKeys {
+		// Smart collections are sorted by their rules
+		if isSmart && models.ActionMap[action] == models.Actions.CollectionSort {
+			continue
+		}
+
 		actions = append(actions, gabagool.MenuItem{
 			Text:     action,
 			Selected: false,
@@ -60,7 +68,6 @@ func (c CollectionOptionsScreen) Draw() (screenReturn interface{}, exitCode int,
 
 	if selection.IsSome() && !selection.Unwrap().ActionTriggered && selection.Unwrap().SelectedIndex != -1 {
 		state.UpdateCurrentMenuPosition(selection.Unwrap().SelectedIndex, selection.Unwrap().VisiblePosition)
-		state.ClearCollectionMap()
 		action := models.ActionMap[selection.Unwrap().SelectedItem.Metadata.(string)]
 
 		switch action {
@@ -77,9 +84,104 @@ func (c CollectionOptionsScreen) Draw() (screenReturn interface{}, exitCode int,
 					return nil, -1, err
 				}
 
+				utils.RecordUndo(fmt.Sprintf("Rename collection %s to %s", c.Collection.DisplayName, updatedCol.DisplayName),
+					utils.RenameCollectionUndoStep(c.Collection, updatedCol))
+
 				return updatedCol, 4, nil
 			}
 
+		case models.Actions.CollectionSort:
+			if err := sortCollection(c.Collection); err != nil {
+				return nil, -1, err
+			}
+
+		case models.Actions.CollectionArt:
+			if err := setCollectionArt(c.Collection); err != nil {
+				return nil, -1, err
+			}
+
+		case models.Actions.CollectionDuplicate:
+			name, err := gabagool.Keyboard(fmt.Sprintf("%s Copy", c.Collection.DisplayName))
+			if err != nil {
+				return nil, -1, err
+			}
+
+			if name.IsSome() {
+				created, err := utils.DuplicateCollection(c.Collection, name.Unwrap())
+				return reportNewCollection(created, err)
+			}
+
+		case models.Actions.CollectionMerge:
+			target, err := pickOtherCollection(c.Collection, "Merge Into", true)
+			if err != nil {
+				return nil, -1, err
+			}
+
+			if target.CollectionFile != "" {
+				undoStep := utils.CollectionUndoStep(target)
+
+				if _, err := utils.MergeCollectionInto(c.Collection, target); err != nil {
+					logger.Error("failed to merge collection", zap.Error(err))
+					utils.ShowTimedMessage(fmt.Sprintf("Unable to merge into %s!", target.DisplayName), time.Second*2)
+				} else {
+					message := fmt.Sprintf("Merged %s into %s", c.Collection.DisplayName, target.DisplayName)
+					utils.RecordUndo(message, undoStep)
+					utils.ShowTimedMessage(message+"!", time.Second*2)
+				}
+			}
+
+		case models.Actions.CollectionIntersect, models.Actions.CollectionSubtract:
+			title, separator := "Intersect With", "&"
+			if action == models.Actions.CollectionSubtract {
+				title, separator = "Subtract", "-"
+			}
+
+			other, err := pickOtherCollection(c.Collection, title, false)
+			if err != nil {
+				return nil, -1, err
+			}
+
+			if other.CollectionFile == "" {
+				break
+			}
+
+			name, err := gabagool.Keyboard(fmt.Sprintf("%s %s %s", c.Collection.DisplayName, separator, other.DisplayName))
+			if err != nil {
+				return nil, -1, err
+			}
+
+			if name.IsSome() {
+				var created models.Collection
+				if action == models.Actions.CollectionSubtract {
+					created, err = utils.SubtractCollection(c.Collection, other, name.Unwrap())
+				} else {
+					created, err = utils.IntersectCollections(c.Collection, other, name.Unwrap())
+				}
+				return reportNewCollection(created, err)
+			}
+
+		case models.Actions.CollectionExport:
+			hashRes, _ := gabagool.ConfirmationMessage("Include file hashes?\n\nSlower, but finds games that were renamed on the other SD card.", []gabagool.FooterHelpItem{
+				{ButtonName: "B", HelpText: "No"},
+				{ButtonName: "A", HelpText: "Yes"},
+			}, gabagool.MessageOptions{})
+			includeHashes := hashRes.IsSome() && !hashRes.Unwrap().Cancelled
+
+			exportRes, _ := gabagool.ProcessMessage(fmt.Sprintf("Exporting %s...", c.Collection.DisplayName), gabagool.ProcessMessageOptions{}, func() (interface{}, error) {
+				exportPath, err := utils.ExportCollection(c.Collection, includeHashes)
+				if err != nil {
+					logger.Error("failed to export collection", zap.Error(err))
+					return "", nil
+				}
+				return exportPath, nil
+			})
+
+			if exportPath, _ := exportRes.Result.(string); exportPath != "" {
+				utils.ShowTimedMessage(fmt.Sprintf("Exported to\n%s", exportPath), time.Second*3)
+			} else {
+				utils.ShowTimedMessage("Unable to export collection!", time.Second*2)
+			}
+
 		case models.Actions.CollectionDelete:
 			res, _ := gabagool.ConfirmationMessage(fmt.Sprintf("Are you sure you want to delete the collection\n%s?", c.Collection.DisplayName), []gabagool.FooterHelpItem{
 				{ButtonName: "B", HelpText: "Cancel"},
@@ -90,7 +192,13 @@ func (c CollectionOptionsScreen) Draw() (screenReturn interface{}, exitCode int,
 			})
 
 			if res.IsSome() && !res.Unwrap().Cancelled {
-				utils.DeleteCollection(c.Collection)
+				entry, err := utils.DeleteCollection(c.Collection)
+				if err != nil {
+					logger.Error("failed to delete collection", zap.Error(err))
+					return nil, -1, err
+				}
+
+				utils.RecordUndo(fmt.Sprintf("Delete collection %s", c.Collection.DisplayName), utils.TrashUndoStep(entry))
 				return nil, 0, nil
 			}
 
@@ -101,3 +209,133 @@ func (c CollectionOptionsScreen) Draw() (screenReturn interface{}, exitCode int,
 
 	return c.Collection, 2, nil
 }
+
+// pickOtherCollection lists every collection but this one and returns the one picked, or an empty collection when
+// backed out of. Smart collections are left out when they are to be written to, since a refresh would undo it.
+func pickOtherCollection(collection models.Collection, title string, skipSmart bool) (models.Collection, error) {
+	collections, _, err := utils.GenerateCollectionList("", true)
+	if err != nil {
+		return models.Collection{}, err
+	}
+
+	var collectionEntries []gabagool.MenuItem
+	for _, other := range collections {
+		if other.CollectionFile == collection.CollectionFile || (skipSmart && utils.IsSmartCollection(other)) {
+			continue
+		}
+
+		collectionEntries = append(collectionEntries, gabagool.MenuItem{
+			Text:     other.DisplayName,
+			Selected: false,
+			Focused:  false,
+			Metadata: other,
+		})
+	}
+
+	if len(collectionEntries) == 0 {
+		utils.ShowTimedMessage("No other collections!", time.Second*2)
+		return models.Collection{}, nil
+	}
+
+	options := gabagool.DefaultListOptions(title, collectionEntries)
+	options.FooterHelpItems = []gabagool.FooterHelpItem{
+		{ButtonName: "B", HelpText: "Back"},
+		{ButtonName: "A", HelpText: "Select"},
+	}
+
+	selection, err := gabagool.List(options)
+	if err != nil {
+		return models.Collection{}, err
+	}
+
+	if selection.IsSome() && selection.Unwrap().SelectedIndex != -1 {
+		return selection.Unwrap().SelectedItem.Metadata.(models.Collection), nil
+	}
+
+	return models.Collection{}, nil
+}
+
+// reportNewCollection tells how creating a collection from this one went and heads back to the collections list
+// when it worked, so the new collection shows up.
+func reportNewCollection(created models.Collection, err error) (interface{}, int, error) {
+	if err != nil {
+		common.GetLoggerInstance().Error("failed to create collection", zap.Error(err))
+		utils.ShowTimedMessage(fmt.Sprintf("Unable to create collection!\n%s", err), time.Second*3)
+		return nil, 2, nil
+	}
+
+	utils.ShowTimedMessage(fmt.Sprintf("Created %s with %d games!", created.DisplayName, len(created.Games)), time.Second*2)
+	return nil, 0, nil
+}
+
+// sortCollection offers the sort orders, marking the one the collection is kept in, and asks whether to keep the
+// collection in the order picked or to sort it just this once.
+func sortCollection(collection models.Collection) error {
+	logger := common.GetLoggerInstance()
+
+	current := utils.GetCollectionSort(collection)
+
+	var sortEntries []gabagool.MenuItem
+	for _, sortKey := range models.CollectionSortKeys {
+		label := models.CollectionSortLabels[sortKey]
+		if sortKey == current {
+			label += " (Current)"
+		}
+
+		sortEntries = append(sortEntries, gabagool.MenuItem{
+			Text:     label,
+			Selected: false,
+			Focused:  false,
+			Metadata: sortKey,
+		})
+	}
+
+	options := gabagool.DefaultListOptions(fmt.Sprintf("Sort %s", collection.DisplayName), sortEntries)
+	options.SmallTitle = true
+	options.FooterHelpItems = []gabagool.FooterHelpItem{
+		{ButtonName: "B", HelpText: "Back"},
+		{ButtonName: "A", HelpText: "Sort"},
+	}
+
+	selection, err := gabagool.List(options)
+	if err != nil {
+		return err
+	}
+
+	if !selection.IsSome() || selection.Unwrap().SelectedIndex == -1 {
+		return nil
+	}
+
+	sortKey := selection.Unwrap().SelectedItem.Metadata.(string)
+	label := models.CollectionSortLabels[sortKey]
+
+	keep := false
+	if models.CollectionSortMap[sortKey] != models.CollectionSorts.Manual {
+		keepRes, _ := gabagool.ConfirmationMessage(fmt.Sprintf("Keep %s sorted by %s?\n\nGames added later will be sorted too.", collection.DisplayName, label), []gabagool.FooterHelpItem{
+			{ButtonName: "B", HelpText: "Just Once"},
+			{ButtonName: "A", HelpText: "Keep Sorted"},
+		}, gabagool.MessageOptions{})
+		keep = keepRes.IsSome() && !keepRes.Unwrap().Cancelled
+	}
+
+	undoStep := utils.CollectionUndoStep(collection)
+
+	if _, err := utils.SortCollection(collection, sortKey, keep); err != nil {
+		logger.Error("failed to sort collection", zap.Error(err))
+		utils.ShowTimedMessage(fmt.Sprintf("Unable to sort %s!", collection.DisplayName), time.Second*2)
+		return nil
+	}
+
+	if models.CollectionSortMap[sortKey] == models.CollectionSorts.Manual {
+		utils.ShowTimedMessage(fmt.Sprintf("%s can be reordered by hand!", collection.DisplayName), time.Second*2)
+		return nil
+	}
+
+	message := fmt.Sprintf("Sorted %s by %s", collection.DisplayName, label)
+	// A kept order would be applied again over the undone one, picking Manual is how that is undone
+	if !keep {
+		utils.RecordUndo(message, undoStep)
+	}
+	utils.ShowTimedMessage(message+"!", time.Second*2)
+	return nil
+}
@@ -5,7 +5,6 @@ import (
 	gaba "github.com/UncleJunVIP/gabagool/pkg/gabagool"
 	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
 	"nextui-game-manager/models"
-	"nextui-game-manager/state"
 	"nextui-game-manager/utils"
 	"path/filepath"
 	"qlova.tech/sum"
@@ -42,7 +41,6 @@ func (c CreateCollectionScreen) Draw() (collection interface{}, exitCode int, e
 	}
 
 	if res.IsSome() {
-		state.ClearCollectionMap()
 		newCollectionName := strings.ReplaceAll(res.Unwrap(), " ", "")
 
 		if newCollectionName == "" {
@@ -3,7 +3,6 @@ package ui
 import (
 	"github.com/UncleJunVIP/gabagool/pkg/gabagool"
 	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
-	"github.com/UncleJunVIP/nextui-pak-shared-functions/filebrowser"
 	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
 	"go.uber.org/zap"
 	"nextui-game-manager/models"
@@ -14,6 +13,14 @@ import (
 	"strings"
 )
 
+// GameListSortExitCode is returned with the sort order picked from the list options.
+const GameListSortExitCode = 7
+
+const (
+	gameListSearch = "Search"
+	gameListSort   = "Sort By"
+)
+
 type GameList struct {
 	RomDirectory         shared.RomDirectory
 	SearchFilter         string
@@ -40,9 +47,26 @@ func (gl GameList) Draw() (item interface{}, exitCode int, e error) {
 	logger := common.GetLoggerInstance()
 	title := gl.RomDirectory.DisplayName
 
-	fb := filebrowser.NewFileBrowser(logger)
+	var roms shared.Items
+	var err error
+
+	appState := state.GetAppState()
+	sortKey := utils.GetGameListSort(appState.Config, gl.RomDirectory.Path)
+
+	if gl.SearchFilter != "" {
+		title = "[Search: \"" + gl.SearchFilter + "\"]"
+
+		query, parseErr := utils.ParseSearchQuery(gl.SearchFilter)
+		if parseErr != nil {
+			logger.Info("Unable to parse search query", zap.String("query", gl.SearchFilter), zap.Error(parseErr))
+			return nil, 404, nil
+		}
+
+		roms, err = utils.SearchLibraryDirectory(gl.RomDirectory.Path, query, searchPlayMap(query))
+	} else {
+		roms, err = utils.ListSortedLibraryDirectory(gl.RomDirectory.Path, sortKey, gameListPlayMap(sortKey))
+	}
 
-	err := fb.CWD(gl.RomDirectory.Path, false)
 	if err != nil {
 		logger.Info("Unable to fetch ROM directory! Continuing without them",
 			zap.String("rom_directory", gl.RomDirectory.Path),
@@ -50,14 +74,6 @@ func (gl GameList) Draw() (item interface{}, exitCode int, e error) {
 		return shared.Item{}, 1, err
 	}
 
-	var roms shared.Items
-	roms = fb.Items
-
-	if gl.SearchFilter != "" {
-		title = "[Search: \"" + gl.SearchFilter + "\"]"
-		roms = utils.FilterList(roms, gl.SearchFilter)
-	}
-
 	var directoryEntries []gabagool.MenuItem
 	var itemEntries []gabagool.MenuItem
 
@@ -104,11 +120,14 @@ func (gl GameList) Draw() (item interface{}, exitCode int, e error) {
 	options.EnableMultiSelect = true
 	options.FooterHelpItems = []gabagool.FooterHelpItem{
 		{ButtonName: "B", HelpText: "Back"},
-		{ButtonName: "X", HelpText: "Search"},
+		{ButtonName: "X", HelpText: "Options"},
 		{ButtonName: "Menu", HelpText: "Help"},
 	}
 
-	appState := state.GetAppState()
+	// Search results are ordered by how well they match, so only a plain listing can be sorted
+	if gl.SearchFilter != "" {
+		options.FooterHelpItems[1].HelpText = "Search"
+	}
 
 	if appState.Config.ShowArt {
 		options.EnableImages = true
@@ -117,7 +136,7 @@ func (gl GameList) Draw() (item interface{}, exitCode int, e error) {
 	options.EnableHelp = true
 	options.HelpTitle = "ROMs List Controls"
 	options.HelpText = []string{
-		"• X: Open Options",
+		"• X: Search or Change the Sort Order",
 		"• Select: Toggle Multi-Select",
 		"• Start: Confirm Multi-Selection",
 	}
@@ -129,7 +148,10 @@ func (gl GameList) Draw() (item interface{}, exitCode int, e error) {
 
 	if selection.IsSome() && selection.Unwrap().ActionTriggered {
 		state.UpdateCurrentMenuPosition(selection.Unwrap().SelectedIndex, selection.Unwrap().VisiblePosition)
-		return nil, 4, nil
+		if gl.SearchFilter != "" {
+			return nil, 4, nil
+		}
+		return gl.listOptions(sortKey)
 	} else if selection.IsSome() && !selection.Unwrap().ActionTriggered && selection.Unwrap().SelectedIndex != -1 {
 		state.UpdateCurrentMenuPosition(selection.Unwrap().SelectedIndex, selection.Unwrap().VisiblePosition)
 		var selectedItems shared.Items
@@ -143,3 +165,46 @@ func (gl GameList) Draw() (item interface{}, exitCode int, e error) {
 
 	return nil, 2, nil
 }
+
+// listOptions offers searching the list and changing its sort order. The sort order picked is returned with
+// GameListSortExitCode, or the current one when nothing was picked.
+func (gl GameList) listOptions(sortKey string) (interface{}, int, error) {
+	choice, err := pickOption("ROMs List Options", []string{gameListSearch, gameListSort + ": " + models.GameListSortLabels[sortKey]})
+	if err != nil {
+		return nil, -1, err
+	}
+
+	if choice == gameListSearch {
+		return nil, 4, nil
+	}
+
+	if choice == "" {
+		return sortKey, GameListSortExitCode, nil
+	}
+
+	var labels []string
+	for _, key := range models.GameListSortKeys {
+		labels = append(labels, models.GameListSortLabels[key])
+	}
+
+	label, err := pickOption(gameListSort, labels)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	for _, key := range models.GameListSortKeys {
+		if models.GameListSortLabels[key] == label {
+			return key, GameListSortExitCode, nil
+		}
+	}
+	return sortKey, GameListSortExitCode, nil
+}
+
+func gameListPlayMap(sortKey string) map[string][]models.PlayHistoryAggregate {
+	if !utils.GameListSortUsesPlayTime(sortKey) {
+		return nil
+	}
+
+	gamePlayMap, _, _ := state.GetPlayMaps()
+	return gamePlayMap
+}
@@ -3,7 +3,6 @@ package ui
 import (
 	"fmt"
 	"github.com/UncleJunVIP/gabagool/pkg/gabagool"
-	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
 	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
 	"github.com/veandco/go-sdl2/sdl"
 	"nextui-game-manager/models"
@@ -164,12 +163,13 @@ func (gas GlobalActionsScreen) Draw() (value interface{}, exitCode int, e error)
 				utils.ShowTimedMessage(message, time.Second*2)
 			}
 		} else if selection.Unwrap().SelectedItem.Metadata == models.Actions.GlobalClearRecents {
-			confirmClear := utils.ConfirmAction("Are you sure you want to clear your recently played list?\n\nThis cannot be undone!")
+			confirmClear := utils.ConfirmAction("Are you sure you want to clear your recently played list?\n\nIt will be kept in the Trash.")
 
 			if confirmClear {
 				deletedRes, _ := gabagool.ProcessMessage("Clearing Recently Played List.", gabagool.ProcessMessageOptions{}, func() (interface{}, error) {
 					time.Sleep(1500 * time.Millisecond)
-					return common.DeleteFile(utils.RecentlyPlayedFile), nil
+					_, err := utils.MoveToTrash(models.TrashKindRecentlyPlayed, "Recently Played List", utils.GetRecentlyPlayedFile())
+					return err == nil, nil
 				})
 
 				if deletedRes.Result.(bool) {
@@ -178,6 +178,17 @@ func (gas GlobalActionsScreen) Draw() (value interface{}, exitCode int, e error)
 					utils.ShowTimedMessage("Failed to confirmClear recently played list!", time.Millisecond*1500)
 				}
 			}
+		} else if selection.Unwrap().SelectedItem.Metadata == models.Actions.GlobalRefreshSmartCollections {
+			refreshRes, _ := gabagool.ProcessMessage("Refreshing Smart Collections.", gabagool.ProcessMessageOptions{}, func() (interface{}, error) {
+				collections, err := utils.RefreshSmartCollections()
+				return smartCollectionsRefreshMessage(collections, err), nil
+			})
+
+			utils.ShowTimedMessage(refreshRes.Result.(string), time.Second*2)
+		} else if selection.Unwrap().SelectedItem.Metadata == models.Actions.GlobalImportCollection {
+			if err := importCollection(); err != nil {
+				return nil, -1, err
+			}
 		}
 
 		return nil, 0, nil
@@ -185,3 +196,24 @@ func (gas GlobalActionsScreen) Draw() (value interface{}, exitCode int, e error)
 
 	return nil, 2, nil
 }
+
+func smartCollectionsRefreshMessage(collections []models.Collection, err error) string {
+	if err != nil && len(collections) == 0 {
+		return "Unable to refresh smart collections!\nCheck smart_collections.yml."
+	}
+
+	if len(collections) == 0 {
+		return "No smart collections defined!\nAdd them to smart_collections.yml."
+	}
+
+	label := "Collections"
+	if len(collections) == 1 {
+		label = "Collection"
+	}
+
+	message := fmt.Sprintf("Refreshed %d Smart %s!", len(collections), label)
+	if err != nil {
+		message += "\nSome could not be refreshed, see the log."
+	}
+	return message
+}
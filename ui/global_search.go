@@ -0,0 +1,178 @@
+package ui
+
+import (
+	"fmt"
+	gaba "github.com/UncleJunVIP/gabagool/pkg/gabagool"
+	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"go.uber.org/zap"
+	"nextui-game-manager/models"
+	"nextui-game-manager/state"
+	"nextui-game-manager/utils"
+	"path/filepath"
+	"qlova.tech/sum"
+	"strings"
+	"time"
+)
+
+type GlobalSearchScreen struct {
+	Query string
+}
+
+func InitGlobalSearchScreen(query string) GlobalSearchScreen {
+	return GlobalSearchScreen{
+		Query: query,
+	}
+}
+
+func (gs GlobalSearchScreen) Name() sum.Int[models.ScreenName] {
+	return models.ScreenNames.GlobalSearch
+}
+
+// Draw lists every ROM and collection matching the query, grouped by platform. Picking a single game returns it as a
+// models.IndexedRom, a multi-selection returns shared.Items and picking a collection returns the models.Collection.
+// Archived games are restored from here, since they have no actions of their own.
+func (gs GlobalSearchScreen) Draw() (value interface{}, exitCode int, e error) {
+	logger := common.GetLoggerInstance()
+
+	query, err := utils.ParseSearchQuery(gs.Query)
+	if err != nil {
+		utils.ShowTimedMessage(fmt.Sprintf("Invalid search!\n%s", err.Error()), time.Second*3)
+		return nil, 1, nil
+	}
+
+	roms, collections, err := utils.SearchLibrary(query, searchPlayMap(query))
+	if err != nil {
+		logger.Error("Unable to search library", zap.String("query", gs.Query), zap.Error(err))
+		utils.ShowTimedMessage("Unable to search your library!", time.Second*2)
+		return nil, 1, nil
+	}
+
+	if len(roms) == 0 && len(collections) == 0 {
+		return nil, 404, nil
+	}
+
+	var menuItems []gaba.MenuItem
+
+	for _, rom := range roms {
+		text := fmt.Sprintf("[%s] %s", globalSearchPlatformLabel(rom.Platform), rom.Item.DisplayName)
+		if rom.Archive != "" {
+			text = fmt.Sprintf("[%s] (%s) %s", globalSearchPlatformLabel(rom.Platform),
+				string(utils.CleanArchiveName(rom.Archive)[0]), rom.Item.DisplayName)
+		}
+
+		menuItems = append(menuItems, gaba.MenuItem{
+			Text:               text,
+			Selected:           false,
+			Focused:            false,
+			Metadata:           rom,
+			ImageFilename:      filepath.Join(filepath.Dir(rom.Item.Path), ".media", strings.TrimSuffix(rom.Item.Filename, filepath.Ext(rom.Item.Filename))+".png"),
+			NotMultiSelectable: rom.Archive != "",
+		})
+	}
+
+	for _, collection := range collections {
+		menuItems = append(menuItems, gaba.MenuItem{
+			Text:               "[Collection] " + collection.DisplayName,
+			Selected:           false,
+			Focused:            false,
+			Metadata:           collection,
+			NotMultiSelectable: true,
+		})
+	}
+
+	options := gaba.DefaultListOptions(fmt.Sprintf("[Search: \"%s\"]", gs.Query), menuItems)
+
+	selectedIndex, visibleStartIndex := state.GetCurrentMenuPosition()
+	options.SelectedIndex = selectedIndex
+	options.VisibleStartIndex = visibleStartIndex
+
+	options.SmallTitle = true
+	options.EnableAction = true
+	options.EnableMultiSelect = true
+	options.FooterHelpItems = []gaba.FooterHelpItem{
+		{ButtonName: "B", HelpText: "Back"},
+		{ButtonName: "X", HelpText: "Search"},
+		{ButtonName: "Menu", HelpText: "Help"},
+	}
+
+	if state.GetAppState().Config.ShowArt {
+		options.EnableImages = true
+	}
+
+	options.EnableHelp = true
+	options.HelpTitle = "Search Results Controls"
+	options.HelpText = []string{
+		"• X: New Search",
+		"• Select: Toggle Multi-Select",
+		"• Start: Confirm Multi-Selection",
+		"• Archived games are marked with their archive and restored with A",
+	}
+
+	selection, err := gaba.List(options)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	if selection.IsSome() && selection.Unwrap().ActionTriggered {
+		state.UpdateCurrentMenuPosition(selection.Unwrap().SelectedIndex, selection.Unwrap().VisiblePosition)
+		return nil, 4, nil
+	} else if selection.IsSome() && !selection.Unwrap().ActionTriggered && selection.Unwrap().SelectedIndex != -1 {
+		state.UpdateCurrentMenuPosition(selection.Unwrap().SelectedIndex, selection.Unwrap().VisiblePosition)
+		rawSelection := selection.Unwrap().SelectedItems
+
+		if len(rawSelection) > 1 {
+			var games shared.Items
+			for _, item := range rawSelection {
+				if rom, ok := item.Metadata.(models.IndexedRom); ok && rom.Archive == "" {
+					games = append(games, rom.Item)
+				}
+			}
+			return games, 0, nil
+		}
+
+		switch selected := rawSelection[0].Metadata.(type) {
+		case models.Collection:
+			return selected, 0, nil
+		case models.IndexedRom:
+			if selected.Archive != "" {
+				restoreSearchResult(selected)
+				return nil, 0, nil
+			}
+			return selected, 0, nil
+		}
+	}
+
+	return nil, 2, nil
+}
+
+// restoreSearchResult restores an archived game picked from the results. A failure is only shown, so the results
+// stay on screen either way.
+func restoreSearchResult(rom models.IndexedRom) {
+	logger := common.GetLoggerInstance()
+
+	archive := shared.RomDirectory{
+		DisplayName: rom.Archive,
+		Path:        utils.GetArchiveRoot(rom.Archive),
+	}
+
+	if !utils.ConfirmAction(fmt.Sprintf("Restore %s from archive %s?", rom.Item.DisplayName, archive.DisplayName)) {
+		return
+	}
+
+	romDirectory := utils.RomDirectoryForItem(rom.Item, shared.RomDirectory{})
+	if err := utils.RestoreRom(rom.Item, romDirectory, archive); err != nil {
+		logger.Error("Unable to restore archived game", zap.String("game", rom.Item.Path), zap.Error(err))
+		utils.ShowTimedMessage(fmt.Sprintf("Unable to restore %s!", rom.Item.DisplayName), time.Second*2)
+		return
+	}
+
+	utils.ShowTimedMessage(fmt.Sprintf("Restored %s from archive %s!", rom.Item.DisplayName, archive.DisplayName), time.Second*2)
+}
+
+func globalSearchPlatformLabel(platform shared.RomDirectory) string {
+	if platform.Tag != "" {
+		return strings.Trim(platform.Tag, "()")
+	}
+	return platform.DisplayName
+}
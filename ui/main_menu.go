@@ -20,6 +20,7 @@ const (
 	settingsExitCode       = 4
 	selectExitCode         = 0
 	ToolsExitCode          = 5
+	SearchExitCode         = 6
 	quitExitCode           = 2
 	errorExitCode          = -1
 )
@@ -48,6 +49,13 @@ func (m MainMenu) Draw() (interface{}, int, error) {
 func buildMenuItems(logger *zap.Logger) ([]gaba.MenuItem, error) {
 	var menuItems []gaba.MenuItem
 
+	menuItems = append(menuItems, gaba.MenuItem{
+		Text:     "Search",
+		Selected: false,
+		Focused:  false,
+		Metadata: "Search",
+	})
+
 	if collectionsItem := buildCollectionsMenuItem(logger); collectionsItem != nil {
 		menuItems = append(menuItems, *collectionsItem)
 	}
@@ -56,7 +64,7 @@ func buildMenuItems(logger *zap.Logger) ([]gaba.MenuItem, error) {
 		menuItems = append(menuItems, *archivesItem)
 	}
 
-	romItems, err := buildRomDirectoryMenuItems(logger)
+	romItems, err := buildRomDirectoryMenuItems()
 	if err != nil {
 		return nil, err
 	}
@@ -130,27 +138,22 @@ func createArchivesRomDirectory() shared.RomDirectory {
 	}
 }
 
-func buildRomDirectoryMenuItems(logger *zap.Logger) ([]gaba.MenuItem, error) {
-	fb := filebrowser.NewFileBrowser(logger)
-
-	if err := fb.CWD(utils.GetRomDirectory(), state.GetAppState().Config.HideEmpty); err != nil {
+func buildRomDirectoryMenuItems() ([]gaba.MenuItem, error) {
+	romDirectories, err := utils.ListLibraryPlatforms(state.GetAppState().Config.HideEmpty)
+	if err != nil {
 		showRomDirectoryError()
 		common.LogStandardFatal("Error fetching ROM directories", err)
 		return nil, err
 	}
 
 	var menuItems []gaba.MenuItem
-	for _, item := range fb.Items {
-		if item.IsDirectory {
-
-			if item.Tag == "(PORTS)" {
-				continue
-			}
-
-			romDirectory := utils.CreateRomDirectoryFromItem(item)
-			menuItem := createMenuItemFromRomDirectory(romDirectory)
-			menuItems = append(menuItems, menuItem)
+	for _, romDirectory := range romDirectories {
+		if romDirectory.Tag == "(PORTS)" {
+			continue
 		}
+
+		menuItem := createMenuItemFromRomDirectory(romDirectory)
+		menuItems = append(menuItems, menuItem)
 	}
 
 	return menuItems, nil
@@ -188,6 +191,10 @@ func handleMenuSelection(menuItems []gaba.MenuItem) (interface{}, int, error) {
 			return nil, ToolsExitCode, nil
 		}
 
+		if selection.Unwrap().SelectedItem.Metadata == "Search" {
+			return nil, SearchExitCode, nil
+		}
+
 		return selection.Unwrap().SelectedItem.Metadata.(shared.RomDirectory), selectExitCode, nil
 	}
 
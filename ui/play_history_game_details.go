@@ -48,19 +48,6 @@ func InitPlayHistoryGameDetailsScreenFromActions(game shared.Item, romDirectory
 	}
 }
 
-func InitPlayHistoryGameDetailsScreenFromSelf(console string, searchFilter string, gameAggregate models.PlayHistoryAggregate, game shared.Item, 
-	romDirectory shared.RomDirectory, previousRomDirectory shared.RomDirectory, playHistoryOrigin bool) PlayHistoryGameDetailsScreen {
-	return PlayHistoryGameDetailsScreen{
-		Console:				console,
-		SearchFilter: 			searchFilter,
-		GameAggregate: 			gameAggregate,
-		Game:      				game,
-		RomDirectory: 			romDirectory,
-		PreviousRomDirectory:	previousRomDirectory,
-		PlayHistoryOrigin: 		playHistoryOrigin,
-	}
-}
-
 func (ptgds PlayHistoryGameDetailsScreen) Name() sum.Int[models.ScreenName] {
 	return models.ScreenNames.PlayHistoryGameDetails
 }
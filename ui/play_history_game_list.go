@@ -35,7 +35,7 @@ func (ptgls PlayHistoryGamesListScreen) Draw() (item interface{}, exitCode int,
 
 	if ptgls.SearchFilter != "" {
 		title = "[Search: \"" + ptgls.SearchFilter + "\"]"
-		gamesList = utils.FilterPlayList(gamesList, ptgls.SearchFilter)
+		gamesList = utils.FuzzyFilterPlayList(gamesList, ptgls.SearchFilter)
 	}
 
 	var menuItems []gaba.MenuItem
@@ -0,0 +1,249 @@
+package ui
+
+import (
+	"fmt"
+	gaba "github.com/UncleJunVIP/gabagool/pkg/gabagool"
+	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
+	"github.com/veandco/go-sdl2/sdl"
+	"go.uber.org/zap"
+	"nextui-game-manager/models"
+	"nextui-game-manager/state"
+	"nextui-game-manager/utils"
+	"qlova.tech/sum"
+	"slices"
+	"time"
+)
+
+const (
+	recentlyPlayedPin     = "Pin to Top"
+	recentlyPlayedUnpin   = "Unpin"
+	recentlyPlayedRemove  = "Remove"
+	recentlyPlayedPrune   = "Prune Missing Games"
+	recentlyPlayedRebuild = "Rebuild from Play History"
+)
+
+type RecentlyPlayedScreen struct {
+}
+
+func InitRecentlyPlayedScreen() RecentlyPlayedScreen {
+	return RecentlyPlayedScreen{}
+}
+
+func (rp RecentlyPlayedScreen) Name() sum.Int[models.ScreenName] {
+	return models.ScreenNames.RecentlyPlayed
+}
+
+// Lists NextUI's recently played games. Selecting one offers to pin or remove it, X offers to prune missing games or
+// rebuild the list from the game tracker, and Y reorders the list, which is saved on the way out
+func (rp RecentlyPlayedScreen) Draw() (value interface{}, exitCode int, e error) {
+	logger := common.GetLoggerInstance()
+
+	entries, err := utils.ReadRecentlyPlayed()
+	if err != nil {
+		logger.Error("Unable to read recently played list", zap.Error(err))
+		utils.ShowTimedMessage("Unable to read the recently played list!", time.Second*2)
+		return nil, 2, nil
+	}
+
+	showArt := state.GetAppState().Config.ShowArt
+
+	var menuItems []gaba.MenuItem
+	for _, entry := range entries {
+		text := utils.RecentlyPlayedName(entry)
+		if entry.Pinned {
+			text = "[Pinned] " + text
+		}
+
+		item := gaba.MenuItem{
+			Text:     text,
+			Selected: false,
+			Focused:  false,
+			Metadata: entry,
+		}
+		if showArt {
+			item.ImageFilename = utils.RecentlyPlayedArtPath(entry)
+		}
+
+		menuItems = append(menuItems, item)
+	}
+
+	options := gaba.DefaultListOptions("Recently Played", menuItems)
+
+	selectedIndex, visibleStartIndex := state.GetCurrentMenuPosition()
+	options.SelectedIndex = selectedIndex
+	options.VisibleStartIndex = visibleStartIndex
+
+	options.EnableAction = true
+	options.EnableImages = showArt
+	options.EnableHelp = true
+	options.HelpTitle = "Recently Played Controls"
+	options.EmptyMessage = "Nothing played recently.\nPress X to rebuild from play history."
+
+	options.HelpText = []string{
+		"• A: Pin or Remove",
+		"• X: Prune or Rebuild",
+	}
+
+	if len(menuItems) > 1 {
+		options.EnableReordering = true
+		options.ReorderKey = sdl.K_y
+		options.ReorderButton = gaba.ButtonY
+		options.HelpText = append(options.HelpText, "• Y: Toggle Reordering Mode")
+		options.HelpText = append(options.HelpText, "• ↕: Move Selection")
+		options.HelpText = append(options.HelpText, "• Pinned games stay on top")
+	}
+
+	options.FooterHelpItems = []gaba.FooterHelpItem{
+		{ButtonName: "B", HelpText: "Back"},
+		{ButtonName: "X", HelpText: "Options"},
+		{ButtonName: "A", HelpText: "Edit"},
+	}
+
+	selection, err := gaba.List(options)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	if !selection.IsSome() {
+		return nil, 2, nil
+	}
+
+	selected := selection.Unwrap()
+
+	if selected.ActionTriggered {
+		state.UpdateCurrentMenuPosition(selected.SelectedIndex, selected.VisiblePosition)
+		return rp.listOptions()
+	}
+
+	if selected.SelectedIndex == -1 {
+		var reordered []models.RecentlyPlayedEntry
+		for _, item := range selected.Items {
+			reordered = append(reordered, item.Metadata.(models.RecentlyPlayedEntry))
+		}
+
+		if !slices.Equal(reordered, entries) {
+			undoStep := utils.RecentlyPlayedUndoStep()
+			if err := utils.SaveRecentlyPlayed(reordered); err != nil {
+				logger.Error("Unable to save recently played list", zap.Error(err))
+				utils.ShowTimedMessage("Unable to save the recently played list!", time.Second*2)
+			} else {
+				utils.RecordUndo("Reorder Recently Played", undoStep)
+			}
+		}
+
+		return nil, 2, nil
+	}
+
+	state.UpdateCurrentMenuPosition(selected.SelectedIndex, selected.VisiblePosition)
+	return rp.entryOptions(entries, selected.SelectedIndex)
+}
+
+func (rp RecentlyPlayedScreen) entryOptions(entries []models.RecentlyPlayedEntry, index int) (interface{}, int, error) {
+	logger := common.GetLoggerInstance()
+	entry := entries[index]
+	name := utils.RecentlyPlayedName(entry)
+
+	pinOption := recentlyPlayedPin
+	if entry.Pinned {
+		pinOption = recentlyPlayedUnpin
+	}
+
+	choice, err := pickOption(name, []string{pinOption, recentlyPlayedRemove})
+	if err != nil || choice == "" {
+		return nil, 0, err
+	}
+
+	undoStep := utils.RecentlyPlayedUndoStep()
+	entries = slices.Clone(entries)
+
+	var message string
+	switch choice {
+	case recentlyPlayedPin, recentlyPlayedUnpin:
+		entries[index].Pinned = !entry.Pinned
+		message = fmt.Sprintf("Pinned %s", name)
+		if entry.Pinned {
+			message = fmt.Sprintf("Unpinned %s", name)
+		}
+	case recentlyPlayedRemove:
+		entries = slices.Delete(entries, index, index+1)
+		message = fmt.Sprintf("Removed %s from Recently Played", name)
+	}
+
+	if err := utils.SaveRecentlyPlayed(entries); err != nil {
+		logger.Error("Unable to save recently played list", zap.Error(err))
+		utils.ShowTimedMessage("Unable to save the recently played list!", time.Second*2)
+		return nil, 1, nil
+	}
+
+	utils.RecordUndo(message, undoStep)
+	return entry, 0, nil
+}
+
+func (rp RecentlyPlayedScreen) listOptions() (interface{}, int, error) {
+	logger := common.GetLoggerInstance()
+
+	choice, err := pickOption("Recently Played Options", []string{recentlyPlayedPrune, recentlyPlayedRebuild})
+	if err != nil || choice == "" {
+		return nil, 0, err
+	}
+
+	undoStep := utils.RecentlyPlayedUndoStep()
+
+	var count int
+	var message string
+	switch choice {
+	case recentlyPlayedPrune:
+		count, err = utils.PruneRecentlyPlayed()
+		message = fmt.Sprintf("Pruned %d missing games from Recently Played", count)
+		if err == nil && count == 0 {
+			utils.ShowTimedMessage("No missing games to prune!", time.Second*2)
+			return nil, 0, nil
+		}
+	case recentlyPlayedRebuild:
+		if !utils.ConfirmBulkAction("Replace Recently Played with your latest play history?") {
+			return nil, 0, nil
+		}
+		count, err = utils.RebuildRecentlyPlayed()
+		message = fmt.Sprintf("Rebuilt Recently Played with %d games", count)
+	}
+
+	if err != nil {
+		logger.Error("Unable to update recently played list", zap.String("option", choice), zap.Error(err))
+		utils.ShowTimedMessage("Unable to update the recently played list!", time.Second*2)
+		return nil, 1, nil
+	}
+
+	utils.RecordUndo(message, undoStep)
+	utils.ShowTimedMessage(message+"!", time.Second*2)
+	return nil, 0, nil
+}
+
+// pickOption lists options by name and returns the one picked, or an empty string when backed out of.
+func pickOption(title string, choices []string) (string, error) {
+	var menuItems []gaba.MenuItem
+	for _, choice := range choices {
+		menuItems = append(menuItems, gaba.MenuItem{
+			Text:     choice,
+			Selected: false,
+			Focused:  false,
+			Metadata: choice,
+		})
+	}
+
+	options := gaba.DefaultListOptions(title, menuItems)
+	options.SmallTitle = true
+	options.FooterHelpItems = []gaba.FooterHelpItem{
+		{ButtonName: "B", HelpText: "Back"},
+		{ButtonName: "A", HelpText: "Select"},
+	}
+
+	selection, err := gaba.List(options)
+	if err != nil {
+		return "", err
+	}
+
+	if !selection.IsSome() || selection.Unwrap().SelectedIndex == -1 {
+		return "", nil
+	}
+	return selection.Unwrap().SelectedItem.Metadata.(string), nil
+}
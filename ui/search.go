@@ -4,16 +4,26 @@ import (
 	"github.com/UncleJunVIP/gabagool/pkg/gabagool"
 	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
 	"nextui-game-manager/models"
+	"nextui-game-manager/state"
+	"nextui-game-manager/utils"
 	"qlova.tech/sum"
 )
 
+// Search asks for a search term. With an empty RomDirectory the term searches the whole library instead of one list.
 type Search struct {
 	RomDirectory shared.RomDirectory
+	Query        string
 }
 
 func InitSearch(romDirectory shared.RomDirectory) Search {
+	return InitSearchWithQuery(romDirectory, "")
+}
+
+// InitSearchWithQuery opens the keyboard with a query already typed in, so a query that failed to parse can be fixed.
+func InitSearchWithQuery(romDirectory shared.RomDirectory, query string) Search {
 	return Search{
 		RomDirectory: romDirectory,
+		Query:        query,
 	}
 }
 
@@ -22,7 +32,7 @@ func (s Search) Name() sum.Int[models.ScreenName] {
 }
 
 func (s Search) Draw() (value interface{}, exitCode int, e error) {
-	query, err := gabagool.Keyboard("")
+	query, err := gabagool.Keyboard(s.Query)
 	if err != nil {
 		return nil, -1, err
 	}
@@ -33,3 +43,13 @@ func (s Search) Draw() (value interface{}, exitCode int, e error) {
 
 	return nil, 2, nil
 }
+
+// searchPlayMap loads the game tracker aggregates only for queries that filter on play time.
+func searchPlayMap(query utils.SearchQuery) map[string][]models.PlayHistoryAggregate {
+	if !query.UsesPlayTime() {
+		return nil
+	}
+
+	gamePlayMap, _, _ := state.GetPlayMaps()
+	return gamePlayMap
+}
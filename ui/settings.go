@@ -166,6 +166,47 @@ func (s SettingsScreen) Draw() (settings interface{}, exitCode int, e error) {
 				}
 			}(),
 		},
+		{
+			Item: gabagool.MenuItem{Text: "Compress Archived Games"},
+			Options: []gabagool.Option{
+				{DisplayName: "True", Value: true},
+				{DisplayName: "False", Value: false},
+			},
+			SelectedOption: func() int {
+				switch appState.Config.CompressArchives {
+				case true:
+					return 0
+				case false:
+					return 1
+				default:
+					return 1
+				}
+			}(),
+		},
+		{
+			Item: gabagool.MenuItem{Text: "Empty Trash After"},
+			Options: []gabagool.Option{
+				{DisplayName: "Never", Value: 0},
+				{DisplayName: "7 Days", Value: 7},
+				{DisplayName: "14 Days", Value: 14},
+				{DisplayName: "30 Days", Value: 30},
+				{DisplayName: "90 Days", Value: 90},
+			},
+			SelectedOption: func() int {
+				switch appState.Config.TrashRetentionDays {
+				case 7:
+					return 1
+				case 14:
+					return 2
+				case 30:
+					return 3
+				case 90:
+					return 4
+				default:
+					return 0
+				}
+			}(),
+		},
 	}
 
 	footerHelpItems := []gabagool.FooterHelpItem{
@@ -214,6 +255,10 @@ func (s SettingsScreen) Draw() (settings interface{}, exitCode int, e error) {
 				appState.Config.PlayHistoryShowArchives = option.Options[option.SelectedOption].Value.(bool)
 			} else if option.Item.Text == "Play History Show Collection Tags" {
 				appState.Config.PlayHistoryShowCollections = option.Options[option.SelectedOption].Value.(bool)
+			} else if option.Item.Text == "Compress Archived Games" {
+				appState.Config.CompressArchives = option.Options[option.SelectedOption].Value.(bool)
+			} else if option.Item.Text == "Empty Trash After" {
+				appState.Config.TrashRetentionDays = option.Options[option.SelectedOption].Value.(int)
 			}
 		}
 
@@ -28,6 +28,20 @@ func (ts ToolsScreen) Draw() (value interface{}, exitCode int, e error) {
 		Metadata: "Global Actions",
 	})
 
+	menuItems = append(menuItems, gabagool.MenuItem{
+		Text:     "Check Collections",
+		Selected: false,
+		Focused:  false,
+		Metadata: "Check Collections",
+	})
+
+	menuItems = append(menuItems, gabagool.MenuItem{
+		Text:     "Recently Played",
+		Selected: false,
+		Focused:  false,
+		Metadata: "Recently Played",
+	})
+
 	menuItems = append(menuItems, gabagool.MenuItem{
 		Text:     "Play History",
 		Selected: false,
@@ -35,6 +49,20 @@ func (ts ToolsScreen) Draw() (value interface{}, exitCode int, e error) {
 		Metadata: "Play History",
 	})
 
+	menuItems = append(menuItems, gabagool.MenuItem{
+		Text:     "Recent Actions",
+		Selected: false,
+		Focused:  false,
+		Metadata: "Recent Actions",
+	})
+
+	menuItems = append(menuItems, gabagool.MenuItem{
+		Text:     "Trash",
+		Selected: false,
+		Focused:  false,
+		Metadata: "Trash",
+	})
+
 	options := gabagool.DefaultListOptions("Tools", menuItems)
 	options.FooterHelpItems = []gabagool.FooterHelpItem{
 		{ButtonName: "B", HelpText: "Back"},
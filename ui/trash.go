@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"fmt"
+	gaba "github.com/UncleJunVIP/gabagool/pkg/gabagool"
+	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
+	"go.uber.org/zap"
+	"nextui-game-manager/models"
+	"nextui-game-manager/state"
+	"nextui-game-manager/utils"
+	"qlova.tech/sum"
+	"time"
+)
+
+type TrashScreen struct {
+}
+
+func InitTrashScreen() TrashScreen {
+	return TrashScreen{}
+}
+
+func (ts TrashScreen) Name() sum.Int[models.ScreenName] {
+	return models.ScreenNames.Trash
+}
+
+// Lists deleted items, newest first. Items can be restored one at a time or the whole trash emptied
+func (ts TrashScreen) Draw() (value interface{}, exitCode int, e error) {
+	logger := common.GetLoggerInstance()
+
+	trash, err := utils.ListTrash()
+	if err != nil {
+		logger.Error("Unable to load trash", zap.Error(err))
+		utils.ShowTimedMessage("Unable to load trash!", time.Second*2)
+		return nil, 2, nil
+	}
+
+	if len(trash) == 0 {
+		return nil, 404, nil
+	}
+
+	var totalSize int64
+	var menuItems []gaba.MenuItem
+	for _, entry := range trash {
+		size := utils.TrashSize(entry)
+		totalSize += size
+
+		menuItems = append(menuItems, gaba.MenuItem{
+			Text:     fmt.Sprintf("[%s] %s (%s)", entry.Kind, entry.Description, utils.FormatSize(size)),
+			Selected: false,
+			Focused:  false,
+			Metadata: entry,
+		})
+	}
+
+	options := gaba.DefaultListOptions(fmt.Sprintf("Trash (%s)", utils.FormatSize(totalSize)), menuItems)
+
+	selectedIndex, visibleStartIndex := state.GetCurrentMenuPosition()
+	options.SelectedIndex = selectedIndex
+	options.VisibleStartIndex = visibleStartIndex
+
+	options.SmallTitle = true
+	options.EnableAction = true
+	options.FooterHelpItems = []gaba.FooterHelpItem{
+		{ButtonName: "B", HelpText: "Back"},
+		{ButtonName: "X", HelpText: "Empty Trash"},
+		{ButtonName: "A", HelpText: "Restore"},
+	}
+
+	selection, err := gaba.List(options)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	if selection.IsSome() && selection.Unwrap().ActionTriggered {
+		res, _ := gaba.ConfirmationMessage(fmt.Sprintf("Permanently delete %d items in the trash?\n\nThis cannot be undone!", len(trash)), []gaba.FooterHelpItem{
+			{ButtonName: "B", HelpText: "Cancel"},
+			{ButtonName: "X", HelpText: "Empty"},
+		}, gaba.MessageOptions{
+			ImagePath:     "",
+			ConfirmButton: gaba.ButtonX,
+		})
+
+		if res.IsSome() && !res.Unwrap().Cancelled {
+			if err := utils.EmptyTrash(); err != nil {
+				logger.Error("Unable to empty trash", zap.Error(err))
+				utils.ShowTimedMessage("Unable to empty trash!", time.Second*2)
+				return nil, 1, nil
+			}
+			state.UpdateCurrentMenuPosition(0, 0)
+		}
+
+		return nil, 0, nil
+	}
+
+	if selection.IsSome() && selection.Unwrap().SelectedIndex != -1 {
+		state.UpdateCurrentMenuPosition(selection.Unwrap().SelectedIndex, selection.Unwrap().VisiblePosition)
+		entry := selection.Unwrap().SelectedItem.Metadata.(models.TrashEntry)
+
+		if !utils.ConfirmAction(fmt.Sprintf("Restore %s?", entry.Description)) {
+			return nil, 0, nil
+		}
+
+		if err := utils.RestoreFromTrash(entry); err != nil {
+			logger.Error("Unable to restore from trash", zap.String("entry", entry.Description), zap.Error(err))
+			utils.ShowTimedMessage(fmt.Sprintf("Unable to restore %s!", entry.Description), time.Second*3)
+			return nil, 1, nil
+		}
+
+		state.UpdateCurrentMenuPosition(0, 0)
+		utils.ShowTimedMessage(fmt.Sprintf("Restored %s!", entry.Description), time.Second*2)
+		return entry, 0, nil
+	}
+
+	return nil, 2, nil
+}
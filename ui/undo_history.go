@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"fmt"
+	gaba "github.com/UncleJunVIP/gabagool/pkg/gabagool"
+	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
+	"go.uber.org/zap"
+	"nextui-game-manager/models"
+	"nextui-game-manager/state"
+	"nextui-game-manager/utils"
+	"qlova.tech/sum"
+	"time"
+)
+
+type UndoHistoryScreen struct {
+}
+
+func InitUndoHistoryScreen() UndoHistoryScreen {
+	return UndoHistoryScreen{}
+}
+
+func (uhs UndoHistoryScreen) Name() sum.Int[models.ScreenName] {
+	return models.ScreenNames.UndoHistory
+}
+
+// Lists recent actions, newest first, and undoes the selected one
+func (uhs UndoHistoryScreen) Draw() (value interface{}, exitCode int, e error) {
+	logger := common.GetLoggerInstance()
+
+	history, err := utils.LoadUndoHistory()
+	if err != nil {
+		logger.Error("Unable to load undo history", zap.Error(err))
+		utils.ShowTimedMessage("Unable to load recent actions!", time.Second*2)
+		return nil, 2, nil
+	}
+
+	if len(history) == 0 {
+		return nil, 404, nil
+	}
+
+	var menuItems []gaba.MenuItem
+	for _, entry := range history {
+		menuItems = append(menuItems, gaba.MenuItem{
+			Text:     fmt.Sprintf("%s : %s", entry.CreatedAt.Format("Jan 2 15:04"), entry.Description),
+			Selected: false,
+			Focused:  false,
+			Metadata: entry,
+		})
+	}
+
+	options := gaba.DefaultListOptions("Recent Actions", menuItems)
+
+	selectedIndex, visibleStartIndex := state.GetCurrentMenuPosition()
+	options.SelectedIndex = selectedIndex
+	options.VisibleStartIndex = visibleStartIndex
+
+	options.SmallTitle = true
+	options.FooterHelpItems = []gaba.FooterHelpItem{
+		{ButtonName: "B", HelpText: "Back"},
+		{ButtonName: "A", HelpText: "Undo"},
+	}
+
+	selection, err := gaba.List(options)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	if selection.IsSome() && selection.Unwrap().SelectedIndex != -1 {
+		state.UpdateCurrentMenuPosition(selection.Unwrap().SelectedIndex, selection.Unwrap().VisiblePosition)
+		entry := selection.Unwrap().SelectedItem.Metadata.(models.UndoEntry)
+
+		if !utils.ConfirmAction(fmt.Sprintf("Undo %s?", entry.Description)) {
+			return nil, 0, nil
+		}
+
+		if err := utils.UndoAction(entry); err != nil {
+			logger.Error("Unable to undo action", zap.String("action", entry.Description), zap.Error(err))
+			utils.ShowTimedMessage(fmt.Sprintf("Unable to fully undo %s!", entry.Description), time.Second*3)
+			return nil, 1, nil
+		}
+
+		state.UpdateCurrentMenuPosition(0, 0)
+		utils.ShowTimedMessage(fmt.Sprintf("Undid %s!", entry.Description), time.Second*2)
+		return entry, 0, nil
+	}
+
+	return nil, 2, nil
+}
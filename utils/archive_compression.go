@@ -0,0 +1,280 @@
+package utils
+
+import (
+	"archive/zip"
+	"fmt"
+	"github.com/spf13/afero"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// compressedArchiveExtension marks a game archived as a zip, keeping it apart from ROMs that are zips themselves.
+const compressedArchiveExtension = ".archive.zip"
+
+// IsCompressedArchive reports whether an archived file is a compressed game rather than the game itself.
+func IsCompressedArchive(filename string) bool {
+	return strings.HasSuffix(strings.ToLower(filename), compressedArchiveExtension)
+}
+
+// UncompressedName returns the file or folder name a compressed game is restored as. Other names are returned as is.
+func UncompressedName(filename string) string {
+	if !IsCompressedArchive(filename) {
+		return filename
+	}
+	return filename[:len(filename)-len(compressedArchiveExtension)]
+}
+
+// CompressedArchiveName is the name a game is given when archived compressed.
+func CompressedArchiveName(filename string) string {
+	return filename + compressedArchiveExtension
+}
+
+// ArchiveSpaceSaved adds up how much smaller the compressed games in an archive are than the games themselves.
+func ArchiveSpaceSaved(archivePath string) (int64, error) {
+	var saved int64
+
+	err := afero.Walk(fileSystem, archivePath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || !IsCompressedArchive(info.Name()) {
+			return nil
+		}
+
+		size, err := uncompressedSize(path)
+		if err != nil {
+			return err
+		}
+
+		saved += size - info.Size()
+		return nil
+	})
+
+	return saved, err
+}
+
+// compressPaths zips files and folders, named relative to baseDir, into zipPath. The zip is written under another name
+// and only renamed once complete, so a zip at zipPath always holds everything.
+func compressPaths(baseDir string, paths []string, zipPath string) error {
+	if DoesFileExists(zipPath) {
+		return fmt.Errorf("%s already exists", zipPath)
+	}
+
+	if err := EnsureDirectoryExists(filepath.Dir(zipPath)); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	partialPath := partialArchivePath(zipPath)
+	if err := writeArchive(baseDir, paths, partialPath); err != nil {
+		_ = fileSystem.Remove(partialPath)
+		return fmt.Errorf("failed to compress %s: %w", filepath.Base(zipPath), err)
+	}
+
+	return MoveFile(partialPath, zipPath)
+}
+
+func writeArchive(baseDir string, paths []string, zipPath string) error {
+	file, err := fileSystem.OpenFile(zipPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, defaultFilePerm)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := zip.NewWriter(file)
+
+	for _, path := range paths {
+		err := afero.Walk(fileSystem, filepath.Join(baseDir, path), func(filePath string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			name, err := filepath.Rel(baseDir, filePath)
+			if err != nil {
+				return err
+			}
+
+			return addToArchive(writer, filePath, filepath.ToSlash(name), info)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return file.Sync()
+}
+
+func addToArchive(writer *zip.Writer, filePath string, name string, info os.FileInfo) error {
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+
+	header.Name = name
+	if info.IsDir() {
+		header.Name += "/"
+		_, err := writer.CreateHeader(header)
+		return err
+	}
+
+	header.Method = zip.Deflate
+	entry, err := writer.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	source, err := fileSystem.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	_, err = io.Copy(entry, source)
+	return err
+}
+
+// extractArchive unzips zipPath into baseDir, replacing any files already there.
+func extractArchive(zipPath string, baseDir string) error {
+	reader, closeArchive, err := openArchive(zipPath)
+	if err != nil {
+		return err
+	}
+	defer closeArchive()
+
+	for _, entry := range reader.File {
+		destinationPath, err := archiveEntryPath(baseDir, entry.Name)
+		if err != nil {
+			return err
+		}
+
+		if entry.FileInfo().IsDir() {
+			if err := EnsureDirectoryExists(destinationPath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := extractArchiveEntry(entry, destinationPath); err != nil {
+			return fmt.Errorf("failed to extract %s: %w", entry.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func extractArchiveEntry(entry *zip.File, destinationPath string) error {
+	if err := EnsureDirectoryExists(filepath.Dir(destinationPath)); err != nil {
+		return err
+	}
+
+	source, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	destination, err := fileSystem.OpenFile(destinationPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, defaultFilePerm)
+	if err != nil {
+		return err
+	}
+	defer destination.Close()
+
+	if _, err := io.Copy(destination, source); err != nil {
+		return err
+	}
+
+	return destination.Sync()
+}
+
+// compressedArchiveEntries lists the files and folders at the top of a zip, i.e. what extracting it creates.
+func compressedArchiveEntries(zipPath string) ([]string, error) {
+	reader, closeArchive, err := openArchive(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer closeArchive()
+
+	var paths []string
+	for _, entry := range reader.File {
+		if _, err := archiveEntryPath("", entry.Name); err != nil {
+			return nil, err
+		}
+
+		top, _, _ := strings.Cut(entry.Name, "/")
+		if top == ".media" {
+			top = entry.Name
+		}
+
+		if top != "" && !strings.HasSuffix(top, "/") && !slices.Contains(paths, top) {
+			paths = append(paths, top)
+		}
+	}
+
+	return paths, nil
+}
+
+func uncompressedSize(zipPath string) (int64, error) {
+	reader, closeArchive, err := openArchive(zipPath)
+	if err != nil {
+		return 0, err
+	}
+	defer closeArchive()
+
+	var size int64
+	for _, entry := range reader.File {
+		size += int64(entry.UncompressedSize64)
+	}
+	return size, nil
+}
+
+func openArchive(zipPath string) (*zip.Reader, func(), error) {
+	file, err := fileSystem.Open(zipPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open %s: %w", filepath.Base(zipPath), err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	reader, err := zip.NewReader(file, info.Size())
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("failed to read %s: %w", filepath.Base(zipPath), err)
+	}
+
+	return reader, func() { _ = file.Close() }, nil
+}
+
+// archiveEntryPath is where an entry of a zip goes when extracted into baseDir, refusing entries that would end up
+// outside of it.
+func archiveEntryPath(baseDir string, name string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("archive entry %s is outside the archive", name)
+	}
+	return filepath.Join(baseDir, cleaned), nil
+}
+
+// removePaths removes files and folders named relative to baseDir, ignoring ones that are already gone.
+func removePaths(baseDir string, paths []string) error {
+	for _, path := range paths {
+		if err := fileSystem.RemoveAll(filepath.Join(baseDir, path)); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func partialArchivePath(zipPath string) string {
+	return zipPath + ".part"
+}
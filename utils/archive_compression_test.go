@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompressAndExtractPaths(t *testing.T) {
+	useFakeSDCard(t)
+
+	discs := filepath.Join(testRoms, "PlayStation (PS)", "Puzzle Pack")
+	writeTestFile(t, filepath.Join(discs, "Puzzle Pack (Disc 1).bin"), "disc 1")
+	writeTestFile(t, filepath.Join(discs, "Puzzle Pack.m3u"), "Puzzle Pack (Disc 1).bin\n")
+
+	zipPath := filepath.Join(testArchive, "PlayStation (PS)", CompressedArchiveName("Puzzle Pack"))
+	if err := compressPaths(filepath.Dir(discs), []string{"Puzzle Pack"}, zipPath); err != nil {
+		t.Fatalf("compressPaths: %v", err)
+	}
+	if err := compressPaths(filepath.Dir(discs), []string{"Puzzle Pack"}, zipPath); err == nil {
+		t.Error("compressing over an existing zip succeeded")
+	}
+
+	restoreDir := filepath.Join(testSDCard, "Restored")
+	if err := extractArchive(zipPath, restoreDir); err != nil {
+		t.Fatalf("extractArchive: %v", err)
+	}
+
+	if got := readTestFile(t, filepath.Join(restoreDir, "Puzzle Pack", "Puzzle Pack (Disc 1).bin")); got != "disc 1" {
+		t.Errorf("extracted disc holds %q", got)
+	}
+	if got := readTestFile(t, filepath.Join(restoreDir, "Puzzle Pack", "Puzzle Pack.m3u")); got != "Puzzle Pack (Disc 1).bin\n" {
+		t.Errorf("extracted playlist holds %q", got)
+	}
+}
+
+func TestExtractArchiveRejectsEntriesOutsideTheArchive(t *testing.T) {
+	useFakeSDCard(t)
+
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	for _, name := range []string{"Tetris.gb", "../../Saves/GB/Tetris.gb.sav"} {
+		entry, err := writer.Create(name)
+		if err != nil {
+			t.Fatalf("creating zip entry %s: %v", name, err)
+		}
+		if _, err := entry.Write([]byte(name)); err != nil {
+			t.Fatalf("writing zip entry %s: %v", name, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("closing zip: %v", err)
+	}
+
+	zipPath := filepath.Join(testArchivedRomDirectory.Path, CompressedArchiveName("Tetris.gb"))
+	writeTestFile(t, zipPath, buffer.String())
+
+	if err := extractArchive(zipPath, testPlatform); err == nil {
+		t.Fatal("extracting an entry outside the platform folder succeeded")
+	}
+	assertMissing(t, filepath.Join(testRoms, "Saves", "GB", "Tetris.gb.sav"))
+
+	for _, name := range []string{"../Tetris.gb", "/Tetris.gb", "..", ".media/../../Tetris.gb"} {
+		if path, err := archiveEntryPath(testPlatform, name); err == nil {
+			t.Errorf("archiveEntryPath(%q) = %q, want an error", name, path)
+		}
+	}
+	if path, err := archiveEntryPath(testPlatform, ".media/Tetris.png"); err != nil || path != filepath.Join(testPlatform, ".media", "Tetris.png") {
+		t.Errorf("archiveEntryPath(.media/Tetris.png) = %q, %v", path, err)
+	}
+}
@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"fmt"
+	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
+	"go.uber.org/zap"
+	"path/filepath"
+	"strings"
+)
+
+// archivedGameDataFolder holds the saves and emulator data of the games in an archive. Being hidden, it is never
+// listed as a platform of the archive.
+const archivedGameDataFolder = ".gamedata"
+
+// gameDataFolders pairs the folders NextUI keeps saves and emulator data in with where they are kept inside an archive.
+func gameDataFolders(archiveRoot string) [][2]string {
+	var folders [][2]string
+	if GetSaveFileDirectory() != "" {
+		folders = append(folders, [2]string{GetSaveFileDirectory(), filepath.Join(archiveRoot, archivedGameDataFolder, "Saves")})
+	}
+	if GetUserDataDirectory() != "" {
+		folders = append(folders, [2]string{GetUserDataDirectory(), filepath.Join(archiveRoot, archivedGameDataFolder, "userdata")})
+	}
+	return folders
+}
+
+// archiveGameData moves the save file, save states, state previews and per-game settings of a ROM into its archive.
+func archiveGameData(op *operation, filename string, romPath string, archiveRoot string) error {
+	tag := gameDataTag(romPath)
+	for _, folders := range gameDataFolders(archiveRoot) {
+		if err := moveGameData(op, folders[0], folders[1], tag, filename); err != nil {
+			return fmt.Errorf("failed to archive save data: %w", err)
+		}
+	}
+	return nil
+}
+
+// restoreGameData moves the saves and emulator data archived with a ROM back to where NextUI looks for them.
+func restoreGameData(op *operation, filename string, archivedRomPath string, archiveRoot string) error {
+	tag := gameDataTag(archivedRomPath)
+	for _, folders := range gameDataFolders(archiveRoot) {
+		if err := moveGameData(op, folders[1], folders[0], tag, UncompressedName(filename)); err != nil {
+			return fmt.Errorf("failed to restore save data: %w", err)
+		}
+	}
+	return nil
+}
+
+// moveGameData moves the data of a ROM from below one folder to the same place below another. Data already at the
+// destination is left alone rather than overwritten, as it was made after the game was archived.
+func moveGameData(op *operation, fromRoot string, toRoot string, tag string, filename string) error {
+	logger := common.GetLoggerInstance()
+
+	paths, err := findGameData(fromRoot, tag, filename)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		relativePath, err := filepath.Rel(fromRoot, path)
+		if err != nil {
+			return err
+		}
+
+		destinationPath := filepath.Join(toRoot, relativePath)
+		if DoesFileExists(destinationPath) {
+			logger.Info("Keeping newer save data", zap.String("path", destinationPath))
+			continue
+		}
+
+		if err := op.move(path, destinationPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findGameData lists the data kept for a ROM below root. Saves sit in a folder named after the platform tag, save
+// states and per-game settings in a folder for each core named after the tag and the core, and state previews in
+// .minui. Each file is named after the ROM file followed by its own extension.
+func findGameData(root string, tag string, filename string) ([]string, error) {
+	if tag == "" || !DoesFileExists(root) {
+		return nil, nil
+	}
+
+	entries, err := GetFileList(root)
+	if err != nil {
+		return nil, err
+	}
+
+	directories := []string{filepath.Join(root, tag), filepath.Join(root, ".minui", tag)}
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), tag+"-") {
+			directories = append(directories, filepath.Join(root, entry.Name()))
+		}
+	}
+
+	var paths []string
+	for _, directory := range directories {
+		if !DoesFileExists(directory) {
+			continue
+		}
+
+		files, err := GetFileList(directory)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			if !file.IsDir() && strings.HasPrefix(file.Name(), filename+".") {
+				paths = append(paths, filepath.Join(directory, file.Name()))
+			}
+		}
+	}
+
+	return paths, nil
+}
+
+// gameDataTag is the platform tag NextUI names the save and emulator folders of a ROM after.
+func gameDataTag(romPath string) string {
+	platformPath, _ := indexScope(romPath)
+	return cleanTag(itemTagPattern.FindString(filepath.Base(platformPath)))
+}
@@ -0,0 +1,359 @@
+package utils
+
+import (
+	"fmt"
+	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
+	"github.com/spf13/afero"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+	"nextui-game-manager/models"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// archiveManifestFile sits in the root of every archive and lists the games in it along with where they came from.
+const archiveManifestFile = ".manifest.yml"
+
+var (
+	archivedGameHomes      map[string]string
+	archivedGameHomesMutex sync.Mutex
+)
+
+func init() {
+	SubscribeLibraryChanges(followArchiveManifestChanges)
+}
+
+// ReadArchiveManifest lists the games an archive remembers. Archives made before manifests were kept get one built
+// from the games in them the first time they are read.
+func ReadArchiveManifest(archiveRoot string) ([]models.ArchivedGame, error) {
+	manifestPath := filepath.Join(archiveRoot, archiveManifestFile)
+	if !DoesFileExists(manifestPath) {
+		return buildArchiveManifest(archiveRoot)
+	}
+
+	data, err := afero.ReadFile(fileSystem, manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive manifest: %w", err)
+	}
+
+	var games []models.ArchivedGame
+	if err := yaml.Unmarshal(data, &games); err != nil {
+		return nil, fmt.Errorf("failed to parse archive manifest: %w", err)
+	}
+	return games, nil
+}
+
+// FindArchivedGame looks up what the archive an archived game is in remembers about it.
+func FindArchivedGame(archivedPath string) (models.ArchivedGame, bool) {
+	archiveRoot, relativePath, ok := archiveManifestScope(archivedPath)
+	if !ok {
+		return models.ArchivedGame{}, false
+	}
+
+	games, err := ReadArchiveManifest(archiveRoot)
+	if err != nil {
+		common.GetLoggerInstance().Error("Unable to read archive manifest", zap.String("archive", archiveRoot), zap.Error(err))
+		return models.ArchivedGame{}, false
+	}
+
+	index := slices.IndexFunc(games, func(game models.ArchivedGame) bool { return game.Path == relativePath })
+	if index == -1 {
+		return models.ArchivedGame{}, false
+	}
+	return games[index], true
+}
+
+// SetArchivedGameNote records why a game was archived, or clears the reason when note is empty.
+func SetArchivedGameNote(archivedPath string, note string) error {
+	archiveRoot, relativePath, ok := archiveManifestScope(archivedPath)
+	if !ok {
+		return fmt.Errorf("%s is not inside an archive", archivedPath)
+	}
+
+	return updateArchiveManifest(archiveRoot, func(games []models.ArchivedGame) ([]models.ArchivedGame, error) {
+		index := slices.IndexFunc(games, func(game models.ArchivedGame) bool { return game.Path == relativePath })
+		if index == -1 {
+			return nil, fmt.Errorf("%s is not in the archive manifest", relativePath)
+		}
+
+		games[index].Note = strings.TrimSpace(note)
+		return games, nil
+	})
+}
+
+// ArchivedGameHome returns the archive a game that was in the ROM directory at originalPath was archived to, going by
+// the archive manifests.
+func ArchivedGameHome(originalPath string) (string, bool) {
+	archivedGameHomesMutex.Lock()
+	defer archivedGameHomesMutex.Unlock()
+
+	if archivedGameHomes == nil {
+		archivedGameHomes = loadArchivedGameHomes()
+	}
+
+	archive, ok := archivedGameHomes[originalPath]
+	return archive, ok
+}
+
+func loadArchivedGameHomes() map[string]string {
+	logger := common.GetLoggerInstance()
+
+	homes := make(map[string]string)
+
+	archives, err := GetArchiveFileListBasic()
+	if err != nil {
+		logger.Error("Unable to list archives", zap.Error(err))
+		return homes
+	}
+
+	for _, archive := range archives {
+		games, err := ReadArchiveManifest(GetArchiveRoot(archive))
+		if err != nil {
+			logger.Error("Unable to read archive manifest", zap.String("archive", archive), zap.Error(err))
+			continue
+		}
+
+		for _, game := range games {
+			homes[game.OriginalPath] = archive
+		}
+	}
+
+	return homes
+}
+
+// forgetArchivedGameHomes drops the cached archive of every archived game so it is read again from the manifests.
+func forgetArchivedGameHomes() {
+	archivedGameHomesMutex.Lock()
+	defer archivedGameHomesMutex.Unlock()
+
+	archivedGameHomes = nil
+}
+
+// recordArchivedGame adds a game that was just put in an archive to its manifest, replacing whatever was remembered
+// for that path before.
+func recordArchivedGame(archivedPath string, originalPath string) error {
+	archiveRoot, relativePath, ok := archiveManifestScope(archivedPath)
+	if !ok {
+		return nil
+	}
+
+	game := archivedGameFor(archiveRoot, relativePath, originalPath)
+	game.ArchivedAt = time.Now()
+
+	return updateArchiveManifest(archiveRoot, func(games []models.ArchivedGame) ([]models.ArchivedGame, error) {
+		games = slices.DeleteFunc(games, func(existing models.ArchivedGame) bool { return existing.Path == relativePath })
+		return append(games, game), nil
+	})
+}
+
+// forgetArchivedGame drops a game that left its archive from the manifest.
+func forgetArchivedGame(archivedPath string) error {
+	archiveRoot, relativePath, ok := archiveManifestScope(archivedPath)
+	if !ok {
+		return nil
+	}
+
+	return updateArchiveManifest(archiveRoot, func(games []models.ArchivedGame) ([]models.ArchivedGame, error) {
+		return slices.DeleteFunc(games, func(game models.ArchivedGame) bool { return game.Path == relativePath }), nil
+	})
+}
+
+// moveArchivedGame keeps what the manifest remembers about a game renamed inside its archive or moved to another one.
+func moveArchivedGame(oldPath string, newPath string) error {
+	archiveRoot, oldRelativePath, ok := archiveManifestScope(oldPath)
+	if !ok {
+		return nil
+	}
+
+	newArchiveRoot, newRelativePath, ok := archiveManifestScope(newPath)
+	if !ok {
+		return forgetArchivedGame(oldPath)
+	}
+
+	if newArchiveRoot != archiveRoot {
+		game, found := FindArchivedGame(oldPath)
+		if err := forgetArchivedGame(oldPath); err != nil {
+			return err
+		}
+
+		if !found {
+			return recordArchivedGame(newPath, restoredPathFor(newRelativePath))
+		}
+
+		game.Path = newRelativePath
+		return updateArchiveManifest(newArchiveRoot, func(games []models.ArchivedGame) ([]models.ArchivedGame, error) {
+			games = slices.DeleteFunc(games, func(existing models.ArchivedGame) bool { return existing.Path == newRelativePath })
+			return append(games, game), nil
+		})
+	}
+
+	return updateArchiveManifest(archiveRoot, func(games []models.ArchivedGame) ([]models.ArchivedGame, error) {
+		for i, game := range games {
+			if game.Path == oldRelativePath {
+				games[i].Path = newRelativePath
+			}
+		}
+		return games, nil
+	})
+}
+
+// updateArchiveManifest rewrites the manifest of an archive, removing it once the archive no longer holds any games.
+func updateArchiveManifest(archiveRoot string, update func(games []models.ArchivedGame) ([]models.ArchivedGame, error)) error {
+	games, err := ReadArchiveManifest(archiveRoot)
+	if err != nil {
+		return err
+	}
+
+	games, err = update(games)
+	if err != nil {
+		return err
+	}
+
+	defer forgetArchivedGameHomes()
+
+	manifestPath := filepath.Join(archiveRoot, archiveManifestFile)
+	if len(games) == 0 {
+		if err := fileSystem.Remove(manifestPath); err != nil && DoesFileExists(manifestPath) {
+			return fmt.Errorf("failed to remove archive manifest: %w", err)
+		}
+		return nil
+	}
+
+	return saveArchiveManifest(archiveRoot, games)
+}
+
+func saveArchiveManifest(archiveRoot string, games []models.ArchivedGame) error {
+	data, err := yaml.Marshal(games)
+	if err != nil {
+		return fmt.Errorf("failed to encode archive manifest: %w", err)
+	}
+
+	return afero.WriteFile(fileSystem, filepath.Join(archiveRoot, archiveManifestFile), data, defaultFilePerm)
+}
+
+// buildArchiveManifest lists the games already in an archive without a manifest and saves them as its manifest. When
+// they were archived is not known.
+func buildArchiveManifest(archiveRoot string) ([]models.ArchivedGame, error) {
+	paths, err := listArchivedGames(archiveRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var games []models.ArchivedGame
+	for _, path := range paths {
+		relativePath, err := filepath.Rel(archiveRoot, path)
+		if err != nil {
+			return nil, err
+		}
+
+		games = append(games, archivedGameFor(archiveRoot, relativePath, restoredPathFor(relativePath)))
+	}
+
+	if len(games) == 0 {
+		return nil, nil
+	}
+
+	if err := saveArchiveManifest(archiveRoot, games); err != nil {
+		return nil, err
+	}
+	return games, nil
+}
+
+// listArchivedGames walks the platform folders of an archive for games, looking inside plain folders but not inside
+// multi-disc or self-contained games.
+func listArchivedGames(dirPath string) ([]string, error) {
+	if !DoesFileExists(dirPath) {
+		return nil, nil
+	}
+
+	items, err := listDirectoryItems(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, item := range items {
+		if strings.HasPrefix(item.Filename, ".") {
+			continue
+		}
+
+		if item.IsDirectory && !item.IsMultiDiscDirectory && !item.IsSelfContainedDirectory {
+			nested, err := listArchivedGames(item.Path)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, nested...)
+			continue
+		}
+
+		paths = append(paths, item.Path)
+	}
+
+	return paths, nil
+}
+
+func archivedGameFor(archiveRoot string, relativePath string, originalPath string) models.ArchivedGame {
+	archivedPath := filepath.Join(archiveRoot, relativePath)
+
+	playTime := gameTrackerPlayTime(archivedPath)
+	if playTime == 0 {
+		// Games archived before their play history followed them still have it under their old path
+		playTime = gameTrackerPlayTime(originalPath)
+	}
+
+	return models.ArchivedGame{
+		Path:         relativePath,
+		OriginalPath: originalPath,
+		Size:         directorySize(archivedPath),
+		PlayTime:     playTime,
+	}
+}
+
+// restoredPathFor is where a game at relativePath inside an archive goes when restored.
+func restoredPathFor(relativePath string) string {
+	return filepath.Join(GetRomDirectory(), filepath.Dir(relativePath), UncompressedName(filepath.Base(relativePath)))
+}
+
+// archiveManifestScope splits an archived path into the archive it is in and its path inside that archive.
+func archiveManifestScope(path string) (archiveRoot string, relativePath string, ok bool) {
+	_, archive := indexScope(path)
+	if archive == "" || archive == ".media" {
+		return "", "", false
+	}
+
+	archiveRoot = GetArchiveRoot(archive)
+	relativePath, err := filepath.Rel(archiveRoot, path)
+	if err != nil || relativePath == "." {
+		return "", "", false
+	}
+	return archiveRoot, relativePath, true
+}
+
+// followArchiveManifestChanges keeps the archive manifests in step with games moving in and out of archives.
+func followArchiveManifestChanges(change models.LibraryChange) {
+	var err error
+	switch change.Kind {
+	case models.LibraryChangeKinds.RomArchived:
+		err = recordArchivedGame(change.Path, change.PreviousPath)
+	case models.LibraryChangeKinds.RomRestored:
+		if change.PreviousPath != "" {
+			err = forgetArchivedGame(change.PreviousPath)
+		} else if _, relativePath, ok := archiveManifestScope(change.Path); ok {
+			// Put back from the trash into the archive it was deleted from
+			err = recordArchivedGame(change.Path, restoredPathFor(relativePath))
+		}
+	case models.LibraryChangeKinds.RomDeleted:
+		err = forgetArchivedGame(change.Path)
+	case models.LibraryChangeKinds.RomRenamed:
+		err = moveArchivedGame(change.PreviousPath, change.Path)
+	default:
+		return
+	}
+
+	if err != nil {
+		common.GetLoggerInstance().Error("Unable to update archive manifest", zap.String("path", change.Path), zap.Error(err))
+	}
+}
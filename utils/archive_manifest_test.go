@@ -0,0 +1,61 @@
+package utils
+
+import (
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadArchiveManifestBuildsOneForOlderArchives(t *testing.T) {
+	useFakeSDCard(t)
+
+	writeTestFile(t, filepath.Join(testArchivedRomDirectory.Path, "Tetris.gb"), "rom")
+	writeTestFile(t, filepath.Join(testArchivedRomDirectory.Path, ".media", "Tetris.png"), "art")
+	writeTestFile(t, filepath.Join(testArchive, "PlayStation (PS)", CompressedArchiveName("Puzzle Pack")), "zip")
+
+	games, err := ReadArchiveManifest(testArchive)
+	if err != nil {
+		t.Fatalf("ReadArchiveManifest: %v", err)
+	}
+	if len(games) != 2 {
+		t.Fatalf("manifest lists %+v, want the two games", games)
+	}
+	assertExists(t, filepath.Join(testArchive, archiveManifestFile))
+
+	if home, ok := ArchivedGameHome(filepath.Join(testRoms, "PlayStation (PS)", "Puzzle Pack")); !ok || home != ".Old" {
+		t.Errorf("ArchivedGameHome of a compressed game = %q, %v", home, ok)
+	}
+}
+
+func TestArchiveManifestFollowsRenamesAndNotes(t *testing.T) {
+	useFakeSDCard(t)
+
+	romPath := filepath.Join(testPlatform, "Tetris.gb")
+	writeTestFile(t, romPath, "rom")
+
+	game := shared.Item{DisplayName: "Tetris", Filename: "Tetris.gb", Path: romPath}
+	if err := ArchiveRom(game, testRomDirectory, "Old", false); err != nil {
+		t.Fatalf("ArchiveRom: %v", err)
+	}
+
+	archivedPath := filepath.Join(testArchivedRomDirectory.Path, "Tetris.gb")
+	if err := SetArchivedGameNote(archivedPath, "  Finished it  "); err != nil {
+		t.Fatalf("SetArchivedGameNote: %v", err)
+	}
+
+	archivedGame := shared.Item{DisplayName: "Tetris", Filename: "Tetris.gb", Path: archivedPath}
+	if _, err := RenameRom(archivedGame, "Tetris DX", testArchivedRomDirectory); err != nil {
+		t.Fatalf("RenameRom: %v", err)
+	}
+
+	if _, ok := FindArchivedGame(archivedPath); ok {
+		t.Error("manifest still lists the old name")
+	}
+	renamed, ok := FindArchivedGame(filepath.Join(testArchivedRomDirectory.Path, "Tetris DX.gb"))
+	if !ok {
+		t.Fatal("manifest lost the renamed game")
+	}
+	if renamed.Note != "Finished it" || renamed.OriginalPath != romPath {
+		t.Errorf("renamed game is remembered as %+v", renamed)
+	}
+}
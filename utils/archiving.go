@@ -1,15 +1,33 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
 	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
 	"go.uber.org/zap"
-	"os"
+	"nextui-game-manager/models"
 	"path/filepath"
 	"strings"
 )
 
+var archiveLocations []string
+
+// SetArchiveLocations sets the folders outside the ROM directory whose subfolders are archives too, e.g. a folder on
+// a USB drive.
+func SetArchiveLocations(locations []string) {
+	archiveLocations = nil
+	for _, location := range locations {
+		archiveLocations = append(archiveLocations, filepath.Clean(location))
+	}
+}
+
+func GetArchiveLocations() []string {
+	return archiveLocations
+}
+
+// GetArchiveFileListBasic lists the hidden archive folders in the ROM directory followed by the archives in each
+// archive location. Locations that are not mounted are skipped.
 func GetArchiveFileListBasic() ([]string, error) {
 	entries, err := GetFileList(GetRomDirectory())
 	if err != nil {
@@ -28,7 +46,7 @@ func GetArchiveFileListBasic() ([]string, error) {
 		}
 	}
 
-	return archiveFolders, nil
+	return append(archiveFolders, listExternalArchives()...), nil
 }
 
 func GetArchiveFileList() ([]string, error) {
@@ -44,22 +62,62 @@ func GetArchiveFileList() ([]string, error) {
 	return archiveFolders, nil
 }
 
-func ArchiveRom(selectedGame shared.Item, romDirectory shared.RomDirectory, archiveName string) error {
+// ArchiveRom moves a ROM and its art into an archive. Compressed, the two are zipped together into a single file so
+// the archive takes up less space on the SD card. Its saves, save states and per-game settings are moved into the
+// archive as well, and its game tracker data is pointed at the archived ROM.
+func ArchiveRom(selectedGame shared.Item, romDirectory shared.RomDirectory, archiveName string, compress bool) error {
 	logger := common.GetLoggerInstance()
 
 	sourcePath := filepath.Join(romDirectory.Path, selectedGame.Filename)
 	destinationPath := buildArchivePath(selectedGame.Filename, romDirectory, archiveName)
+	if compress {
+		destinationPath = CompressedArchiveName(destinationPath)
+	}
 
 	logger.Debug("Archiving ROM", zap.String("from", sourcePath), zap.String("to", destinationPath))
 
-	if err := MoveFile(sourcePath, destinationPath); err != nil {
+	op, err := beginOperation(fmt.Sprintf("Archive %s", selectedGame.Filename))
+	if err != nil {
+		return err
+	}
+
+	if compress {
+		err = compressRom(op, selectedGame.Filename, romDirectory, destinationPath)
+	} else {
+		err = op.move(sourcePath, destinationPath)
+		if err == nil {
+			err = archiveArtFile(op, selectedGame.Filename, romDirectory, archiveName)
+		}
+	}
+
+	if err == nil {
+		err = archiveGameData(op, selectedGame.Filename, sourcePath, GetArchiveRoot(archiveName))
+	}
+
+	if err != nil {
+		op.rollback()
 		return fmt.Errorf("failed to archive ROM: %w", err)
 	}
 
-	archiveArtFile(selectedGame.Filename, romDirectory, archiveName, logger)
+	migrateGameTrackerTree(op, sourcePath, destinationPath)
+
+	if err := op.moveReferences(sourcePath, destinationPath); err != nil {
+		op.rollback()
+		return err
+	}
+
+	op.commit()
+
+	publishLibraryChange(models.LibraryChange{
+		Kind:         models.LibraryChangeKinds.RomArchived,
+		Path:         destinationPath,
+		PreviousPath: sourcePath,
+	})
 	return nil
 }
 
+// RestoreRom moves an archived ROM and its art back to the platform folder, extracting them if they were compressed,
+// and puts its saves and game tracker data back the way they were before it was archived.
 func RestoreRom(selectedGame shared.Item, romDirectory shared.RomDirectory, archive shared.RomDirectory) error {
 	logger := common.GetLoggerInstance()
 
@@ -68,21 +126,127 @@ func RestoreRom(selectedGame shared.Item, romDirectory shared.RomDirectory, arch
 
 	logger.Debug("Restoring ROM", zap.String("from", sourcePath), zap.String("to", destinationPath))
 
-	if err := MoveFile(sourcePath, destinationPath); err != nil {
+	op, err := beginOperation(fmt.Sprintf("Restore %s", selectedGame.Filename))
+	if err != nil {
+		return err
+	}
+
+	if IsCompressedArchive(selectedGame.Filename) {
+		err = op.extract(sourcePath, filepath.Dir(destinationPath))
+	} else {
+		err = op.move(sourcePath, destinationPath)
+		if err == nil {
+			err = restoreArtFile(op, selectedGame.Filename, romDirectory, archive)
+		}
+	}
+
+	if err == nil {
+		err = restoreGameData(op, selectedGame.Filename, sourcePath, archive.Path)
+	}
+
+	if err != nil {
+		op.rollback()
 		return fmt.Errorf("failed to restore ROM: %w", err)
 	}
 
-	restoreArtFile(selectedGame.Filename, romDirectory, archive, logger)
+	migrateGameTrackerTree(op, sourcePath, destinationPath)
+
+	if err := op.moveReferences(sourcePath, destinationPath); err != nil {
+		op.rollback()
+		return err
+	}
+
+	op.commit()
+
+	publishLibraryChange(models.LibraryChange{
+		Kind:         models.LibraryChangeKinds.RomRestored,
+		Path:         destinationPath,
+		PreviousPath: sourcePath,
+	})
+	return nil
+}
+
+// RenameArchive moves an archive folder, keeping track of where the games in it were listed before they were archived.
+func RenameArchive(archivePath string, newArchivePath string) error {
+	if err := MoveFile(archivePath, newArchivePath); err != nil {
+		return err
+	}
+
+	if err := moveLibraryReferences(archivePath, newArchivePath); err != nil {
+		common.GetLoggerInstance().Error("Failed to update archived collection entries", zap.Error(err))
+	}
+
+	forgetArchivedGameHomes()
 	return nil
 }
 
 func CleanArchiveName(archive string) string {
+	if filepath.IsAbs(archive) {
+		return filepath.Base(archive)
+	}
 	return strings.TrimPrefix(archive, ".")
 }
 
+// ArchiveDisplayName names an archive for lists, adding the location of archives kept outside the ROM directory.
+func ArchiveDisplayName(archive string) string {
+	if filepath.IsAbs(archive) {
+		return fmt.Sprintf("%s (%s)", filepath.Base(archive), filepath.Base(filepath.Dir(archive)))
+	}
+	return archive
+}
+
+// ArchiveNameForRoot is how an archive is known from its folder: by name in the ROM directory and by path elsewhere.
+func ArchiveNameForRoot(archiveRoot string) string {
+	if filepath.Dir(archiveRoot) == GetRomDirectory() {
+		return filepath.Base(archiveRoot)
+	}
+	return archiveRoot
+}
+
+// RenamedArchiveRoot is where an archive goes when renamed, which stays in the location it is in.
+func RenamedArchiveRoot(archivePath string, newName string) string {
+	if filepath.Dir(archivePath) == GetRomDirectory() {
+		return GetArchiveRoot(PrepArchiveName(newName))
+	}
+	return filepath.Join(filepath.Dir(archivePath), newName)
+}
+
+func listExternalArchives() []string {
+	var archives []string
+	for _, location := range archiveLocations {
+		entries, err := GetFileList(location)
+		if err != nil {
+			common.GetLoggerInstance().Info("Archive location unavailable", zap.String("location", location), zap.Error(err))
+			continue
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+				archives = append(archives, filepath.Join(location, entry.Name()))
+			}
+		}
+	}
+	return archives
+}
+
+// LibraryScope works out which platform folder a path is in and, for archived games, which archive.
+func LibraryScope(path string) (platformPath string, archive string) {
+	return indexScope(path)
+}
+
+// externalArchiveLocation returns the archive location a path is in, or an empty string for paths in none of them.
+func externalArchiveLocation(path string) string {
+	for _, location := range archiveLocations {
+		if strings.HasPrefix(path, location+string(filepath.Separator)) {
+			return location
+		}
+	}
+	return ""
+}
+
 func DeleteArchive(archive shared.RomDirectory) (string, error) {
 	logger := common.GetLoggerInstance()
-	res, err := deleteArchiveRecursive(archive.Path, 0)
+	res, err := deleteArchiveRecursive(archive.Path)
 
 	if err != nil {
 		logger.Error("Failed to traverse archive", zap.Error(err))
@@ -93,20 +257,18 @@ func DeleteArchive(archive shared.RomDirectory) (string, error) {
 		return res, nil
 	}
 
-	removeErr := os.RemoveAll(archive.Path)
+	removeErr := fileSystem.RemoveAll(archive.Path)
 
 	if removeErr != nil {
 		return "", removeErr
 	}
 
+	forgetArchivedGameHomes()
 	return "", nil
 }
 
-func deleteArchiveRecursive(currentDirectory string, currentDepth int) (string, error) {
+func deleteArchiveRecursive(currentDirectory string) (string, error) {
 	logger := common.GetLoggerInstance()
-	if currentDepth > 10 {
-		return "Max Depth Exceeded", nil
-	}
 
 	entries, err := GetFileList(currentDirectory)
 
@@ -120,7 +282,7 @@ func deleteArchiveRecursive(currentDirectory string, currentDepth int) (string,
 			return file.Name(), nil
 		}
 
-		res, recurseErr := deleteArchiveRecursive(filepath.Join(currentDirectory, file.Name()), currentDepth+1)
+		res, recurseErr := deleteArchiveRecursive(filepath.Join(currentDirectory, file.Name()))
 
 		if recurseErr != nil {
 			return "", recurseErr
@@ -134,6 +296,183 @@ func deleteArchiveRecursive(currentDirectory string, currentDepth int) (string,
 	return "", nil
 }
 
+// SummarizeArchive counts the games in an archive per platform and how many of them have been played, and adds up
+// the space the archive takes, saves included. The counts come from the archive manifest.
+func SummarizeArchive(archive shared.RomDirectory) (models.ArchiveSummary, error) {
+	summary := models.ArchiveSummary{Platforms: make(map[string]int)}
+
+	games, err := ReadArchiveManifest(archive.Path)
+	if err != nil {
+		return summary, err
+	}
+
+	for _, game := range games {
+		platformPath, _ := indexScope(filepath.Join(archive.Path, game.Path))
+		summary.Platforms[filepath.Base(platformPath)]++
+		summary.Games++
+		summary.Size += game.Size
+
+		if game.PlayTime > 0 {
+			summary.PlayedGames++
+		}
+	}
+
+	summary.Size += directorySize(filepath.Join(archive.Path, archivedGameDataFolder))
+	return summary, nil
+}
+
+// EmptyArchive permanently deletes every game in an archive along with its art and saves, keeping the archive folder.
+// Play history is kept. Whatever cannot be deleted is skipped and reported once the rest is gone. It returns how many
+// games were deleted.
+func EmptyArchive(archive shared.RomDirectory) (int, error) {
+	logger := common.GetLoggerInstance()
+
+	games, err := listArchivedGames(archive.Path)
+	if err != nil {
+		return 0, err
+	}
+
+	entries, err := GetFileList(archive.Path)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	for _, entry := range entries {
+		if err := fileSystem.RemoveAll(filepath.Join(archive.Path, entry.Name())); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	deleted := 0
+	for _, game := range games {
+		if DoesFileExists(game) {
+			continue
+		}
+
+		if err := forgetArchivedReferences(game); err != nil {
+			logger.Error("Failed to forget collection entries of deleted game", zap.String("game", game), zap.Error(err))
+		}
+
+		publishLibraryChange(models.LibraryChange{
+			Kind: models.LibraryChangeKinds.RomDeleted,
+			Path: game,
+		})
+		deleted++
+	}
+
+	forgetArchivedGameHomes()
+
+	if len(errs) > 0 {
+		return deleted, fmt.Errorf("failed to empty archive: %w", errors.Join(errs...))
+	}
+	return deleted, nil
+}
+
+// DeleteArchiveAndContents permanently deletes an archive along with every game in it and returns how many games were
+// deleted.
+func DeleteArchiveAndContents(archive shared.RomDirectory) (int, error) {
+	deleted, err := EmptyArchive(archive)
+	if err != nil {
+		return deleted, err
+	}
+
+	if err := fileSystem.RemoveAll(archive.Path); err != nil {
+		return deleted, fmt.Errorf("failed to delete archive: %w", err)
+	}
+
+	forgetArchivedGameHomes()
+	return deleted, nil
+}
+
+// MoveArchiveContents moves every game in an archive into another one, along with its art, saves, play history and
+// what the archive remembers about it. Games the other archive already has one of are left where they are. It returns
+// how many games were moved and how many were left.
+func MoveArchiveContents(archive shared.RomDirectory, destinationArchive string) (int, int, error) {
+	logger := common.GetLoggerInstance()
+
+	games, err := listArchivedGames(archive.Path)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	destinationRoot := GetArchiveRoot(destinationArchive)
+	if destinationRoot == archive.Path {
+		return 0, 0, fmt.Errorf("cannot move an archive into itself")
+	}
+
+	moved, left := 0, 0
+	for _, game := range games {
+		if err := moveArchivedGameBetween(game, archive.Path, destinationRoot); err != nil {
+			logger.Error("Unable to move archived game", zap.String("game", game), zap.Error(err))
+			left++
+			continue
+		}
+		moved++
+	}
+
+	return moved, left, nil
+}
+
+func moveArchivedGameBetween(gamePath string, archiveRoot string, destinationRoot string) error {
+	relativePath, err := filepath.Rel(archiveRoot, gamePath)
+	if err != nil {
+		return err
+	}
+
+	destinationPath := filepath.Join(destinationRoot, relativePath)
+	if DoesFileExists(destinationPath) {
+		return fmt.Errorf("%s already exists", destinationPath)
+	}
+
+	filename := filepath.Base(gamePath)
+
+	op, err := beginOperation(fmt.Sprintf("Move %s to %s", filename, filepath.Base(destinationRoot)))
+	if err != nil {
+		return err
+	}
+
+	err = op.move(gamePath, destinationPath)
+	if err == nil {
+		var artPath string
+		artPath, err = FindExistingArt(filename, shared.RomDirectory{Path: filepath.Dir(gamePath)})
+		if err == nil && artPath != "" {
+			err = op.move(artPath, filepath.Join(filepath.Dir(destinationPath), ".media", filepath.Base(artPath)))
+		}
+	}
+
+	if err == nil {
+		tag := gameDataTag(gamePath)
+		from, to := gameDataFolders(archiveRoot), gameDataFolders(destinationRoot)
+		for i := range from {
+			if err = moveGameData(op, from[i][1], to[i][1], tag, UncompressedName(filename)); err != nil {
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		op.rollback()
+		return err
+	}
+
+	migrateGameTrackerTree(op, gamePath, destinationPath)
+
+	if err := op.moveReferences(gamePath, destinationPath); err != nil {
+		op.rollback()
+		return err
+	}
+
+	op.commit()
+
+	publishLibraryChange(models.LibraryChange{
+		Kind:         models.LibraryChangeKinds.RomRenamed,
+		Path:         destinationPath,
+		PreviousPath: gamePath,
+	})
+	return nil
+}
+
 func PrepArchiveName(archive string) string {
 	if !strings.HasPrefix(archive, ".") {
 		return "." + archive
@@ -149,34 +488,63 @@ func buildArchivePath(filename string, romDirectory shared.RomDirectory, archive
 
 func buildRestorePath(filename string, romDirectory shared.RomDirectory, archive shared.RomDirectory) string {
 	subdirectory := strings.ReplaceAll(romDirectory.Path, archive.Path, "")
-	return filepath.Join(GetRomDirectory(), subdirectory, filename)
+	return filepath.Join(GetRomDirectory(), subdirectory, UncompressedName(filename))
+}
+
+// compressRom zips a ROM together with its art, which is kept under .media inside the zip so both land back in place
+// when it is extracted into the platform folder.
+func compressRom(op *operation, filename string, romDirectory shared.RomDirectory, zipPath string) error {
+	paths := []string{filename}
+
+	artPath, err := FindExistingArt(filename, romDirectory)
+	if err != nil {
+		return fmt.Errorf("failed to find art to archive: %w", err)
+	}
+
+	if artPath != "" {
+		paths = append(paths, filepath.Join(".media", filepath.Base(artPath)))
+	}
+
+	return op.compress(romDirectory.Path, paths, zipPath)
 }
 
-func archiveArtFile(filename string, romDirectory shared.RomDirectory, archiveName string, logger *zap.Logger) {
+func archiveArtFile(op *operation, filename string, romDirectory shared.RomDirectory, archiveName string) error {
 	artPath, err := FindExistingArt(filename, romDirectory)
-	if err != nil || artPath == "" {
-		return
+	if err != nil {
+		return fmt.Errorf("failed to find art to archive: %w", err)
+	}
+
+	if artPath == "" {
+		return nil
 	}
 
 	archiveRoot := GetArchiveRoot(archiveName)
 	subdirectory := strings.ReplaceAll(romDirectory.Path, GetRomDirectory(), "")
 	destinationPath := filepath.Join(archiveRoot, subdirectory, ".media", filepath.Base(artPath))
 
-	if err := MoveFile(artPath, destinationPath); err != nil {
-		logger.Error("Failed to archive art file", zap.Error(err))
+	if err := op.move(artPath, destinationPath); err != nil {
+		return fmt.Errorf("failed to archive art file: %w", err)
 	}
+
+	return nil
 }
 
-func restoreArtFile(filename string, romDirectory shared.RomDirectory, archive shared.RomDirectory, logger *zap.Logger) {
+func restoreArtFile(op *operation, filename string, romDirectory shared.RomDirectory, archive shared.RomDirectory) error {
 	artPath, err := FindExistingArt(filename, romDirectory)
-	if err != nil || artPath == "" {
-		return
+	if err != nil {
+		return fmt.Errorf("failed to find art to restore: %w", err)
+	}
+
+	if artPath == "" {
+		return nil
 	}
 
 	subdirectory := strings.ReplaceAll(romDirectory.Path, archive.Path, "")
 	destinationPath := filepath.Join(GetRomDirectory(), subdirectory, ".media", filepath.Base(artPath))
 
-	if err := MoveFile(artPath, destinationPath); err != nil {
-		logger.Error("Failed to restore art file", zap.Error(err))
+	if err := op.move(artPath, destinationPath); err != nil {
+		return fmt.Errorf("failed to restore art file: %w", err)
 	}
+
+	return nil
 }
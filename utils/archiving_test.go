@@ -0,0 +1,221 @@
+package utils
+
+import (
+	"errors"
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"github.com/spf13/afero"
+	"path/filepath"
+	"testing"
+)
+
+const testArchive = testRoms + "/.Old"
+
+var testArchivedRomDirectory = shared.RomDirectory{DisplayName: "Game Boy", Tag: "(GB)", Path: testArchive + "/Game Boy (GB)"}
+
+func TestArchiveAndRestoreRom(t *testing.T) {
+	useFakeSDCard(t)
+
+	romPath := filepath.Join(testPlatform, "Tetris.gb")
+	artPath := filepath.Join(testPlatform, ".media", "Tetris.png")
+	savePath := filepath.Join(testSaves, "GB", "Tetris.gb.sav")
+	statePath := filepath.Join(testUserData, "GB-gambatte", "Tetris.gb.st0")
+	collectionFile := filepath.Join(testCollections, "Puzzle.txt")
+
+	writeTestFile(t, romPath, "rom")
+	writeTestFile(t, artPath, "art")
+	writeTestFile(t, savePath, "save")
+	writeTestFile(t, statePath, "state")
+	writeTestFile(t, collectionFile, "/Roms/Game Boy (GB)/Dr. Mario.gb\n/Roms/Game Boy (GB)/Tetris.gb\n")
+
+	game := shared.Item{DisplayName: "Tetris", Filename: "Tetris.gb", Path: romPath}
+	if err := ArchiveRom(game, testRomDirectory, "Old", false); err != nil {
+		t.Fatalf("ArchiveRom: %v", err)
+	}
+
+	archivedPath := filepath.Join(testArchivedRomDirectory.Path, "Tetris.gb")
+	assertMissing(t, romPath, artPath, savePath, statePath, GetOperationJournalPath())
+	assertExists(t,
+		archivedPath,
+		filepath.Join(testArchivedRomDirectory.Path, ".media", "Tetris.png"),
+		filepath.Join(testArchive, archivedGameDataFolder, "Saves", "GB", "Tetris.gb.sav"),
+		filepath.Join(testArchive, archivedGameDataFolder, "userdata", "GB-gambatte", "Tetris.gb.st0"))
+
+	if got, want := readTestFile(t, collectionFile), "/Roms/Game Boy (GB)/Dr. Mario.gb\n"; got != want {
+		t.Errorf("collection after archiving: %q, want %q", got, want)
+	}
+
+	archived, ok := FindArchivedGame(archivedPath)
+	if !ok {
+		t.Fatal("archived game is not in the archive manifest")
+	}
+	if archived.OriginalPath != romPath || archived.ArchivedAt.IsZero() {
+		t.Errorf("archive manifest remembers %+v", archived)
+	}
+	if home, ok := ArchivedGameHome(romPath); !ok || home != ".Old" {
+		t.Errorf("ArchivedGameHome = %q, %v", home, ok)
+	}
+
+	archivedGame := shared.Item{DisplayName: "Tetris", Filename: "Tetris.gb", Path: archivedPath}
+	if err := RestoreRom(archivedGame, testArchivedRomDirectory, shared.RomDirectory{Path: testArchive}); err != nil {
+		t.Fatalf("RestoreRom: %v", err)
+	}
+
+	assertExists(t, romPath, artPath, savePath, statePath)
+	assertMissing(t, archivedPath, filepath.Join(testArchive, archiveManifestFile), GetOperationJournalPath())
+
+	if got, want := readTestFile(t, collectionFile), "/Roms/Game Boy (GB)/Dr. Mario.gb\n/Roms/Game Boy (GB)/Tetris.gb\n"; got != want {
+		t.Errorf("collection after restoring: %q, want %q", got, want)
+	}
+	if _, ok := ArchivedGameHome(romPath); ok {
+		t.Error("restored game still has an archive")
+	}
+}
+
+func TestArchiveAndRestoreCompressedRom(t *testing.T) {
+	useFakeSDCard(t)
+
+	romPath := filepath.Join(testPlatform, "Tetris.gb")
+	artPath := filepath.Join(testPlatform, ".media", "Tetris.png")
+	writeTestFile(t, romPath, "rom contents")
+	writeTestFile(t, artPath, "art contents")
+
+	game := shared.Item{DisplayName: "Tetris", Filename: "Tetris.gb", Path: romPath}
+	if err := ArchiveRom(game, testRomDirectory, "Old", true); err != nil {
+		t.Fatalf("ArchiveRom: %v", err)
+	}
+
+	zipPath := filepath.Join(testArchivedRomDirectory.Path, CompressedArchiveName("Tetris.gb"))
+	assertMissing(t, romPath, artPath, partialArchivePath(zipPath))
+	assertExists(t, zipPath)
+
+	entries, err := compressedArchiveEntries(zipPath)
+	if err != nil {
+		t.Fatalf("listing %s: %v", zipPath, err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("zip holds %v, want the ROM and .media", entries)
+	}
+
+	archived, ok := FindArchivedGame(zipPath)
+	if !ok || archived.OriginalPath != romPath {
+		t.Errorf("archive manifest remembers %+v, %v", archived, ok)
+	}
+
+	archivedGame := shared.Item{DisplayName: "Tetris", Filename: filepath.Base(zipPath), Path: zipPath}
+	if err := RestoreRom(archivedGame, testArchivedRomDirectory, shared.RomDirectory{Path: testArchive}); err != nil {
+		t.Fatalf("RestoreRom: %v", err)
+	}
+
+	assertMissing(t, zipPath, GetOperationJournalPath())
+	if got := readTestFile(t, romPath); got != "rom contents" {
+		t.Errorf("restored ROM holds %q", got)
+	}
+	if got := readTestFile(t, artPath); got != "art contents" {
+		t.Errorf("restored art holds %q", got)
+	}
+}
+
+func TestArchiveRomRollsBackWhenTheArchiveIsTaken(t *testing.T) {
+	useFakeSDCard(t)
+
+	romPath := filepath.Join(testPlatform, "Tetris.gb")
+	writeTestFile(t, romPath, "rom")
+	writeTestFile(t, filepath.Join(testArchivedRomDirectory.Path, CompressedArchiveName("Tetris.gb")), "older archive")
+
+	game := shared.Item{DisplayName: "Tetris", Filename: "Tetris.gb", Path: romPath}
+	if err := ArchiveRom(game, testRomDirectory, "Old", true); err == nil {
+		t.Fatal("archiving over an existing archive succeeded")
+	}
+
+	assertExists(t, romPath)
+	assertMissing(t, GetOperationJournalPath())
+	if got := readTestFile(t, filepath.Join(testArchivedRomDirectory.Path, CompressedArchiveName("Tetris.gb"))); got != "older archive" {
+		t.Errorf("existing archive was overwritten with %q", got)
+	}
+}
+
+// failingRemoveFs fails to remove one path, e.g. a folder on a card that went read-only.
+type failingRemoveFs struct {
+	afero.Fs
+	failing string
+}
+
+func (fs failingRemoveFs) RemoveAll(path string) error {
+	if path == fs.failing {
+		return errors.New("read-only file system")
+	}
+	return fs.Fs.RemoveAll(path)
+}
+
+func archiveTestGames(t *testing.T) (tetris string, metroid string) {
+	t.Helper()
+
+	gbaDirectory := shared.RomDirectory{DisplayName: "Game Boy Advance", Tag: "(GBA)", Path: filepath.Join(testRoms, "Game Boy Advance (GBA)")}
+
+	writeTestFile(t, filepath.Join(testPlatform, "Tetris.gb"), "tetris")
+	writeTestFile(t, filepath.Join(gbaDirectory.Path, "Metroid Fusion.gba"), "metroid fusion")
+	writeTestFile(t, filepath.Join(testSaves, "GBA", "Metroid Fusion.gba.sav"), "save")
+	writeTestFile(t, filepath.Join(testCollections, "Favorites.txt"), "/Roms/Game Boy (GB)/Tetris.gb\n/Roms/Game Boy Advance (GBA)/Metroid Fusion.gba\n")
+
+	if err := ArchiveRom(shared.Item{Filename: "Tetris.gb", Path: filepath.Join(testPlatform, "Tetris.gb")}, testRomDirectory, "Old", false); err != nil {
+		t.Fatalf("ArchiveRom: %v", err)
+	}
+	if err := ArchiveRom(shared.Item{Filename: "Metroid Fusion.gba", Path: filepath.Join(gbaDirectory.Path, "Metroid Fusion.gba")}, gbaDirectory, "Old", false); err != nil {
+		t.Fatalf("ArchiveRom: %v", err)
+	}
+
+	return filepath.Join(testArchivedRomDirectory.Path, "Tetris.gb"), filepath.Join(testArchive, "Game Boy Advance (GBA)", "Metroid Fusion.gba")
+}
+
+func TestSummarizeArchive(t *testing.T) {
+	useFakeSDCard(t)
+	archiveTestGames(t)
+
+	summary, err := SummarizeArchive(shared.RomDirectory{DisplayName: "Old", Path: testArchive})
+	if err != nil {
+		t.Fatalf("SummarizeArchive: %v", err)
+	}
+
+	if summary.Games != 2 || summary.Platforms["Game Boy (GB)"] != 1 || summary.Platforms["Game Boy Advance (GBA)"] != 1 {
+		t.Errorf("summary counts %+v", summary)
+	}
+	if want := int64(len("tetris") + len("metroid fusion") + len("save")); summary.Size != want {
+		t.Errorf("summary size %d, want %d", summary.Size, want)
+	}
+}
+
+func TestEmptyArchiveForgetsWhatWasDeletedWhenPartOfItFails(t *testing.T) {
+	useFakeSDCard(t)
+	tetris, metroid := archiveTestGames(t)
+
+	SetFileSystem(failingRemoveFs{Fs: fileSystem, failing: filepath.Dir(metroid)})
+
+	deleted, err := EmptyArchive(shared.RomDirectory{DisplayName: "Old", Path: testArchive})
+	if err == nil {
+		t.Error("EmptyArchive did not report the folder it could not delete")
+	}
+	if deleted != 1 {
+		t.Errorf("EmptyArchive deleted %d games, want 1", deleted)
+	}
+
+	assertMissing(t, tetris)
+	assertExists(t, metroid)
+
+	if _, ok := FindArchivedGame(tetris); ok {
+		t.Error("archive manifest still lists the deleted game")
+	}
+	if _, ok := FindArchivedGame(metroid); !ok {
+		t.Error("archive manifest lost the game that is still there")
+	}
+	if _, ok := ArchivedGameHome(filepath.Join(testPlatform, "Tetris.gb")); ok {
+		t.Error("the deleted game still has an archive")
+	}
+
+	references, err := loadArchivedReferences()
+	if err != nil {
+		t.Fatalf("loadArchivedReferences: %v", err)
+	}
+	if len(references) != 1 || references[0].ArchivedPath != metroid {
+		t.Errorf("archived references are %+v, want only the game that is still there", references)
+	}
+}
@@ -4,12 +4,12 @@ import (
 	"fmt"
 	gaba "github.com/UncleJunVIP/gabagool/pkg/gabagool"
 	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
-	"github.com/UncleJunVIP/nextui-pak-shared-functions/filebrowser"
 	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
 	"github.com/disintegration/imaging"
 	"go.uber.org/zap"
 	"math"
 	"net/url"
+	"nextui-game-manager/models"
 	"path/filepath"
 	"qlova.tech/sum"
 	"regexp"
@@ -42,17 +42,47 @@ func FindExistingArt(selectedFile string, romDirectory shared.RomDirectory) (str
 }
 
 func FindAllArt(romDirectory shared.RomDirectory, games shared.Items, downloadType sum.Int[shared.ArtDownloadType], fuzzySearchThreshold float64) []gaba.Download {
-	logger := common.GetLoggerInstance()
+	client := common.NewThumbnailClient(downloadType)
+	section := client.BuildThumbnailSection(cleanTag(romDirectory.Tag))
 
-	artMap := make(map[shared.Item]string)
+	artMap := matchAllArt(client, section, games, fuzzySearchThreshold)
+
+	downloads := buildArtDownloads(artMap, client.RootURL, section)
+
+	return downloads
+}
+
+// FetchAllArt matches art for games the way FindAllArt does, listing the platform's art once, and downloads it right
+// away for when there is no download screen to hand the downloads to. It returns where the art of each game was saved,
+// keyed by the game's path; games without a match are left out.
+func FetchAllArt(romDirectory shared.RomDirectory, games shared.Items, downloadType sum.Int[shared.ArtDownloadType], fuzzySearchThreshold float64) map[string]string {
+	logger := common.GetLoggerInstance()
 
 	client := common.NewThumbnailClient(downloadType)
 	section := client.BuildThumbnailSection(cleanTag(romDirectory.Tag))
 
+	saved := make(map[string]string)
+	for game, artFilename := range matchAllArt(client, section, games, fuzzySearchThreshold) {
+		artPath, err := client.DownloadArt(section.HostSubdirectory, buildArtDirectory(game), artFilename, game.Filename)
+		if err != nil {
+			logger.Info("Unable to download art", zap.String("game", game.Path), zap.Error(err))
+			continue
+		}
+		saved[game.Path] = artPath
+	}
+
+	return saved
+}
+
+func matchAllArt(client *common.ThumbnailClient, section shared.Section, games shared.Items, fuzzySearchThreshold float64) map[shared.Item]string {
+	logger := common.GetLoggerInstance()
+
+	artMap := make(map[shared.Item]string)
+
 	artList, err := client.ListDirectory(section.HostSubdirectory)
 	if err != nil {
 		logger.Info("Unable to fetch art list", zap.Error(err))
-		return nil
+		return artMap
 	}
 
 	for _, game := range games {
@@ -62,9 +92,7 @@ func FindAllArt(romDirectory shared.RomDirectory, games shared.Items, downloadTy
 		}
 	}
 
-	downloads := buildArtDownloads(artMap, client.RootURL, section)
-
-	return downloads
+	return artMap
 }
 
 func FindArt(romDirectory shared.RomDirectory, game shared.Item, downloadType sum.Int[shared.ArtDownloadType], fuzzySearchThreshold float64) string {
@@ -109,17 +137,31 @@ func FindArt(romDirectory shared.RomDirectory, game shared.Item, downloadType su
 
 func FindRomsWithoutArt() (map[shared.RomDirectory][]shared.Item, error) {
 	logger := common.GetLoggerInstance()
-	romDirectories := make(map[shared.RomDirectory][]shared.Item)
 
-	fb := filebrowser.NewFileBrowser(logger)
+	romDirectories, err := findIndexedRomsWithoutArt()
+	if err == nil {
+		return romDirectories, nil
+	}
+
+	logger.Error("Library index unavailable, scanning for missing art instead", zap.Error(err))
+	return scanRomsWithoutArt()
+}
 
-	err := fb.CWD(GetRomDirectory(), false)
+func scanRomsWithoutArt() (map[shared.RomDirectory][]shared.Item, error) {
+	logger := common.GetLoggerInstance()
+	romDirectories := make(map[shared.RomDirectory][]shared.Item)
+
+	items, err := listDirectoryItems(GetRomDirectory())
 	if err != nil {
 		logger.Error("Failed to get rom directories", zap.Error(err))
 		return nil, fmt.Errorf("failed to get rom directories: %w", err)
 	}
 
-	for _, dir := range fb.Items {
+	for _, dir := range items {
+		if !dir.IsDirectory || strings.HasPrefix(dir.Filename, ".") {
+			continue
+		}
+
 		romDir := CreateRomDirectoryFromItem(dir)
 
 		if romDir.Tag == "(PORTS)" {
@@ -189,76 +231,12 @@ func fuzzyArtSearch(romFilename string, artList []shared.Item, threshold float64
 		threshold = .8 // Default
 	}
 
-	// Calculate similarity between two strings using Jaccard similarity
-	similarity := func(s1, s2 string) float64 {
-		// Tokenize
-		tokens1 := make(map[string]bool)
-		tokens2 := make(map[string]bool)
-
-		for _, token := range strings.Fields(s1) {
-			tokens1[token] = true
-		}
-		for _, token := range strings.Fields(s2) {
-			tokens2[token] = true
-		}
-
-		// Calculate intersection and union
-		intersection := 0
-		for token := range tokens1 {
-			if tokens2[token] {
-				intersection++
-			}
-		}
-
-		union := len(tokens1) + len(tokens2) - intersection
-		if union == 0 {
-			return 0
-		}
-
-		// Also consider character-level similarity for the main title
-		title1 := strings.Split(s1, "(")[0]
-		title2 := strings.Split(s2, "(")[0]
-
-		// Simple character overlap ratio
-		charSim := 0.0
-		if len(title1) > 0 && len(title2) > 0 {
-			matches := 0
-			maxLen := len(title1)
-			if len(title2) > maxLen {
-				maxLen = len(title2)
-			}
-
-			// Count matching characters in order
-			j := 0
-			for i := 0; i < len(title1) && j < len(title2); i++ {
-				if title1[i] == title2[j] {
-					matches++
-					j++
-				} else {
-					// Look ahead for match
-					for k := j + 1; k < len(title2) && k-j < 3; k++ {
-						if title1[i] == title2[k] {
-							j = k + 1
-							matches++
-							break
-						}
-					}
-				}
-			}
-			charSim = float64(matches) / float64(maxLen)
-		}
-
-		// Weighted combination of token and character similarity
-		tokenSim := float64(intersection) / float64(union)
-		return tokenSim*0.6 + charSim*0.4
-	}
-
 	bestMatch := ""
 	bestScore := 0.0
 
 	for _, art := range artList {
 		pngNorm := removeFileExtension(art.Filename)
-		score := similarity(romFilename, pngNorm)
+		score := titleSimilarity(romFilename, pngNorm)
 
 		zipRegions := regexp.MustCompile(`\((.*?)\)`).FindAllStringSubmatch(romFilename, -1)
 		pngRegions := regexp.MustCompile(`\((.*?)\)`).FindAllStringSubmatch(art.Filename, -1)
@@ -328,28 +306,31 @@ func buildArtDirectory(game shared.Item) string {
 	return filepath.Join(romDirectoryPath, ".media")
 }
 
-func renameArtFile(oldFilename, newFilename string, romDirectory shared.RomDirectory, logger *zap.Logger) {
+func renameArtFile(op *operation, oldFilename, newFilename string, romDirectory shared.RomDirectory) error {
+	logger := common.GetLoggerInstance()
+
 	existingArtPath, err := FindExistingArt(oldFilename, romDirectory)
 	if err != nil {
-		logger.Error("Failed to find existing art", zap.Error(err))
-		return
+		return fmt.Errorf("failed to find existing art: %w", err)
 	}
 
 	if existingArtPath == "" {
-		return
+		return nil
 	}
 
 	if !DoesFileExists(existingArtPath) {
 		logger.Info("Art file does not exist, skipping rename")
-		return
+		return nil
 	}
 
 	ext := filepath.Ext(existingArtPath)
 	newArtPath := filepath.Join(filepath.Dir(existingArtPath), newFilename+ext)
 
-	if err := MoveFile(existingArtPath, newArtPath); err != nil {
-		logger.Error("Failed to rename art file", zap.Error(err))
+	if err := op.move(existingArtPath, newArtPath); err != nil {
+		return fmt.Errorf("failed to rename art file: %w", err)
 	}
+
+	return nil
 }
 
 func DeleteArt(filename string, romDirectory shared.RomDirectory) {
@@ -366,5 +347,7 @@ func DeleteArt(filename string, romDirectory shared.RomDirectory) {
 		return
 	}
 
-	common.DeleteFile(artPath)
+	if _, err := MoveToTrash(models.TrashKindArt, removeFileExtension(filename), artPath); err != nil {
+		logger.Error("Failed to delete art", zap.Error(err))
+	}
 }
@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"fmt"
+	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"github.com/disintegration/imaging"
+	"go.uber.org/zap"
+	"image"
+	"image/color"
+	"nextui-game-manager/models"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	collectionArtSize     = 500
+	collectionArtGridSize = 2
+)
+
+func init() {
+	SubscribeLibraryChanges(followCollectionArtChanges)
+}
+
+// CollectionArtPath is where NextUI looks for a collection's art, next to the collection in the .media folder.
+func CollectionArtPath(collection models.Collection) string {
+	name := removeFileExtension(filepath.Base(collection.CollectionFile))
+	return filepath.Join(filepath.Dir(collection.CollectionFile), ".media", name+".png")
+}
+
+// ListCollectionGameArt returns the games of a collection that have art, with the art as their path.
+func ListCollectionGameArt(collection models.Collection) ([]shared.Item, error) {
+	collection, err := ReadCollection(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	var art []shared.Item
+	for _, game := range collection.Games {
+		if artPath := collectionGameArtPath(game); artPath != "" {
+			art = append(art, shared.Item{DisplayName: game.DisplayName, Filename: filepath.Base(artPath), Path: artPath})
+		}
+	}
+	return art, nil
+}
+
+// SetCollectionArt makes an image the collection's art, scaled down the same way downloaded art is.
+func SetCollectionArt(collection models.Collection, imagePath string) error {
+	src, err := openImage(imagePath)
+	if err != nil {
+		return err
+	}
+
+	if src.Bounds().Dx() > collectionArtSize {
+		src = imaging.Resize(src, collectionArtSize, 0, imaging.Lanczos)
+	}
+
+	return saveCollectionArt(collection, src)
+}
+
+// SetCollectionArtGrid builds the collection's art from the art of its first four games laid out in a grid. Games
+// without art are skipped.
+func SetCollectionArtGrid(collection models.Collection) error {
+	logger := common.GetLoggerInstance()
+
+	art, err := ListCollectionGameArt(collection)
+	if err != nil {
+		return err
+	}
+
+	cellSize := collectionArtSize / collectionArtGridSize
+	grid := imaging.New(collectionArtSize, collectionArtSize, color.Transparent)
+
+	cells := 0
+	for _, gameArt := range art {
+		if cells == collectionArtGridSize*collectionArtGridSize {
+			break
+		}
+
+		src, err := openImage(gameArt.Path)
+		if err != nil {
+			logger.Error("Unable to open game art", zap.String("path", gameArt.Path), zap.Error(err))
+			continue
+		}
+
+		cell := imaging.Fit(src, cellSize, cellSize, imaging.Lanczos)
+		origin := image.Pt((cells%collectionArtGridSize)*cellSize, (cells/collectionArtGridSize)*cellSize)
+
+		// Centered in its cell, as box art is rarely square
+		offset := image.Pt((cellSize-cell.Bounds().Dx())/2, (cellSize-cell.Bounds().Dy())/2)
+		grid = imaging.Paste(grid, cell, origin.Add(offset))
+		cells++
+	}
+
+	if cells == 0 {
+		return fmt.Errorf("no games in %s have art", collection.DisplayName)
+	}
+
+	return saveCollectionArt(collection, grid)
+}
+
+// DeleteCollectionArt moves the collection's art to the trash.
+func DeleteCollectionArt(collection models.Collection) (models.TrashEntry, error) {
+	return MoveToTrash(models.TrashKindArt, fmt.Sprintf("Art for %s", collection.DisplayName), CollectionArtPath(collection))
+}
+
+// ListImages lists the folders and PNG images in a directory for picking an image on the SD card.
+func ListImages(dirPath string) ([]shared.Item, error) {
+	items, err := listDirectoryItems(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []shared.Item
+	for _, item := range items {
+		if strings.HasPrefix(item.Filename, ".") {
+			continue
+		}
+
+		if item.IsDirectory || strings.EqualFold(filepath.Ext(item.Filename), ".png") {
+			images = append(images, item)
+		}
+	}
+	return images, nil
+}
+
+// collectionGameArtPath finds the art for a collection entry. Multi-disc and self-contained games are listed by a file
+// inside their folder, but their art is named after the folder.
+func collectionGameArtPath(game shared.Item) string {
+	gamePath := collectionEntryPath(normalizeCollectionGamePath(game))
+	dirPath := filepath.Dir(gamePath)
+
+	if filepath.Base(dirPath) == removeFileExtension(filepath.Base(gamePath)) {
+		gamePath = dirPath
+		dirPath = filepath.Dir(dirPath)
+	}
+
+	// FindExistingArt creates the .media folder when it is missing, which is not wanted for games that are gone
+	if !DoesFileExists(filepath.Join(dirPath, ".media")) {
+		return ""
+	}
+
+	artPath, err := FindExistingArt(filepath.Base(gamePath), shared.RomDirectory{Path: dirPath})
+	if err != nil {
+		return ""
+	}
+	return artPath
+}
+
+func openImage(imagePath string) (image.Image, error) {
+	file, err := fileSystem.Open(imagePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image: %w", err)
+	}
+	defer file.Close()
+
+	img, err := imaging.Decode(file, imaging.AutoOrientation(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image %s: %w", filepath.Base(imagePath), err)
+	}
+	return img, nil
+}
+
+func saveCollectionArt(collection models.Collection, img image.Image) error {
+	artPath := CollectionArtPath(collection)
+	if err := EnsureDirectoryExists(filepath.Dir(artPath)); err != nil {
+		return fmt.Errorf("failed to create collection art directory: %w", err)
+	}
+
+	file, err := fileSystem.OpenFile(artPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, defaultFilePerm)
+	if err != nil {
+		return fmt.Errorf("failed to open collection art: %w", err)
+	}
+	defer file.Close()
+
+	if err := imaging.Encode(file, img, imaging.PNG); err != nil {
+		return fmt.Errorf("failed to write collection art: %w", err)
+	}
+	return nil
+}
+
+// followCollectionArtChanges renames a collection's art along with the collection.
+func followCollectionArtChanges(change models.LibraryChange) {
+	if change.Kind != models.LibraryChangeKinds.CollectionRenamed {
+		return
+	}
+
+	previousArt := CollectionArtPath(models.Collection{CollectionFile: change.PreviousPath})
+	if !DoesFileExists(previousArt) {
+		return
+	}
+
+	if err := MoveFile(previousArt, CollectionArtPath(models.Collection{CollectionFile: change.Path})); err != nil {
+		common.GetLoggerInstance().Error("Unable to rename collection art", zap.String("art", previousArt), zap.Error(err))
+	}
+}
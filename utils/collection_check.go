@@ -0,0 +1,214 @@
+package utils
+
+import (
+	"fmt"
+	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"go.uber.org/zap"
+	"nextui-game-manager/models"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// CheckCollections reads every collection and returns the entries whose game is missing. For each one the platform
+// folder and every archive are searched for the same game, or failing that one with a close enough name.
+func CheckCollections() ([]models.CollectionProblem, error) {
+	logger := common.GetLoggerInstance()
+
+	collections, _, err := GenerateCollectionList("", false)
+	if err != nil {
+		return nil, err
+	}
+
+	archives, err := GetArchiveFileListBasic()
+	if err != nil {
+		logger.Error("Unable to list archives, only checking the platform folders", zap.Error(err))
+	}
+
+	var problems []models.CollectionProblem
+	for _, collection := range collections {
+		for _, entry := range collection.Games {
+			if DoesFileExists(collectionEntryPath(entry.Path)) {
+				continue
+			}
+
+			problem := models.CollectionProblem{Collection: collection, Entry: entry}
+			platformFolders := entryPlatformFolders(entry.Path)
+
+			candidates := listMissingGameCandidates(GetRomDirectory(), platformFolders)
+			if index := findMissingGame(entry, candidates); index != -1 {
+				problem.Replacement = candidates[index]
+			}
+
+			var archived []shared.Item
+			var archivedIn []string
+			for _, archive := range archives {
+				archiveCandidates := listMissingGameCandidates(GetArchiveRoot(archive), platformFolders)
+				archived = append(archived, archiveCandidates...)
+				for range archiveCandidates {
+					archivedIn = append(archivedIn, archive)
+				}
+			}
+
+			if index := findMissingGame(entry, archived); index != -1 {
+				problem.Archived = archived[index]
+				problem.Archive = shared.RomDirectory{
+					DisplayName: CleanArchiveName(archivedIn[index]),
+					Path:        GetArchiveRoot(archivedIn[index]),
+				}
+			}
+
+			problems = append(problems, problem)
+		}
+	}
+
+	return problems, nil
+}
+
+// RepairCollectionEntry points a broken collection entry at game instead. When the collection already has game the
+// broken entry is just dropped.
+func RepairCollectionEntry(problem models.CollectionProblem, game shared.Item) error {
+	collection, index, err := readProblemCollection(problem)
+	if err != nil {
+		return err
+	}
+
+	repaired := shared.Item{DisplayName: missingGameCandidateName(game), Path: normalizeCollectionGamePath(game)}
+
+	collection.Games[index] = repaired
+	if slices.IndexFunc(collection.Games, func(existing shared.Item) bool { return existing.Path == repaired.Path }) != index {
+		collection.Games = slices.Delete(collection.Games, index, index+1)
+	}
+
+	return SaveCollection(collection)
+}
+
+// RemoveCollectionEntry takes a broken entry out of its collection.
+func RemoveCollectionEntry(problem models.CollectionProblem) error {
+	collection, index, err := readProblemCollection(problem)
+	if err != nil {
+		return err
+	}
+
+	collection.Games = slices.Delete(collection.Games, index, index+1)
+	return SaveCollection(collection)
+}
+
+// RestoreCollectionEntry restores the archived game found for a broken entry and points the entry at it.
+func RestoreCollectionEntry(problem models.CollectionProblem) error {
+	if problem.Archived.Path == "" {
+		return fmt.Errorf("%s was not found in an archive", problem.Entry.DisplayName)
+	}
+
+	romDirectory := RomDirectoryForItem(problem.Archived, shared.RomDirectory{})
+	if err := RestoreRom(problem.Archived, romDirectory, problem.Archive); err != nil {
+		return err
+	}
+
+	// Listed again from disk, as a compressed game only shows what it held once extracted
+	restoredPath := buildRestorePath(problem.Archived.Filename, romDirectory, problem.Archive)
+	info, err := fileSystem.Stat(restoredPath)
+	if err != nil {
+		return fmt.Errorf("failed to find restored game: %w", err)
+	}
+
+	return RepairCollectionEntry(problem, buildItem(filepath.Dir(restoredPath), info))
+}
+
+func readProblemCollection(problem models.CollectionProblem) (models.Collection, int, error) {
+	collection, err := ReadCollection(problem.Collection)
+	if err != nil {
+		return collection, -1, err
+	}
+
+	index := slices.IndexFunc(collection.Games, func(game shared.Item) bool {
+		return game.Path == problem.Entry.Path
+	})
+	if index == -1 {
+		return collection, -1, fmt.Errorf("%s is no longer in %s", problem.Entry.Path, collection.DisplayName)
+	}
+
+	return collection, index, nil
+}
+
+// entryPlatformFolders returns the platform folder named in a collection entry, followed by any other platform folder
+// with the same tag in case it was renamed.
+func entryPlatformFolders(entryPath string) []string {
+	folder, _, _ := strings.Cut(strings.TrimPrefix(entryPath, "/Roms/"), "/")
+	folders := []string{folder}
+
+	tag := platformDirectory(filepath.Join(GetRomDirectory(), folder)).Tag
+	if tag == "" {
+		return folders
+	}
+
+	entries, err := GetFileList(GetRomDirectory())
+	if err != nil {
+		return folders
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || entry.Name() == folder {
+			continue
+		}
+
+		if strings.EqualFold(platformDirectory(filepath.Join(GetRomDirectory(), entry.Name())).Tag, tag) {
+			folders = append(folders, entry.Name())
+		}
+	}
+
+	return folders
+}
+
+func listMissingGameCandidates(root string, platformFolders []string) []shared.Item {
+	var candidates []shared.Item
+	for _, folder := range platformFolders {
+		items, err := listDirectoryItems(filepath.Join(root, folder))
+		if err != nil {
+			continue
+		}
+
+		for _, item := range items {
+			if !strings.HasPrefix(item.Filename, ".") {
+				candidates = append(candidates, item)
+			}
+		}
+	}
+	return candidates
+}
+
+// findMissingGame picks the candidate with the same name as a missing entry, or else the closest fuzzy match, and
+// returns -1 when there is none. Names are compared both ways round like collection imports.
+func findMissingGame(entry shared.Item, candidates []shared.Item) int {
+	name := removeFileExtension(filepath.Base(entry.Path))
+
+	if index := slices.IndexFunc(candidates, func(candidate shared.Item) bool {
+		return strings.EqualFold(missingGameCandidateName(candidate), name)
+	}); index != -1 {
+		return index
+	}
+
+	best := -1
+	bestScore := 0.0
+	for i, candidate := range candidates {
+		candidateName := missingGameCandidateName(candidate)
+		score := min(FuzzyScore(name, candidateName), FuzzyScore(candidateName, name))
+		if score > bestScore {
+			best = i
+			bestScore = score
+		}
+	}
+
+	if bestScore < importFuzzyThreshold {
+		return -1
+	}
+	return best
+}
+
+func missingGameCandidateName(item shared.Item) string {
+	if item.IsDirectory {
+		return item.Filename
+	}
+	return removeFileExtension(UncompressedName(item.Filename))
+}
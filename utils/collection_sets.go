@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"fmt"
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"nextui-game-manager/models"
+	"path/filepath"
+	"strings"
+)
+
+// DuplicateCollection copies the games of a collection into a new collection called name.
+func DuplicateCollection(collection models.Collection, name string) (models.Collection, error) {
+	collection, err := ReadCollection(collection)
+	if err != nil {
+		return models.Collection{}, err
+	}
+
+	return createCollection(name, collection.Games)
+}
+
+// MergeCollectionInto adds the games of collection that target does not have yet to the end of target.
+func MergeCollectionInto(collection models.Collection, target models.Collection) (models.Collection, error) {
+	collection, err := ReadCollection(collection)
+	if err != nil {
+		return models.Collection{}, err
+	}
+
+	target, err = ReadCollection(target)
+	if err != nil {
+		return models.Collection{}, err
+	}
+
+	target.Games = uniqueCollectionGames(append(target.Games, collection.Games...))
+	return target, SaveCollection(target)
+}
+
+// IntersectCollections creates a collection called name with the games of collection that other has as well.
+func IntersectCollections(collection models.Collection, other models.Collection, name string) (models.Collection, error) {
+	return combineCollections(collection, other, name, true)
+}
+
+// SubtractCollection creates a collection called name with the games of collection that other does not have.
+func SubtractCollection(collection models.Collection, other models.Collection, name string) (models.Collection, error) {
+	return combineCollections(collection, other, name, false)
+}
+
+func combineCollections(collection models.Collection, other models.Collection, name string, keepShared bool) (models.Collection, error) {
+	collection, err := ReadCollection(collection)
+	if err != nil {
+		return models.Collection{}, err
+	}
+
+	other, err = ReadCollection(other)
+	if err != nil {
+		return models.Collection{}, err
+	}
+
+	otherPaths := make(map[string]bool)
+	for _, game := range other.Games {
+		otherPaths[normalizeCollectionGamePath(game)] = true
+	}
+
+	var games shared.Items
+	for _, game := range collection.Games {
+		if otherPaths[normalizeCollectionGamePath(game)] == keepShared {
+			games = append(games, game)
+		}
+	}
+
+	return createCollection(name, games)
+}
+
+// createCollection writes games to a new collection, refusing to overwrite one that already exists.
+func createCollection(name string, games shared.Items) (models.Collection, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return models.Collection{}, fmt.Errorf("invalid collection name %q", name)
+	}
+
+	collection := models.Collection{
+		DisplayName:    name,
+		CollectionFile: filepath.Join(GetCollectionDirectory(), name+".txt"),
+		Games:          uniqueCollectionGames(games),
+	}
+
+	if DoesFileExists(collection.CollectionFile) {
+		return models.Collection{}, fmt.Errorf("collection %s already exists", name)
+	}
+
+	return collection, SaveCollection(collection)
+}
+
+// uniqueCollectionGames drops games listed more than once, comparing the paths written to the collection file.
+func uniqueCollectionGames(games shared.Items) shared.Items {
+	seen := make(map[string]bool)
+
+	var unique shared.Items
+	for _, game := range games {
+		path := normalizeCollectionGamePath(game)
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		unique = append(unique, game)
+	}
+	return unique
+}
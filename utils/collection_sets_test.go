@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"nextui-game-manager/models"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	testTetris     = "/Roms/Game Boy (GB)/Tetris.gb"
+	testDrMario    = "/Roms/Game Boy (GB)/Dr. Mario.gb"
+	testPuzzlePack = "/Roms/PlayStation (PS)/Puzzle Pack/Puzzle Pack.m3u"
+)
+
+// collectionSetsTestCollections writes a Puzzle and a Favorites collection that share Tetris.
+func collectionSetsTestCollections(t *testing.T) (puzzle models.Collection, favorites models.Collection) {
+	t.Helper()
+
+	puzzle = models.Collection{DisplayName: "Puzzle", CollectionFile: filepath.Join(testCollections, "Puzzle.txt")}
+	favorites = models.Collection{DisplayName: "Favorites", CollectionFile: filepath.Join(testCollections, "Favorites.txt")}
+
+	writeTestFile(t, puzzle.CollectionFile, testTetris+"\n"+testDrMario+"\n"+testPuzzlePack+"\n")
+	writeTestFile(t, favorites.CollectionFile, "/Roms/Game Boy Advance (GBA)/Metroid Fusion.gba\n"+testTetris+"\n")
+
+	return puzzle, favorites
+}
+
+func TestDuplicateCollection(t *testing.T) {
+	useFakeSDCard(t)
+	puzzle, _ := collectionSetsTestCollections(t)
+
+	duplicate, err := DuplicateCollection(puzzle, " Puzzle Copy ")
+	if err != nil {
+		t.Fatalf("DuplicateCollection: %v", err)
+	}
+	if duplicate.CollectionFile != filepath.Join(testCollections, "Puzzle Copy.txt") {
+		t.Errorf("duplicated into %s", duplicate.CollectionFile)
+	}
+	if got, want := readTestFile(t, duplicate.CollectionFile), readTestFile(t, puzzle.CollectionFile); got != want {
+		t.Errorf("duplicate holds:\n%s\nwant:\n%s", got, want)
+	}
+
+	for _, name := range []string{"Favorites", "", "Puzzle/Copy"} {
+		if _, err := DuplicateCollection(puzzle, name); err == nil {
+			t.Errorf("duplicating into %q succeeded", name)
+		}
+	}
+	if got := readTestFile(t, filepath.Join(testCollections, "Favorites.txt")); got != "/Roms/Game Boy Advance (GBA)/Metroid Fusion.gba\n"+testTetris+"\n" {
+		t.Errorf("an existing collection was overwritten with %q", got)
+	}
+}
+
+func TestMergeCollectionInto(t *testing.T) {
+	useFakeSDCard(t)
+	puzzle, favorites := collectionSetsTestCollections(t)
+
+	merged, err := MergeCollectionInto(puzzle, favorites)
+	if err != nil {
+		t.Fatalf("MergeCollectionInto: %v", err)
+	}
+	if len(merged.Games) != 4 {
+		t.Errorf("merged collection has %d games, want 4", len(merged.Games))
+	}
+
+	want := "/Roms/Game Boy Advance (GBA)/Metroid Fusion.gba\n" + testTetris + "\n" + testDrMario + "\n" + testPuzzlePack + "\n"
+	if got := readTestFile(t, favorites.CollectionFile); got != want {
+		t.Errorf("merged collection:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestIntersectAndSubtractCollections(t *testing.T) {
+	useFakeSDCard(t)
+	puzzle, favorites := collectionSetsTestCollections(t)
+
+	if _, err := IntersectCollections(puzzle, favorites, "Puzzle Favorites"); err != nil {
+		t.Fatalf("IntersectCollections: %v", err)
+	}
+	if got, want := readTestFile(t, filepath.Join(testCollections, "Puzzle Favorites.txt")), testTetris+"\n"; got != want {
+		t.Errorf("intersection:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err := SubtractCollection(puzzle, favorites, "Puzzle Backlog"); err != nil {
+		t.Fatalf("SubtractCollection: %v", err)
+	}
+	if got, want := readTestFile(t, filepath.Join(testCollections, "Puzzle Backlog.txt")), testDrMario+"\n"+testPuzzlePack+"\n"; got != want {
+		t.Errorf("difference:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err := SubtractCollection(favorites, puzzle, "Puzzle"); err == nil {
+		t.Error("subtracting into an existing collection succeeded")
+	}
+}
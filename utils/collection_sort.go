@@ -0,0 +1,282 @@
+package utils
+
+import (
+	"cmp"
+	"fmt"
+	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"github.com/spf13/afero"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+	"nextui-game-manager/models"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const collectionOrderFile = "collection_order.yml"
+
+func init() {
+	SubscribeLibraryChanges(followCollectionOrderChanges)
+}
+
+// GetCollectionSort returns the sort order a collection is kept in, manual if it has none.
+func GetCollectionSort(collection models.Collection) string {
+	orders, err := loadCollectionOrders()
+	if err != nil {
+		return models.CollectionSortKeys[0]
+	}
+
+	if sortKey := orders[collectionOrderKey(collection)].Sort; sortKey != "" {
+		return sortKey
+	}
+	return models.CollectionSortKeys[0]
+}
+
+// SortCollection puts a collection in the given order. When keep is set the order is remembered and applied every time
+// the collection is saved, otherwise the collection is sorted once and goes back to being ordered by hand.
+func SortCollection(collection models.Collection, sortKey string, keep bool) (models.Collection, error) {
+	if _, ok := models.CollectionSortMap[sortKey]; !ok {
+		return collection, fmt.Errorf("unknown sort %q, try %s", sortKey, strings.Join(models.CollectionSortKeys, ", "))
+	}
+
+	collection, err := ReadCollection(collection)
+	if err != nil {
+		return collection, err
+	}
+
+	orders, err := loadCollectionOrders()
+	if err != nil {
+		return collection, err
+	}
+
+	key := collectionOrderKey(collection)
+	order := orders[key]
+
+	order.Sort = ""
+	if keep && models.CollectionSortMap[sortKey] != models.CollectionSorts.Manual {
+		order.Sort = sortKey
+	}
+
+	orders[key] = order
+	if err := saveCollectionOrders(orders); err != nil {
+		return collection, err
+	}
+
+	sortCollectionGames(collection.Games, sortKey, order.Added)
+	return collection, SaveCollection(collection)
+}
+
+// orderCollectionGames is run by SaveCollection. It remembers when new games were added, forgets games that were
+// removed and applies the sort order the collection is kept in, if any.
+func orderCollectionGames(collection models.Collection) shared.Items {
+	logger := common.GetLoggerInstance()
+
+	orders, err := loadCollectionOrders()
+	if err != nil {
+		logger.Error("Unable to load collection orders", zap.Error(err))
+		return collection.Games
+	}
+
+	key := collectionOrderKey(collection)
+	order := orders[key]
+
+	now := time.Now()
+	added := make(map[string]time.Time)
+	for _, game := range collection.Games {
+		path := normalizeCollectionGamePath(game)
+		if addedAt, ok := order.Added[path]; ok {
+			added[path] = addedAt
+		} else {
+			added[path] = now
+		}
+	}
+
+	order.Added = added
+	orders[key] = order
+
+	if err := saveCollectionOrders(orders); err != nil {
+		logger.Error("Unable to save collection orders", zap.Error(err))
+	}
+
+	if order.Sort != "" {
+		sortCollectionGames(collection.Games, order.Sort, added)
+	}
+	return collection.Games
+}
+
+// sortCollectionGames orders the games of a collection, keeping the current order for ties. Name breaks ties when
+// sorting by platform, and the most recent or most played games come first.
+func sortCollectionGames(games shared.Items, sortKey string, added map[string]time.Time) {
+	order := models.CollectionSortMap[sortKey]
+	if order == models.CollectionSorts.Manual {
+		return
+	}
+
+	aggregates := make(map[string]models.PlayHistoryAggregate)
+	if order == models.CollectionSorts.PlayTime || order == models.CollectionSorts.LastPlayed {
+		gamePlayMap, _, _ := GenerateCurrentGameStats()
+		for _, game := range games {
+			path := normalizeCollectionGamePath(game)
+			aggregates[path], _ = CollectGameAggregateFromGame(shared.Item{
+				DisplayName: game.DisplayName,
+				Path:        collectionEntryPath(path),
+			}, gamePlayMap)
+		}
+	}
+
+	byName := func(a, b shared.Item) int {
+		return strings.Compare(strings.ToLower(a.DisplayName), strings.ToLower(b.DisplayName))
+	}
+
+	slices.SortStableFunc(games, func(a, b shared.Item) int {
+		pathA, pathB := normalizeCollectionGamePath(a), normalizeCollectionGamePath(b)
+
+		switch order {
+		case models.CollectionSorts.Name:
+			return byName(a, b)
+		case models.CollectionSorts.Platform:
+			platformA, _, _ := strings.Cut(strings.TrimPrefix(pathA, "/Roms/"), "/")
+			platformB, _, _ := strings.Cut(strings.TrimPrefix(pathB, "/Roms/"), "/")
+			return cmp.Or(strings.Compare(strings.ToLower(platformA), strings.ToLower(platformB)), byName(a, b))
+		case models.CollectionSorts.PlayTime:
+			return cmp.Compare(aggregates[pathB].PlayTimeTotal, aggregates[pathA].PlayTimeTotal)
+		case models.CollectionSorts.LastPlayed:
+			return aggregates[pathB].LastPlayedTime.Compare(aggregates[pathA].LastPlayedTime)
+		case models.CollectionSorts.DateAdded:
+			return added[pathB].Compare(added[pathA])
+		}
+		return 0
+	})
+}
+
+// moveCollectionOrderEntries carries when games were added over to their new paths after a ROM or multi-disc folder
+// moved within the library. Moves rewrite collection files in place instead of saving them, so the games would
+// otherwise look newly added the next time their collection is saved.
+func moveCollectionOrderEntries(oldEntry string, newEntry string) error {
+	orders, err := loadCollectionOrders()
+	if err != nil || len(orders) == 0 {
+		return err
+	}
+
+	changed := false
+	for key, order := range orders {
+		added := make(map[string]time.Time, len(order.Added))
+		for path, addedAt := range order.Added {
+			if newPath, moved := movedEntryPath(path, oldEntry, newEntry); moved {
+				path = newPath
+				changed = true
+			}
+			added[path] = addedAt
+		}
+		order.Added = added
+		orders[key] = order
+	}
+
+	if !changed {
+		return nil
+	}
+	return saveCollectionOrders(orders)
+}
+
+// sortCollectionLines puts the rewritten lines of a collection file back in the sort order the collection is kept in,
+// if any. Other files, such as the recently played list, are left as they are.
+func sortCollectionLines(file string, lines []string) []string {
+	if file == GetRecentlyPlayedFile() {
+		return lines
+	}
+
+	orders, err := loadCollectionOrders()
+	if err != nil {
+		return lines
+	}
+
+	order := orders[filepath.Base(file)]
+	if order.Sort == "" {
+		return lines
+	}
+
+	games := make(shared.Items, 0, len(lines))
+	for _, line := range lines {
+		games = append(games, shared.Item{
+			DisplayName: strings.ReplaceAll(filepath.Base(line), filepath.Ext(line), ""),
+			Path:        line,
+		})
+	}
+
+	sortCollectionGames(games, order.Sort, order.Added)
+
+	sorted := make([]string, 0, len(games))
+	for _, game := range games {
+		sorted = append(sorted, game.Path)
+	}
+	return sorted
+}
+
+func collectionOrderKey(collection models.Collection) string {
+	return filepath.Base(collection.CollectionFile)
+}
+
+func loadCollectionOrders() (map[string]models.CollectionOrder, error) {
+	orders := make(map[string]models.CollectionOrder)
+	if !DoesFileExists(GetCollectionOrderPath()) {
+		return orders, nil
+	}
+
+	data, err := afero.ReadFile(fileSystem, GetCollectionOrderPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read collection orders: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, &orders); err != nil {
+		return nil, fmt.Errorf("failed to parse collection orders: %w", err)
+	}
+
+	if orders == nil {
+		orders = make(map[string]models.CollectionOrder)
+	}
+	return orders, nil
+}
+
+func saveCollectionOrders(orders map[string]models.CollectionOrder) error {
+	data, err := yaml.Marshal(orders)
+	if err != nil {
+		return fmt.Errorf("failed to encode collection orders: %w", err)
+	}
+
+	return afero.WriteFile(fileSystem, GetCollectionOrderPath(), data, defaultFilePerm)
+}
+
+// followCollectionOrderChanges carries a collection's order over when it is renamed and forgets it when deleted.
+func followCollectionOrderChanges(change models.LibraryChange) {
+	if change.Kind != models.LibraryChangeKinds.CollectionRenamed && change.Kind != models.LibraryChangeKinds.CollectionDeleted {
+		return
+	}
+
+	logger := common.GetLoggerInstance()
+
+	orders, err := loadCollectionOrders()
+	if err != nil || len(orders) == 0 {
+		return
+	}
+
+	previousKey := filepath.Base(change.PreviousPath)
+	if change.Kind == models.LibraryChangeKinds.CollectionDeleted {
+		previousKey = filepath.Base(change.Path)
+	}
+
+	order, ok := orders[previousKey]
+	if !ok {
+		return
+	}
+
+	delete(orders, previousKey)
+	if change.Kind == models.LibraryChangeKinds.CollectionRenamed {
+		orders[filepath.Base(change.Path)] = order
+	}
+
+	if err := saveCollectionOrders(orders); err != nil {
+		logger.Error("Unable to update collection orders", zap.Error(err))
+	}
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"nextui-game-manager/models"
+	"path/filepath"
+	"testing"
+)
+
+func TestSortCollection(t *testing.T) {
+	useFakeSDCard(t)
+
+	collection := models.Collection{DisplayName: "Puzzle", CollectionFile: filepath.Join(testCollections, "Puzzle.txt")}
+	writeTestFile(t, collection.CollectionFile, "/Roms/Game Boy (GB)/Tetris.gb\n/Roms/Game Boy (GB)/Dr. Mario.gb\n")
+
+	if _, err := SortCollection(collection, "name", false); err != nil {
+		t.Fatalf("SortCollection: %v", err)
+	}
+	if got, want := readTestFile(t, collection.CollectionFile), "/Roms/Game Boy (GB)/Dr. Mario.gb\n/Roms/Game Boy (GB)/Tetris.gb\n"; got != want {
+		t.Errorf("sorted once:\n%s\nwant:\n%s", got, want)
+	}
+	if sortKey := GetCollectionSort(collection); sortKey != "manual" {
+		t.Errorf("a one-off sort is kept as %q", sortKey)
+	}
+
+	if _, err := SortCollection(collection, "name", true); err != nil {
+		t.Fatalf("SortCollection: %v", err)
+	}
+	if sortKey := GetCollectionSort(collection); sortKey != "name" {
+		t.Errorf("kept sort is %q, want name", sortKey)
+	}
+
+	if _, err := AddCollectionGames(collection, []shared.Item{{DisplayName: "Alleyway", Path: filepath.Join(testPlatform, "Alleyway.gb")}}); err != nil {
+		t.Fatalf("AddCollectionGames: %v", err)
+	}
+	want := "/Roms/Game Boy (GB)/Alleyway.gb\n/Roms/Game Boy (GB)/Dr. Mario.gb\n/Roms/Game Boy (GB)/Tetris.gb\n"
+	if got := readTestFile(t, collection.CollectionFile); got != want {
+		t.Errorf("kept order after adding:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err := SortCollection(collection, "size", true); err == nil {
+		t.Error("an unknown sort was accepted")
+	}
+}
+
+func TestRestoredGamesFollowTheKeptSort(t *testing.T) {
+	useFakeSDCard(t)
+
+	romPath := filepath.Join(testPlatform, "Dr. Mario.gb")
+	writeTestFile(t, romPath, "rom")
+
+	collection := models.Collection{DisplayName: "Puzzle", CollectionFile: filepath.Join(testCollections, "Puzzle.txt")}
+	writeTestFile(t, collection.CollectionFile, "/Roms/Game Boy (GB)/Alleyway.gb\n/Roms/Game Boy (GB)/Dr. Mario.gb\n/Roms/Game Boy (GB)/Tetris.gb\n")
+	if _, err := SortCollection(collection, "name", true); err != nil {
+		t.Fatalf("SortCollection: %v", err)
+	}
+
+	game := shared.Item{DisplayName: "Dr. Mario", Filename: "Dr. Mario.gb", Path: romPath}
+	if err := ArchiveRom(game, testRomDirectory, "Old", false); err != nil {
+		t.Fatalf("ArchiveRom: %v", err)
+	}
+	if _, err := AddCollectionGames(collection, []shared.Item{{DisplayName: "Boxxle", Path: filepath.Join(testPlatform, "Boxxle.gb")}}); err != nil {
+		t.Fatalf("AddCollectionGames: %v", err)
+	}
+
+	archivedGame := shared.Item{DisplayName: "Dr. Mario", Filename: "Dr. Mario.gb", Path: filepath.Join(testArchivedRomDirectory.Path, "Dr. Mario.gb")}
+	if err := RestoreRom(archivedGame, testArchivedRomDirectory, shared.RomDirectory{Path: testArchive}); err != nil {
+		t.Fatalf("RestoreRom: %v", err)
+	}
+
+	want := "/Roms/Game Boy (GB)/Alleyway.gb\n/Roms/Game Boy (GB)/Boxxle.gb\n/Roms/Game Boy (GB)/Dr. Mario.gb\n/Roms/Game Boy (GB)/Tetris.gb\n"
+	if got := readTestFile(t, collection.CollectionFile); got != want {
+		t.Errorf("collection after restoring:\n%s\nwant:\n%s", got, want)
+	}
+}
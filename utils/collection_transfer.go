@@ -0,0 +1,216 @@
+package utils
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"github.com/spf13/afero"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+	"io"
+	"nextui-game-manager/models"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	collectionExportExtension = ".collection.yml"
+	importFuzzyThreshold      = 0.8
+)
+
+// ExportCollection writes a collection to the export directory in a form that can be imported on another SD card and
+// returns where it was written. Hashing every game is slow for large ROMs but lets renamed games be found.
+func ExportCollection(collection models.Collection, includeHashes bool) (string, error) {
+	logger := common.GetLoggerInstance()
+
+	collection, err := ReadCollection(collection)
+	if err != nil {
+		return "", err
+	}
+
+	export := models.CollectionExport{Name: collection.DisplayName}
+
+	for _, game := range collection.Games {
+		gamePath := collectionEntryPath(game.Path)
+		platformFolder, _, _ := strings.Cut(strings.TrimPrefix(game.Path, "/Roms/"), "/")
+		platform := platformDirectory(filepath.Join(GetRomDirectory(), platformFolder))
+
+		entry := models.CollectionExportEntry{
+			Path:        game.Path,
+			Platform:    exportedPlatform(platform),
+			DisplayName: game.DisplayName,
+		}
+
+		if info, err := fileSystem.Stat(gamePath); err == nil && !info.IsDir() {
+			entry.Size = info.Size()
+
+			if includeHashes && !strings.EqualFold(filepath.Ext(gamePath), ".m3u") {
+				if entry.Hash, err = hashFile(gamePath); err != nil {
+					logger.Error("Unable to hash game for export", zap.String("path", gamePath), zap.Error(err))
+				}
+			}
+		}
+
+		export.Games = append(export.Games, entry)
+	}
+
+	data, err := yaml.Marshal(export)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode collection export: %w", err)
+	}
+
+	if err := EnsureDirectoryExists(GetExportDirectory()); err != nil {
+		return "", fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	exportPath := filepath.Join(GetExportDirectory(), collection.DisplayName+collectionExportExtension)
+	if err := afero.WriteFile(fileSystem, exportPath, data, defaultFilePerm); err != nil {
+		return "", fmt.Errorf("failed to write collection export: %w", err)
+	}
+
+	return exportPath, nil
+}
+
+// ListCollectionExports returns the collection exports waiting in the export directory.
+func ListCollectionExports() ([]shared.Item, error) {
+	items, err := listDirectoryItems(GetExportDirectory())
+	if err != nil {
+		return nil, err
+	}
+
+	var exports []shared.Item
+	for _, item := range items {
+		if !item.IsDirectory && strings.HasSuffix(item.Filename, collectionExportExtension) {
+			item.DisplayName = strings.TrimSuffix(item.Filename, collectionExportExtension)
+			exports = append(exports, item)
+		}
+	}
+	return exports, nil
+}
+
+// ImportCollection reads an export and adds the games it can find on this SD card to the collection of the same name,
+// creating it if needed. Each game is looked for by its exact path, then by name on the same platform, then by hash
+// when the export has one, and finally by fuzzy name. Games that cannot be found are reported back.
+func ImportCollection(exportPath string) (models.CollectionImport, error) {
+	data, err := afero.ReadFile(fileSystem, exportPath)
+	if err != nil {
+		return models.CollectionImport{}, fmt.Errorf("failed to read collection export: %w", err)
+	}
+
+	var export models.CollectionExport
+	if err := yaml.Unmarshal(data, &export); err != nil {
+		return models.CollectionImport{}, fmt.Errorf("failed to parse collection export: %w", err)
+	}
+
+	if strings.TrimSpace(export.Name) == "" || strings.ContainsAny(export.Name, `/\`) {
+		return models.CollectionImport{}, fmt.Errorf("collection export has an invalid name %q", export.Name)
+	}
+
+	roms, err := ListLibraryRoms()
+	if err != nil {
+		return models.CollectionImport{}, fmt.Errorf("failed to list library: %w", err)
+	}
+
+	result := models.CollectionImport{}
+
+	var games []shared.Item
+	for _, entry := range export.Games {
+		game, found := resolveExportEntry(entry, roms, &result)
+		if !found {
+			result.Unresolved = append(result.Unresolved, entry)
+			continue
+		}
+		games = append(games, game)
+	}
+
+	result.Collection, err = AddCollectionGames(models.Collection{
+		DisplayName:    export.Name,
+		CollectionFile: filepath.Join(GetCollectionDirectory(), export.Name+".txt"),
+	}, games)
+
+	return result, err
+}
+
+func resolveExportEntry(entry models.CollectionExportEntry, roms []models.IndexedRom, result *models.CollectionImport) (shared.Item, bool) {
+	for _, rom := range roms {
+		if normalizeCollectionGamePath(rom.Item) == entry.Path {
+			result.ByPath++
+			return rom.Item, true
+		}
+	}
+
+	var candidates []models.IndexedRom
+	for _, rom := range roms {
+		if matchesPlatform(rom.Platform, entry.Platform) {
+			candidates = append(candidates, rom)
+		}
+	}
+
+	for _, rom := range candidates {
+		if strings.EqualFold(rom.Item.DisplayName, entry.DisplayName) {
+			result.ByName++
+			return rom.Item, true
+		}
+	}
+
+	if entry.Hash != "" {
+		for _, rom := range candidates {
+			if rom.Item.IsDirectory || rom.Size != entry.Size {
+				continue
+			}
+
+			if hash, err := hashFile(rom.Item.Path); err == nil && hash == entry.Hash {
+				result.ByHash++
+				return rom.Item, true
+			}
+		}
+	}
+
+	// Both ways round so neither name may have words the other lacks, only typos and different tags
+	bestScore := 0.0
+	var best shared.Item
+	for _, rom := range candidates {
+		score := min(FuzzyScore(entry.DisplayName, rom.Item.DisplayName), FuzzyScore(rom.Item.DisplayName, entry.DisplayName))
+		if score > bestScore {
+			bestScore = score
+			best = rom.Item
+		}
+	}
+
+	if bestScore >= importFuzzyThreshold {
+		result.ByFuzzy++
+		return best, true
+	}
+
+	return shared.Item{}, false
+}
+
+// exportedPlatform names a platform by its tag, which stays the same across SD cards far more often than the folder.
+func exportedPlatform(platform shared.RomDirectory) string {
+	if platform.Tag != "" {
+		return strings.Trim(platform.Tag, "()")
+	}
+	return platform.DisplayName
+}
+
+// collectionEntryPath turns a path as written in a collection file back into a path on the SD card.
+func collectionEntryPath(entryPath string) string {
+	return filepath.Join(GetRomDirectory(), strings.TrimPrefix(entryPath, "/Roms/"))
+}
+
+func hashFile(path string) (string, error) {
+	file, err := fileSystem.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha1.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
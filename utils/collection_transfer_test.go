@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"gopkg.in/yaml.v3"
+	"nextui-game-manager/models"
+	"path/filepath"
+	"testing"
+)
+
+const testExports = testSDCard + "/Exports"
+
+func useTestExportDirectory() {
+	testLayout := GetLayout()
+	testLayout.ExportDirectory = testExports
+	SetLayout(testLayout)
+}
+
+func TestExportAndImportCollection(t *testing.T) {
+	useFakeSDCard(t)
+	useTestExportDirectory()
+
+	writeTestFile(t, filepath.Join(testPlatform, "Tetris.gb"), "tetris")
+	writeTestFile(t, filepath.Join(testPlatform, "Dr. Mario.gb"), "dr. mario")
+	writeTestFile(t, filepath.Join(testCollections, "Puzzle.txt"),
+		"/Roms/Game Boy (GB)/Tetris.gb\n/Roms/Game Boy (GB)/Dr. Mario.gb\n/Roms/Game Boy (GB)/Kirby's Star Stacker.gb\n")
+
+	exportPath, err := ExportCollection(models.Collection{DisplayName: "Puzzle", CollectionFile: filepath.Join(testCollections, "Puzzle.txt")}, true)
+	if err != nil {
+		t.Fatalf("ExportCollection: %v", err)
+	}
+	if exportPath != filepath.Join(testExports, "Puzzle"+collectionExportExtension) {
+		t.Errorf("exported to %s", exportPath)
+	}
+
+	data := readTestFile(t, exportPath)
+	var export models.CollectionExport
+	if err := yaml.Unmarshal([]byte(data), &export); err != nil {
+		t.Fatalf("parsing export: %v", err)
+	}
+	if len(export.Games) != 3 || export.Games[0].Platform != "GB" || export.Games[0].Size != int64(len("tetris")) ||
+		export.Games[0].Hash == "" || export.Games[2].Hash != "" {
+		t.Errorf("export holds %+v", export)
+	}
+
+	// Import on another SD card where the platform folder has a different name and Dr. Mario was renamed
+	useFakeSDCard(t)
+	useTestExportDirectory()
+
+	otherPlatform := filepath.Join(testRoms, "Nintendo Game Boy (GB)")
+	writeTestFile(t, filepath.Join(otherPlatform, "Tetris.gb"), "tetris")
+	writeTestFile(t, filepath.Join(otherPlatform, "Doctor Mario.gb"), "dr. mario")
+	writeTestFile(t, exportPath, data)
+
+	if exports, err := ListCollectionExports(); err != nil || len(exports) != 1 || exports[0].DisplayName != "Puzzle" {
+		t.Errorf("ListCollectionExports = %+v, %v", exports, err)
+	}
+
+	useLibraryIndex(t)
+
+	imported, err := ImportCollection(exportPath)
+	if err != nil {
+		t.Fatalf("ImportCollection: %v", err)
+	}
+	if imported.ByName != 1 || imported.ByHash != 1 || len(imported.Unresolved) != 1 || imported.Unresolved[0].DisplayName != "Kirby's Star Stacker" {
+		t.Errorf("import %+v", imported)
+	}
+
+	want := "/Roms/Nintendo Game Boy (GB)/Tetris.gb\n/Roms/Nintendo Game Boy (GB)/Doctor Mario.gb\n"
+	if got := readTestFile(t, filepath.Join(testCollections, "Puzzle.txt")); got != want {
+		t.Errorf("imported collection:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"fmt"
 	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
-	"github.com/UncleJunVIP/nextui-pak-shared-functions/filebrowser"
 	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
 	"go.uber.org/zap"
 	"nextui-game-manager/models"
@@ -15,18 +14,18 @@ import (
 	"time"
 )
 
-func updateCollectionGamePath(collection models.Collection, oldDisplayName string, game shared.Item, romDirectory shared.RomDirectory) {
-	romDirectoryStub := strings.ReplaceAll(romDirectory.Path, GetRomDirectory(), "")
-
-	for i, item := range collection.Games {
-		if item.DisplayName == oldDisplayName {
-			collection.Games[i].Path = filepath.Join(romDirectoryStub, game.Filename)
-		}
+func DeleteCollection(collection models.Collection) (models.TrashEntry, error) {
+	// The art goes along so restoring the collection brings it back too
+	entry, err := MoveToTrash(models.TrashKindCollection, collection.DisplayName, collection.CollectionFile, CollectionArtPath(collection))
+	if err != nil {
+		return entry, err
 	}
-}
 
-func DeleteCollection(collection models.Collection) {
-	common.DeleteFile(collection.CollectionFile)
+	publishLibraryChange(models.LibraryChange{
+		Kind: models.LibraryChangeKinds.CollectionDeleted,
+		Path: collection.CollectionFile,
+	})
+	return entry, nil
 }
 
 func AddCollectionGames(collection models.Collection, games []shared.Item) (models.Collection, error) {
@@ -54,8 +53,9 @@ func AddCollectionGames(collection models.Collection, games []shared.Item) (mode
 }
 
 func GameExistsInCollection(games []shared.Item, targetGame shared.Item) bool {
+	targetPath := normalizeCollectionGamePath(targetGame)
 	for _, game := range games {
-		if strings.Contains(strings.ToLower(game.Path), strings.ToLower(targetGame.DisplayName)) {
+		if normalizeCollectionGamePath(game) == targetPath {
 			return true
 		}
 	}
@@ -65,7 +65,7 @@ func GameExistsInCollection(games []shared.Item, targetGame shared.Item) bool {
 func ReadCollection(collection models.Collection) (models.Collection, error) {
 	logger := common.GetLoggerInstance()
 
-	file, err := os.Open(collection.CollectionFile)
+	file, err := fileSystem.Open(collection.CollectionFile)
 	if err != nil {
 		logger.Error("Failed to open collection file", zap.String("file", collection.CollectionFile), zap.Error(err))
 		return collection, fmt.Errorf("failed to open collection file: %w", err)
@@ -103,62 +103,60 @@ func SaveCollection(collection models.Collection) error {
 		return fmt.Errorf("failed to create collection directory: %w", err)
 	}
 
-	file, err := os.OpenFile(collection.CollectionFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, defaultFilePerm)
+	games := orderCollectionGames(collection)
+
+	file, err := fileSystem.OpenFile(collection.CollectionFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, defaultFilePerm)
 	if err != nil {
 		return fmt.Errorf("failed to open collection file: %w", err)
 	}
 	defer file.Close()
 
 	writer := bufio.NewWriter(file)
-	defer writer.Flush()
 
-	for _, game := range collection.Games {
+	for _, game := range games {
 		path := normalizeCollectionGamePath(game)
 		if _, err := writer.WriteString(path + "\n"); err != nil {
 			return fmt.Errorf("failed to write collection entry: %w", err)
 		}
 	}
 
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write collection: %w", err)
+	}
+
+	publishLibraryChange(models.LibraryChange{
+		Kind: models.LibraryChangeKinds.CollectionSaved,
+		Path: collection.CollectionFile,
+	})
 	return nil
 }
 
 func normalizeCollectionGamePath(game shared.Item) string {
 	path := strings.ReplaceAll(game.Path, GetRomDirectory()+"/", "/Roms/")
 
+	// Multi-disc playlists are named after the folder, tags and all
 	if game.IsMultiDiscDirectory {
-		path = filepath.Join(path, game.DisplayName+".m3u")
+		path = filepath.Join(path, filepath.Base(game.Path)+".m3u")
 	}
 
 	return path
 }
 
-func findCollectionsContainingGame(game shared.Item, logger *zap.Logger) []models.Collection {
-	fb := filebrowser.NewFileBrowser(logger)
-	if err := fb.CWD(GetCollectionDirectory(), false); err != nil {
-		return nil
+// listCollectionItems returns the collection files, skipping hidden files and folders such as .media.
+func listCollectionItems() ([]shared.Item, error) {
+	items, err := listDirectoryItems(GetCollectionDirectory())
+	if err != nil {
+		return nil, err
 	}
 
-	var collections []models.Collection
-	for _, item := range fb.Items {
-		collection := models.Collection{
-			DisplayName:    item.DisplayName,
-			CollectionFile: item.Path,
-		}
-
-		if loadedCollection, err := ReadCollection(collection); err == nil {
-			if containsGame(loadedCollection.Games, game) {
-				collections = append(collections, loadedCollection)
-			}
+	var collectionItems []shared.Item
+	for _, item := range items {
+		if item.IsDirectory || strings.HasPrefix(item.Filename, ".") {
+			continue
 		}
+		collectionItems = append(collectionItems, item)
 	}
-
-	return collections
-}
-
-func containsGame(games []shared.Item, targetGame shared.Item) bool {
-	return slices.ContainsFunc(games, func(game shared.Item) bool {
-		return game.DisplayName == targetGame.DisplayName
-	})
+	return collectionItems, nil
 }
 
 func GenerateCollectionMap() map[string][]models.Collection {
@@ -173,8 +171,7 @@ func GenerateCollectionMap() map[string][]models.Collection {
 }
 
 func GenerateCollectionList(searchFilter string, onScreen bool) (collections []models.Collection, exitCode int, e error) {
-	fb := filebrowser.NewFileBrowser(common.GetLoggerInstance())
-	err := fb.CWD(GetCollectionDirectory(), false)
+	itemList, err := listCollectionItems()
 	if err != nil {
 		if onScreen {
 			ShowTimedMessage("Unable to Load Collections!", time.Second*2)
@@ -182,12 +179,10 @@ func GenerateCollectionList(searchFilter string, onScreen bool) (collections []m
 		return nil, 404, nil
 	}
 
-	if fb.Items == nil || len(fb.Items) == 0 {
+	if len(itemList) == 0 {
 		return nil, 404, nil
 	}
 
-	itemList := fb.Items
-
 	if searchFilter != "" {
 		itemList = FilterList(itemList, searchFilter)
 	}
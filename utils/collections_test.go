@@ -0,0 +1,136 @@
+package utils
+
+import (
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"nextui-game-manager/models"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveCollection(t *testing.T) {
+	useFakeSDCard(t)
+
+	collection := models.Collection{
+		DisplayName:    "Puzzle",
+		CollectionFile: filepath.Join(testCollections, "Puzzle.txt"),
+		Games: shared.Items{
+			{DisplayName: "Tetris", Path: filepath.Join(testPlatform, "Tetris.gb")},
+			{DisplayName: "Dr. Mario", Path: "/Roms/Game Boy (GB)/Dr. Mario.gb"},
+			{DisplayName: "Puzzle Pack", Path: filepath.Join(testRoms, "PlayStation (PS)", "Puzzle Pack"), IsMultiDiscDirectory: true},
+		},
+	}
+
+	if err := SaveCollection(collection); err != nil {
+		t.Fatalf("SaveCollection: %v", err)
+	}
+
+	want := "/Roms/Game Boy (GB)/Tetris.gb\n" +
+		"/Roms/Game Boy (GB)/Dr. Mario.gb\n" +
+		"/Roms/PlayStation (PS)/Puzzle Pack/Puzzle Pack.m3u\n"
+	if got := readTestFile(t, collection.CollectionFile); got != want {
+		t.Errorf("collection file:\n%s\nwant:\n%s", got, want)
+	}
+
+	read, err := ReadCollection(models.Collection{CollectionFile: collection.CollectionFile})
+	if err != nil {
+		t.Fatalf("ReadCollection: %v", err)
+	}
+	if len(read.Games) != 3 || read.Games[0].DisplayName != "Tetris" || read.Games[0].Path != "/Roms/Game Boy (GB)/Tetris.gb" {
+		t.Errorf("ReadCollection returned %+v", read.Games)
+	}
+}
+
+func TestAddCollectionGames(t *testing.T) {
+	useFakeSDCard(t)
+
+	collectionFile := filepath.Join(testCollections, "Puzzle.txt")
+	writeTestFile(t, collectionFile, "/Roms/Game Boy (GB)/Tetris.gb\n")
+
+	collection, err := AddCollectionGames(models.Collection{DisplayName: "Puzzle", CollectionFile: collectionFile}, []shared.Item{
+		{DisplayName: "Tetris", Path: filepath.Join(testPlatform, "Tetris.gb")},
+		{DisplayName: "Dr. Mario", Path: filepath.Join(testPlatform, "Dr. Mario.gb")},
+	})
+	if err != nil {
+		t.Fatalf("AddCollectionGames: %v", err)
+	}
+
+	if len(collection.Games) != 2 {
+		t.Errorf("collection has %d games, want 2", len(collection.Games))
+	}
+	if got, want := readTestFile(t, collectionFile), "/Roms/Game Boy (GB)/Tetris.gb\n/Roms/Game Boy (GB)/Dr. Mario.gb\n"; got != want {
+		t.Errorf("collection file:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestAddCollectionGamesNamesPlaylistsAfterTheFolder(t *testing.T) {
+	useFakeSDCard(t)
+
+	folder := filepath.Join(testRoms, "PlayStation (PS)", "Final Fantasy VII (USA)")
+	writeTestFile(t, filepath.Join(folder, "Final Fantasy VII (USA).m3u"), "")
+	game, err := ItemFromPath(folder)
+	if err != nil {
+		t.Fatalf("ItemFromPath: %v", err)
+	}
+	if !game.IsMultiDiscDirectory || game.DisplayName != "Final Fantasy VII" {
+		t.Fatalf("ItemFromPath = %+v", game)
+	}
+
+	collectionFile := filepath.Join(testCollections, "RPG.txt")
+	if _, err := AddCollectionGames(models.Collection{DisplayName: "RPG", CollectionFile: collectionFile}, []shared.Item{game}); err != nil {
+		t.Fatalf("AddCollectionGames: %v", err)
+	}
+
+	if got, want := readTestFile(t, collectionFile), "/Roms/PlayStation (PS)/Final Fantasy VII (USA)/Final Fantasy VII (USA).m3u\n"; got != want {
+		t.Errorf("collection file:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRenamedGamesKeepWhenTheyWereAdded(t *testing.T) {
+	useFakeSDCard(t)
+
+	collectionFile := filepath.Join(testCollections, "Puzzle.txt")
+	writeTestFile(t, filepath.Join(testPlatform, "Tetris.gb"), "rom")
+	writeTestFile(t, collectionFile, "/Roms/Game Boy (GB)/Tetris.gb\n/Roms/Game Boy (GB)/Dr. Mario.gb\n")
+
+	collection := models.Collection{DisplayName: "Puzzle", CollectionFile: collectionFile}
+	if _, err := SortCollection(collection, "date_added", true); err != nil {
+		t.Fatalf("SortCollection: %v", err)
+	}
+
+	orders, err := loadCollectionOrders()
+	if err != nil {
+		t.Fatalf("loadCollectionOrders: %v", err)
+	}
+	addedAt := time.Now().AddDate(0, -1, 0).Truncate(time.Second)
+	orders["Puzzle.txt"].Added["/Roms/Game Boy (GB)/Tetris.gb"] = addedAt
+	if err := saveCollectionOrders(orders); err != nil {
+		t.Fatalf("backdating collection order: %v", err)
+	}
+
+	game := shared.Item{DisplayName: "Tetris", Filename: "Tetris.gb", Path: filepath.Join(testPlatform, "Tetris.gb")}
+	if _, err := RenameRom(game, "Tetris DX", testRomDirectory); err != nil {
+		t.Fatalf("RenameRom: %v", err)
+	}
+
+	want := "/Roms/Game Boy (GB)/Dr. Mario.gb\n/Roms/Game Boy (GB)/Tetris DX.gb\n"
+	if got := readTestFile(t, collectionFile); got != want {
+		t.Errorf("collection after rename:\n%s\nwant:\n%s", got, want)
+	}
+
+	read, err := ReadCollection(collection)
+	if err != nil {
+		t.Fatalf("ReadCollection: %v", err)
+	}
+	if err := SaveCollection(read); err != nil {
+		t.Fatalf("SaveCollection: %v", err)
+	}
+	if got := readTestFile(t, collectionFile); got != want {
+		t.Errorf("collection after saving:\n%s\nwant:\n%s", got, want)
+	}
+
+	orders, _ = loadCollectionOrders()
+	if got := orders["Puzzle.txt"].Added["/Roms/Game Boy (GB)/Tetris DX.gb"]; !got.Equal(addedAt) {
+		t.Errorf("renamed game was added at %v, want %v", got, addedAt)
+	}
+}
@@ -33,6 +33,10 @@ func SaveConfig(config *models.Config) error {
 	viper.Set("log_level", config.LogLevel)
 	viper.Set("play_history_show_collections", config.PlayHistoryShowCollections)
 	viper.Set("play_history_show_archives", config.PlayHistoryShowArchives)
+	viper.Set("trash_retention_days", config.TrashRetentionDays)
+	viper.Set("compress_archives", config.CompressArchives)
+	viper.Set("archive_locations", config.ArchiveLocations)
+	viper.Set("game_list_sort", config.GameListSort)
 
 
 	return viper.WriteConfigAs(configFile)
@@ -1,16 +1,21 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
 	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"github.com/spf13/afero"
 	"go.uber.org/zap"
+	"io"
+	"nextui-game-manager/models"
 	"os"
 	"path/filepath"
+	"syscall"
 )
 
-func GetFileList(dirPath string) ([]os.DirEntry, error) {
-	entries, err := os.ReadDir(dirPath)
+func GetFileList(dirPath string) ([]os.FileInfo, error) {
+	entries, err := afero.ReadDir(fileSystem, dirPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read directory %s: %w", dirPath, err)
 	}
@@ -18,13 +23,13 @@ func GetFileList(dirPath string) ([]os.DirEntry, error) {
 }
 
 func DoesFileExists(path string) bool {
-	_, err := os.Stat(path)
-	return !os.IsNotExist(err)
+	_, err := fileSystem.Stat(path)
+	return err == nil
 }
 
 func EnsureDirectoryExists(dirPath string) error {
-	if _, err := os.Stat(dirPath); os.IsNotExist(err) {
-		return os.MkdirAll(dirPath, defaultDirPerm)
+	if _, err := fileSystem.Stat(dirPath); os.IsNotExist(err) {
+		return fileSystem.MkdirAll(dirPath, defaultDirPerm)
 	}
 	return nil
 }
@@ -37,7 +42,13 @@ func MoveFile(sourcePath, destinationPath string) error {
 		return fmt.Errorf("failed to create destination directory: %w", err)
 	}
 
-	if err := os.Rename(sourcePath, destinationPath); err != nil {
+	err := fileSystem.Rename(sourcePath, destinationPath)
+	if errors.Is(err, syscall.EXDEV) {
+		// Archives can live on another card or a USB drive, which a rename cannot reach
+		err = copyAndRemove(sourcePath, destinationPath)
+	}
+
+	if err != nil {
 		logger.Error("Failed to move file", zap.String("from", sourcePath), zap.String("to", destinationPath), zap.Error(err))
 		return fmt.Errorf("failed to move file from %s to %s: %w", sourcePath, destinationPath, err)
 	}
@@ -45,14 +56,79 @@ func MoveFile(sourcePath, destinationPath string) error {
 	return nil
 }
 
-func DeleteRom(game shared.Item, romDirectory shared.RomDirectory) {
-	romPath := filepath.Join(romDirectory.Path, game.Filename)
-	if common.DeleteFile(romPath) {
-		DeleteArt(game.Filename, romDirectory)
+// copyAndRemove moves a file or folder between filesystems. The source is only removed once everything is copied, and
+// a partial copy is cleaned up.
+func copyAndRemove(sourcePath, destinationPath string) error {
+	if DoesFileExists(destinationPath) {
+		return fmt.Errorf("%s already exists", destinationPath)
+	}
+
+	err := afero.Walk(fileSystem, sourcePath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(sourcePath, path)
+		if err != nil {
+			return err
+		}
+		targetPath := filepath.Join(destinationPath, relativePath)
+
+		if info.IsDir() {
+			return fileSystem.MkdirAll(targetPath, info.Mode().Perm())
+		}
+		return copyFile(path, targetPath, info.Mode().Perm())
+	})
+	if err != nil {
+		_ = fileSystem.RemoveAll(destinationPath)
+		return err
+	}
+
+	return fileSystem.RemoveAll(sourcePath)
+}
+
+func copyFile(sourcePath, destinationPath string, perm os.FileMode) error {
+	source, err := fileSystem.Open(sourcePath)
+	if err != nil {
+		return err
 	}
+	defer source.Close()
+
+	destination, err := fileSystem.OpenFile(destinationPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	defer destination.Close()
+
+	if _, err := io.Copy(destination, source); err != nil {
+		return err
+	}
+	return destination.Sync()
+}
+
+// DeleteRom moves the ROM and its art to the trash together, returning the entry so the deletion can be undone.
+func DeleteRom(game shared.Item, romDirectory shared.RomDirectory) (models.TrashEntry, error) {
+	logger := common.GetLoggerInstance()
+
+	paths := []string{filepath.Join(romDirectory.Path, game.Filename)}
+	if artPath, err := FindExistingArt(game.Filename, romDirectory); err == nil && artPath != "" {
+		paths = append(paths, artPath)
+	}
+
+	entry, err := MoveToTrash(models.TrashKindRom, game.DisplayName, paths...)
+	if err != nil {
+		logger.Error("Failed to delete ROM", zap.String("rom", game.Filename), zap.Error(err))
+		return entry, err
+	}
+
+	publishLibraryChange(models.LibraryChange{
+		Kind: models.LibraryChangeKinds.RomDeleted,
+		Path: paths[0],
+	})
+	return entry, nil
 }
 
-func Nuke(game shared.Item, romDirectory shared.RomDirectory) {
+func Nuke(game shared.Item, romDirectory shared.RomDirectory) (models.TrashEntry, error) {
 	ClearGameTracker(game.Filename, romDirectory)
-	DeleteRom(game, romDirectory)
+	return DeleteRom(game, romDirectory)
 }
@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"github.com/spf13/afero"
+	"nextui-game-manager/models"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	fileSystem afero.Fs = afero.NewOsFs()
+	layout     *models.Layout
+)
+
+var itemTagPattern = regexp.MustCompile(`\([^()]*\)$`)
+
+// SetFileSystem swaps the filesystem every library operation runs against, e.g. an afero.MemMapFs holding a fake SD card.
+func SetFileSystem(fs afero.Fs) {
+	fileSystem = fs
+}
+
+func GetFileSystem() afero.Fs {
+	return fileSystem
+}
+
+// SetLayout overrides the SD card paths that would otherwise come from the device defaults or the DEV environment.
+func SetLayout(newLayout models.Layout) {
+	layout = &newLayout
+}
+
+func GetLayout() models.Layout {
+	if layout == nil {
+		defaults := defaultLayout()
+		layout = &defaults
+	}
+	return *layout
+}
+
+// pakDirectory is the folder the game manager binary sits in, which is where the pak keeps its own state.
+func pakDirectory() string {
+	executable, err := os.Executable()
+	if err != nil {
+		return "."
+	}
+	return filepath.Dir(executable)
+}
+
+func defaultLayout() models.Layout {
+	if IsDev() {
+		return models.Layout{
+			RomDirectory:           os.Getenv("ROM_DIRECTORY"),
+			CollectionDirectory:    os.Getenv("COLLECTION_DIRECTORY"),
+			SaveFileDirectory:      os.Getenv("SAVE_FILE_DIRECTORY"),
+			UserDataDirectory:      os.Getenv("USER_DATA_DIRECTORY"),
+			GameTrackerDBPath:      os.Getenv("GAME_TRACKER_DB_PATH"),
+			RecentlyPlayedFile:     os.Getenv("RECENTLY_PLAYED_FILE"),
+			TrashDirectory:         os.Getenv("TRASH_DIRECTORY"),
+			LibraryIndexPath:       os.Getenv("LIBRARY_INDEX_PATH"),
+			ExportDirectory:        os.Getenv("EXPORT_DIRECTORY"),
+			OperationJournalPath:   devStatePath("OPERATION_JOURNAL_PATH", operationJournalFile),
+			UndoHistoryPath:        devStatePath("UNDO_HISTORY_PATH", undoHistoryFile),
+			ArchivedReferencesPath: devStatePath("ARCHIVED_REFERENCES_PATH", archivedReferencesFile),
+			CollectionOrderPath:    devStatePath("COLLECTION_ORDER_PATH", collectionOrderFile),
+			RecentlyPlayedPinsPath: devStatePath("RECENTLY_PLAYED_PINS_PATH", recentlyPlayedPinsFile),
+		}
+	}
+
+	return models.Layout{
+		RomDirectory:           common.RomDirectory,
+		CollectionDirectory:    common.CollectionDirectory,
+		SaveFileDirectory:      saveFileDirectory,
+		UserDataDirectory:      userDataDirectory,
+		GameTrackerDBPath:      gameTrackerDBPath,
+		RecentlyPlayedFile:     recentlyPlayedFile,
+		TrashDirectory:         trashDirectory,
+		LibraryIndexPath:       filepath.Join(pakDirectory(), libraryIndexFile),
+		ExportDirectory:        exportDirectory,
+		OperationJournalPath:   filepath.Join(pakDirectory(), operationJournalFile),
+		UndoHistoryPath:        filepath.Join(pakDirectory(), undoHistoryFile),
+		ArchivedReferencesPath: filepath.Join(pakDirectory(), archivedReferencesFile),
+		CollectionOrderPath:    filepath.Join(pakDirectory(), collectionOrderFile),
+		RecentlyPlayedPinsPath: filepath.Join(pakDirectory(), recentlyPlayedPinsFile),
+	}
+}
+
+// devStatePath reads where a file of the pak's own state lives from the environment, falling back to the working
+// directory so development runs keep working without it.
+func devStatePath(variable string, file string) string {
+	if path := os.Getenv(variable); path != "" {
+		return path
+	}
+	return file
+}
+
+// listDirectoryItems mirrors filebrowser.CWD on top of the injected filesystem.
+func listDirectoryItems(dirPath string) ([]shared.Item, error) {
+	entries, err := GetFileList(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []shared.Item
+	for _, entry := range entries {
+		items = append(items, buildItem(dirPath, entry))
+	}
+
+	slices.SortFunc(items, func(a, b shared.Item) int {
+		return strings.Compare(strings.ToLower(a.Filename), strings.ToLower(b.Filename))
+	})
+
+	return items, nil
+}
+
+// listDirectoryItemsRecursive mirrors filebrowser.CWDDepth with unlimited depth.
+func listDirectoryItemsRecursive(dirPath string) ([]shared.Item, error) {
+	items, err := listDirectoryItems(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var allItems []shared.Item
+	for _, item := range items {
+		allItems = append(allItems, item)
+
+		if item.IsDirectory {
+			children, err := listDirectoryItemsRecursive(item.Path)
+			if err != nil {
+				return nil, err
+			}
+			allItems = append(allItems, children...)
+		}
+	}
+
+	return allItems, nil
+}
+
+// ItemFromPath builds the item the game lists show for the file or folder at path.
+func ItemFromPath(path string) (shared.Item, error) {
+	info, err := fileSystem.Stat(path)
+	if err != nil {
+		return shared.Item{}, err
+	}
+	return buildItem(filepath.Dir(path), info), nil
+}
+
+func buildItem(dirPath string, entry os.FileInfo) shared.Item {
+	name := entry.Name()
+	itemPath := filepath.Join(dirPath, name)
+
+	// Compressed archived games are named after the game they hold
+	baseName := removeFileExtension(UncompressedName(name))
+
+	item := shared.Item{
+		DisplayName: baseName,
+		Filename:    name,
+		Path:        itemPath,
+		Tag:         itemTagPattern.FindString(baseName),
+		IsDirectory: entry.IsDir(),
+	}
+
+	if !entry.IsDir() {
+		return item
+	}
+
+	item.DisplayName = name
+	if item.Tag != "" {
+		item.DisplayName = strings.TrimSpace(strings.TrimSuffix(name, item.Tag))
+	}
+
+	children, err := GetFileList(itemPath)
+	if err != nil {
+		return item
+	}
+
+	for _, child := range children {
+		if child.IsDir() {
+			continue
+		}
+
+		if strings.EqualFold(filepath.Ext(child.Name()), ".m3u") {
+			item.IsMultiDiscDirectory = true
+		} else if removeFileExtension(child.Name()) == name {
+			item.IsSelfContainedDirectory = true
+		}
+	}
+
+	if item.IsMultiDiscDirectory {
+		item.IsSelfContainedDirectory = false
+	}
+
+	return item
+}
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"github.com/spf13/afero"
+	"nextui-game-manager/models"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	testSDCard      = "/mnt/SDCARD"
+	testRoms        = testSDCard + "/Roms"
+	testPlatform    = testRoms + "/Game Boy (GB)"
+	testCollections = testSDCard + "/Collections"
+	testSaves       = testSDCard + "/Saves"
+	testUserData    = testSDCard + "/.userdata/shared"
+	testRecents     = testUserData + "/.minui/recent.txt"
+	testTrash       = testSDCard + "/.trash"
+	testPak         = testSDCard + "/Tools/tg5040/Game Manager.pak"
+)
+
+var testRomDirectory = shared.RomDirectory{DisplayName: "Game Boy", Tag: "(GB)", Path: testPlatform}
+
+// useFakeSDCard points every library operation at an empty in-memory SD card for the length of a test. The game
+// tracker and library index are left unset, as both are SQLite databases that only live on a real disk.
+func useFakeSDCard(t *testing.T) afero.Fs {
+	t.Helper()
+
+	previousFileSystem, previousLayout, previousLocations := fileSystem, layout, archiveLocations
+	t.Cleanup(func() {
+		fileSystem, layout, archiveLocations = previousFileSystem, previousLayout, previousLocations
+		forgetArchivedGameHomes()
+	})
+
+	fs := afero.NewMemMapFs()
+	SetFileSystem(fs)
+	SetLayout(models.Layout{
+		RomDirectory:           testRoms,
+		CollectionDirectory:    testCollections,
+		SaveFileDirectory:      testSaves,
+		UserDataDirectory:      testUserData,
+		RecentlyPlayedFile:     testRecents,
+		TrashDirectory:         testTrash,
+		OperationJournalPath:   filepath.Join(testPak, operationJournalFile),
+		UndoHistoryPath:        filepath.Join(testPak, undoHistoryFile),
+		ArchivedReferencesPath: filepath.Join(testPak, archivedReferencesFile),
+		CollectionOrderPath:    filepath.Join(testPak, collectionOrderFile),
+		RecentlyPlayedPinsPath: filepath.Join(testPak, recentlyPlayedPinsFile),
+	})
+	SetArchiveLocations(nil)
+	forgetArchivedGameHomes()
+
+	return fs
+}
+
+func writeTestFile(t *testing.T, path string, contents string) {
+	t.Helper()
+
+	if err := fileSystem.MkdirAll(filepath.Dir(path), defaultDirPerm); err != nil {
+		t.Fatalf("creating %s: %v", filepath.Dir(path), err)
+	}
+	if err := afero.WriteFile(fileSystem, path, []byte(contents), defaultFilePerm); err != nil {
+		t.Fatalf("writing %s: %v", path, err)
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := afero.ReadFile(fileSystem, path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func assertExists(t *testing.T, paths ...string) {
+	t.Helper()
+
+	for _, path := range paths {
+		if !DoesFileExists(path) {
+			t.Errorf("expected %s to exist", path)
+		}
+	}
+}
+
+func assertMissing(t *testing.T, paths ...string) {
+	t.Helper()
+
+	for _, path := range paths {
+		if DoesFileExists(path) {
+			t.Errorf("expected %s to be gone", path)
+		}
+	}
+}
+
+func TestSetFileSystem(t *testing.T) {
+	fs := useFakeSDCard(t)
+
+	if GetFileSystem() != fs {
+		t.Fatal("GetFileSystem does not return the injected filesystem")
+	}
+	if GetRomDirectory() != testRoms || GetTrashDirectory() != testTrash {
+		t.Fatalf("layout not applied: %+v", GetLayout())
+	}
+
+	writeTestFile(t, filepath.Join(testPlatform, "Tetris.gb"), "rom")
+	assertExists(t, filepath.Join(testPlatform, "Tetris.gb"))
+
+	if exists, _ := afero.Exists(afero.NewOsFs(), filepath.Join(testPlatform, "Tetris.gb")); exists {
+		t.Fatal("file was written to the real disk")
+	}
+}
@@ -2,8 +2,6 @@ package utils
 
 import (
 	"fmt"
-	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
-	"github.com/UncleJunVIP/nextui-pak-shared-functions/filebrowser"
 	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
 	_ "github.com/mattn/go-sqlite3"
 	"os"
@@ -15,7 +13,11 @@ import (
 const (
 	gameTrackerDBPath  = "/mnt/SDCARD/.userdata/shared/game_logs.sqlite"
 	saveFileDirectory  = "/mnt/SDCARD/Saves/"
-	RecentlyPlayedFile = "/mnt/SDCARD/.userdata/shared/.minui/recent.txt"
+	userDataDirectory  = "/mnt/SDCARD/.userdata/shared"
+	recentlyPlayedFile = "/mnt/SDCARD/.userdata/shared/.minui/recent.txt"
+	trashDirectory     = "/mnt/SDCARD/.trash"
+	libraryIndexFile   = "library_index.sqlite"
+	exportDirectory    = "/mnt/SDCARD/Exports"
 	defaultDirPerm     = 0755
 	defaultFilePerm    = 0644
 )
@@ -25,13 +27,16 @@ func IsDev() bool {
 }
 
 func GetRomDirectory() string {
-	if IsDev() {
-		return os.Getenv("ROM_DIRECTORY")
-	}
-	return common.RomDirectory
+	return GetLayout().RomDirectory
 }
 
+// GetArchiveRoot returns the folder of an archive. Archives in the ROM directory are known by their hidden folder name
+// and archives kept elsewhere by their full path.
 func GetArchiveRoot(archiveName string) string {
+	if filepath.IsAbs(archiveName) {
+		return archiveName
+	}
+
 	if !strings.HasPrefix(archiveName, ".") {
 		archiveName = "." + archiveName
 	}
@@ -40,27 +45,59 @@ func GetArchiveRoot(archiveName string) string {
 }
 
 func GetCollectionDirectory() string {
-	dir := common.CollectionDirectory
-	if IsDev() {
-		dir = os.Getenv("COLLECTION_DIRECTORY")
-	}
+	dir := GetLayout().CollectionDirectory
 
 	_ = EnsureDirectoryExists(dir)
 	return dir
 }
 
 func GetSaveFileDirectory() string {
-	if IsDev() {
-		return os.Getenv("SAVE_FILE_DIRECTORY")
-	}
-	return saveFileDirectory
+	return GetLayout().SaveFileDirectory
+}
+
+// GetUserDataDirectory is where the emulators keep save states and per-game settings, in a folder per platform and core.
+func GetUserDataDirectory() string {
+	return GetLayout().UserDataDirectory
 }
 
 func GetGameTrackerDBPath() string {
-	if IsDev() {
-		return os.Getenv("GAME_TRACKER_DB_PATH")
-	}
-	return gameTrackerDBPath
+	return GetLayout().GameTrackerDBPath
+}
+
+func GetRecentlyPlayedFile() string {
+	return GetLayout().RecentlyPlayedFile
+}
+
+func GetTrashDirectory() string {
+	return GetLayout().TrashDirectory
+}
+
+func GetLibraryIndexPath() string {
+	return GetLayout().LibraryIndexPath
+}
+
+func GetOperationJournalPath() string {
+	return GetLayout().OperationJournalPath
+}
+
+func GetUndoHistoryPath() string {
+	return GetLayout().UndoHistoryPath
+}
+
+func GetArchivedReferencesPath() string {
+	return GetLayout().ArchivedReferencesPath
+}
+
+func GetCollectionOrderPath() string {
+	return GetLayout().CollectionOrderPath
+}
+
+func GetRecentlyPlayedPinsPath() string {
+	return GetLayout().RecentlyPlayedPinsPath
+}
+
+func GetExportDirectory() string {
+	return GetLayout().ExportDirectory
 }
 
 func CreateRomDirectoryFromItem(item shared.Item) shared.RomDirectory {
@@ -71,6 +108,27 @@ func CreateRomDirectoryFromItem(item shared.Item) shared.RomDirectory {
 	}
 }
 
+// RomDirectoryForItem returns the folder an item sits in, tagged with its platform. Lists that mix folders, such as
+// global search results, use it in place of their own RomDirectory.
+func RomDirectoryForItem(item shared.Item, romDirectory shared.RomDirectory) shared.RomDirectory {
+	dirPath := filepath.Dir(item.Path)
+	if item.Path == "" || dirPath == romDirectory.Path {
+		return romDirectory
+	}
+
+	platformPath, _ := indexScope(item.Path)
+	platform := platformDirectory(platformPath)
+	if dirPath == platform.Path {
+		return platform
+	}
+
+	return shared.RomDirectory{
+		DisplayName: filepath.Base(dirPath),
+		Tag:         platform.Tag,
+		Path:        dirPath,
+	}
+}
+
 func FilterList(itemList []shared.Item, keywords ...string) []shared.Item {
 	if len(keywords) == 0 {
 		return itemList
@@ -109,8 +167,7 @@ func InsertIntoSlice(slice []string, index int, values ...string) []string {
 func getRomFilesRecursive(dirPath string) ([]shared.Item, error) {
 	var romFiles []shared.Item
 
-	fb := filebrowser.NewFileBrowser(common.GetLoggerInstance())
-	err := fb.CWDDepth(dirPath, true, -1)
+	items, err := listDirectoryItemsRecursive(dirPath)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get rom files: %w", err)
@@ -118,7 +175,7 @@ func getRomFilesRecursive(dirPath string) ([]shared.Item, error) {
 
 	var selfContainedPaths []string
 
-	for _, item := range fb.Items {
+	for _, item := range items {
 		if strings.Contains(item.Path, ".media") {
 			continue
 		}
@@ -159,3 +216,19 @@ func cleanTag(tag string) string {
 func removeFileExtension(filename string) string {
 	return strings.TrimSuffix(filename, filepath.Ext(filename))
 }
+
+// FormatSize renders a byte count the way the rest of the UI shows sizes, e.g. "1.4 GB".
+func FormatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGT"[exp])
+}
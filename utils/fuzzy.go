@@ -0,0 +1,265 @@
+package utils
+
+import (
+	"cmp"
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"nextui-game-manager/models"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+var searchTagPattern = regexp.MustCompile(`\([^)]*\)|\[[^\]]*\]`)
+
+var searchArticles = map[string]bool{"the": true, "a": true, "an": true}
+
+// titleSimilarity scores two normalized titles between 0 and 1 by combining the Jaccard similarity of their words with
+// how many characters of the main title line up in order. Art matching uses it, so words only count as shared when
+// they are the same, which keeps sequels and spin-offs such as Zelda II and Zelda III apart.
+func titleSimilarity(s1, s2 string) float64 {
+	return weighTitles(s1, s2, sameWord)
+}
+
+// searchSimilarity is titleSimilarity for what was typed into a search, where a word that matches allowing for a typo
+// or an unfinished word counts as shared, see tokenSimilarity.
+func searchSimilarity(query, name string) float64 {
+	return weighTitles(query, name, tokenSimilarity)
+}
+
+func weighTitles(s1, s2 string, wordMatch func(word1 string, word2 string) float64) float64 {
+	// Also consider character-level similarity for the main title
+	title1 := strings.Split(s1, "(")[0]
+	title2 := strings.Split(s2, "(")[0]
+
+	// Weighted combination of token and character similarity
+	return wordSimilarity(strings.Fields(s1), strings.Fields(s2), wordMatch)*0.6 + characterSimilarity(title1, title2)*0.4
+}
+
+func sameWord(word1 string, word2 string) float64 {
+	if word1 == word2 {
+		return 1
+	}
+	return 0
+}
+
+// wordSimilarity is the Jaccard similarity of two sets of words, each word of the first counting as shared as far as
+// wordMatch matches it with its closest word in the second.
+func wordSimilarity(words1 []string, words2 []string, wordMatch func(word1 string, word2 string) float64) float64 {
+	words1 = slices.Compact(slices.Sorted(slices.Values(words1)))
+	words2 = slices.Compact(slices.Sorted(slices.Values(words2)))
+
+	// Calculate intersection and union
+	intersection := 0.0
+	for _, word1 := range words1 {
+		best := 0.0
+		for _, word2 := range words2 {
+			best = max(best, wordMatch(word1, word2))
+		}
+		intersection += best
+	}
+	intersection = min(intersection, float64(min(len(words1), len(words2))))
+
+	union := float64(len(words1)+len(words2)) - intersection
+	if union == 0 {
+		return 0
+	}
+	return intersection / union
+}
+
+// characterSimilarity is the share of characters of the longer title that line up in order with the other one.
+func characterSimilarity(title1, title2 string) float64 {
+	if len(title1) == 0 || len(title2) == 0 {
+		return 0
+	}
+
+	matches := 0
+	maxLen := max(len(title1), len(title2))
+
+	// Count matching characters in order
+	j := 0
+	for i := 0; i < len(title1) && j < len(title2); i++ {
+		if title1[i] == title2[j] {
+			matches++
+			j++
+		} else {
+			// Look ahead for match
+			for k := j + 1; k < len(title2) && k-j < 3; k++ {
+				if title1[i] == title2[k] {
+					j = k + 1
+					matches++
+					break
+				}
+			}
+		}
+	}
+
+	return float64(matches) / float64(maxLen)
+}
+
+// FuzzyScore rates how well a ROM or game name matches what was typed, from 0 for no match to 1 for an exact one. Both
+// sides are split into words with tags such as (USA) or [!] and articles dropped, every typed word must match a word of
+// the name, allowing for a typo or two and for unfinished words, and names that do are ranked by searchSimilarity.
+func FuzzyScore(query string, name string) float64 {
+	queryTokens := searchTokens(query)
+	if len(queryTokens) == 0 {
+		return 1
+	}
+
+	nameTokens := searchTokens(name)
+
+	// Anything the old substring search found still matches, just not as well as a match on whole words
+	substring := 0.0
+	if strings.Contains(strings.ToLower(name), strings.ToLower(strings.TrimSpace(query))) {
+		substring = 0.5
+	}
+
+	for _, queryToken := range queryTokens {
+		if !slices.ContainsFunc(nameTokens, func(nameToken string) bool { return tokenSimilarity(queryToken, nameToken) > 0 }) {
+			return substring
+		}
+	}
+
+	return 0.5 + searchSimilarity(strings.Join(queryTokens, " "), strings.Join(nameTokens, " "))*0.5
+}
+
+// searchTokens lowercases a name and splits it into words, leaving out tags in brackets and articles. Names made of
+// nothing but articles keep them so they can still be searched for.
+func searchTokens(name string) []string {
+	name = strings.ToLower(searchTagPattern.ReplaceAllString(name, " "))
+
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	var tokens []string
+	for _, word := range words {
+		if !searchArticles[word] && !slices.Contains(tokens, word) {
+			tokens = append(tokens, word)
+		}
+	}
+
+	if len(tokens) == 0 {
+		return slices.Compact(words)
+	}
+
+	return tokens
+}
+
+// tokenSimilarity compares a typed word with a word from a name. Short words must match exactly, longer ones may be a
+// prefix of the name's word or be off by one typo, two from eight letters on.
+func tokenSimilarity(queryToken string, nameToken string) float64 {
+	if queryToken == nameToken {
+		return 1
+	}
+
+	query := []rune(queryToken)
+	name := []rune(nameToken)
+
+	if len(query) >= 3 && strings.HasPrefix(nameToken, queryToken) {
+		return 0.9
+	}
+
+	allowed := 0
+	switch {
+	case len(query) >= 8:
+		allowed = 2
+	case len(query) >= 4:
+		allowed = 1
+	}
+
+	if allowed == 0 {
+		return 0
+	}
+
+	distance := editDistance(query, name)
+	if distance > allowed {
+		return 0
+	}
+
+	return 1 - float64(distance)/float64(max(len(query), len(name)))
+}
+
+// editDistance counts the insertions, deletions, substitutions and swaps of neighbouring letters that turn a into b.
+func editDistance(a []rune, b []rune) int {
+	previousPrevious := make([]int, len(b)+1)
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				current[j] = min(current[j], previousPrevious[j-2]+1)
+			}
+		}
+
+		previousPrevious, previous, current = previous, current, previousPrevious
+	}
+
+	return previous[len(b)]
+}
+
+// rankByFuzzyScore keeps the values whose name matches the query, best match first. Equal scores keep their order.
+func rankByFuzzyScore[T any](values []T, query string, name func(T) string) []T {
+	type scored struct {
+		value T
+		score float64
+	}
+
+	var matches []scored
+	for _, value := range values {
+		if score := FuzzyScore(query, name(value)); score > 0 {
+			matches = append(matches, scored{value, score})
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b scored) int {
+		return cmp.Compare(b.score, a.score)
+	})
+
+	ranked := make([]T, 0, len(matches))
+	for _, match := range matches {
+		ranked = append(ranked, match.value)
+	}
+	return ranked
+}
+
+// FuzzyFilterList is FilterList with typo tolerance, ordered by how well each item matches.
+func FuzzyFilterList(itemList []shared.Item, query string) []shared.Item {
+	if strings.TrimSpace(query) == "" {
+		return itemList
+	}
+
+	return rankByFuzzyScore(itemList, query, searchName)
+}
+
+// FuzzyFilterPlayList keeps the games whose name matches the query, ordered by how well they match.
+func FuzzyFilterPlayList(itemList []models.PlayHistoryAggregate, query string) []models.PlayHistoryAggregate {
+	if strings.TrimSpace(query) == "" {
+		return itemList
+	}
+
+	return rankByFuzzyScore(itemList, query, func(item models.PlayHistoryAggregate) string {
+		return item.Name
+	})
+}
+
+// searchName is the name an item is searched by, its display name without the extension when there is one.
+func searchName(item shared.Item) string {
+	if item.DisplayName != "" {
+		return item.DisplayName
+	}
+	return removeFileExtension(item.Filename)
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"testing"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	for _, test := range []struct {
+		query, name string
+		matches     bool
+	}{
+		{"tetris", "Tetris (World) (Rev 1)", true},
+		{"the legend of zelda", "Legend of Zelda, The - Link's Awakening (USA)", true},
+		{"zelad", "Legend of Zelda, The (USA)", true},
+		{"pokem", "Pokemon - Red Version (USA, Europe)", true},
+		{"mario kart", "Super Mario Land (World)", false},
+		{"zx", "Zelda (USA)", false},
+	} {
+		if score := FuzzyScore(test.query, test.name); (score > 0) != test.matches {
+			t.Errorf("FuzzyScore(%q, %q) = %v, want a match: %v", test.query, test.name, score, test.matches)
+		}
+	}
+
+	if FuzzyScore("tetris", "Tetris") != 1 {
+		t.Errorf("an exact match scores %v", FuzzyScore("tetris", "Tetris"))
+	}
+}
+
+func TestFuzzyFilterListRanksCloserTitlesFirst(t *testing.T) {
+	items := []shared.Item{
+		{DisplayName: "Super Mario Bros. 3"},
+		{DisplayName: "Dr. Mario"},
+		{DisplayName: "Mario"},
+		{DisplayName: "Tetris"},
+	}
+
+	ranked := FuzzyFilterList(items, "mario")
+	if len(ranked) != 3 {
+		t.Fatalf("FuzzyFilterList kept %+v", ranked)
+	}
+	if ranked[0].DisplayName != "Mario" || ranked[2].DisplayName != "Super Mario Bros. 3" {
+		t.Errorf("FuzzyFilterList ranked %+v", ranked)
+	}
+}
+
+func TestFuzzyArtSearchMatchesRegionVariants(t *testing.T) {
+	artList := []shared.Item{
+		{Filename: "Pokemon - Blue Version (USA, Europe).png"},
+		{Filename: "Pokemon - Red Version (USA, Europe).png"},
+	}
+
+	match, ok := fuzzyArtSearch("Pokemon - Red Version (USA)", artList, 0.8)
+	if !ok || match != "Pokemon - Red Version (USA, Europe).png" {
+		t.Errorf("fuzzyArtSearch = %q, %v", match, ok)
+	}
+}
+
+func TestFuzzyArtSearchRejectsNearMissTitles(t *testing.T) {
+	tests := []struct {
+		rom string
+		art string
+	}{
+		{"Mario Kart (USA)", "Mario Party (USA).png"},
+		{"Zelda III (USA)", "Zelda II (USA).png"},
+		{"Zelda II (USA)", "Zelda III (USA).png"},
+	}
+
+	for _, tt := range tests {
+		if match, ok := fuzzyArtSearch(tt.rom, []shared.Item{{Filename: tt.art}}, 0.8); ok {
+			t.Errorf("fuzzyArtSearch(%q) matched %q", tt.rom, match)
+		}
+	}
+}
+
+func TestSearchSimilarityToleratesTyposUnlikeTitleSimilarity(t *testing.T) {
+	if titleSimilarity("zelda lnik", "zelda link") >= searchSimilarity("zelda lnik", "zelda link") {
+		t.Error("titleSimilarity counted a misspelt word as shared")
+	}
+
+	closer := FuzzyScore("pokemon red", "Pokemon - Red Version (USA, Europe)")
+	further := FuzzyScore("pokemon red", "Pokemon Mystery Dungeon - Red Rescue Team (USA, Australia)")
+	if closer <= further {
+		t.Errorf("FuzzyScore ranked the closer title %v <= %v", closer, further)
+	}
+}
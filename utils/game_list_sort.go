@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"cmp"
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"nextui-game-manager/models"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// GetGameListSort returns the sort order saved for the platform a directory belongs to, name if there is none. Keys
+// are lowercase as viper lowercases them when writing config.yml.
+func GetGameListSort(config *models.Config, dirPath string) string {
+	if sortKey, ok := config.GameListSort[gameListSortPlatform(dirPath)]; ok {
+		if _, valid := models.GameListSortMap[sortKey]; valid {
+			return sortKey
+		}
+	}
+	return models.GameListSortKeys[0]
+}
+
+// SetGameListSort remembers the sort order for the platform a directory belongs to. It reports whether the order
+// changed, in which case the caller saves the config.
+func SetGameListSort(config *models.Config, dirPath string, sortKey string) bool {
+	if _, valid := models.GameListSortMap[sortKey]; !valid || GetGameListSort(config, dirPath) == sortKey {
+		return false
+	}
+
+	if config.GameListSort == nil {
+		config.GameListSort = make(map[string]string)
+	}
+	config.GameListSort[gameListSortPlatform(dirPath)] = sortKey
+
+	return true
+}
+
+// GameListSortUsesPlayTime reports whether a sort order needs the game tracker.
+func GameListSortUsesPlayTime(sortKey string) bool {
+	order := models.GameListSortMap[sortKey]
+	return order == models.GameListSorts.LastPlayed || order == models.GameListSorts.PlayTime
+}
+
+func gameListSortPlatform(dirPath string) string {
+	platformPath, _ := indexScope(dirPath)
+	if platformPath == "" {
+		platformPath = dirPath
+	}
+	return strings.ToLower(filepath.Base(platformPath))
+}
+
+// ListSortedLibraryDirectory lists a directory in the given sort order, falling back to name order for ties.
+// gamePlayMap may be nil unless GameListSortUsesPlayTime is true.
+func ListSortedLibraryDirectory(dirPath string, sortKey string, gamePlayMap map[string][]models.PlayHistoryAggregate) ([]shared.Item, error) {
+	roms, err := ListIndexedDirectory(dirPath)
+	if err != nil {
+		roms, err = statDirectoryRoms(dirPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sortIndexedRoms(roms, sortKey, gamePlayMap)
+
+	var items []shared.Item
+	for _, rom := range roms {
+		items = append(items, rom.Item)
+	}
+	return items, nil
+}
+
+// sortIndexedRoms orders ROMs by one of the games list sort orders, keeping the current order for ties.
+func sortIndexedRoms(roms []models.IndexedRom, sortKey string, gamePlayMap map[string][]models.PlayHistoryAggregate) {
+	order := models.GameListSortMap[sortKey]
+
+	playHistory := func(rom models.IndexedRom) models.PlayHistoryAggregate {
+		aggregate, _ := CollectGameAggregateFromGame(rom.Item, gamePlayMap)
+		return aggregate
+	}
+
+	slices.SortStableFunc(roms, func(a, b models.IndexedRom) int {
+		switch order {
+		case models.GameListSorts.Size:
+			return cmp.Compare(b.Size, a.Size)
+		case models.GameListSorts.DateAdded:
+			return b.ModTime.Compare(a.ModTime)
+		case models.GameListSorts.LastPlayed:
+			return playHistory(b).LastPlayedTime.Compare(playHistory(a).LastPlayedTime)
+		case models.GameListSorts.PlayTime:
+			return cmp.Compare(playHistory(b).PlayTimeTotal, playHistory(a).PlayTimeTotal)
+		case models.GameListSorts.MissingArt:
+			if a.HasArt != b.HasArt {
+				if b.HasArt {
+					return -1
+				}
+				return 1
+			}
+		}
+		return 0
+	})
+}
+
+// statDirectoryRoms reads what the sort orders need straight from the disk for when the index is unavailable.
+func statDirectoryRoms(dirPath string) ([]models.IndexedRom, error) {
+	items, err := listDirectoryItems(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var roms []models.IndexedRom
+	for _, item := range items {
+		rom := models.IndexedRom{Item: item}
+
+		if info, err := fileSystem.Stat(item.Path); err == nil {
+			rom.ModTime = info.ModTime()
+			rom.Size = info.Size()
+			if item.IsMultiDiscDirectory || item.IsSelfContainedDirectory {
+				rom.Size = directorySize(item.Path)
+			}
+		}
+
+		artPath := filepath.Join(dirPath, ".media", removeFileExtension(item.Filename)+".png")
+		if _, err := fileSystem.Stat(artPath); err == nil {
+			rom.HasArt = true
+		}
+
+		roms = append(roms, rom)
+	}
+
+	return roms, nil
+}
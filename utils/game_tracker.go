@@ -30,8 +30,21 @@ func HasGameTrackerData(romFilename string, romDirectory shared.RomDirectory) bo
 }
 
 func buildGameTrackerPath(romDirectoryPath, filename string) string {
-	fullPath := filepath.Join(romDirectoryPath, filename)
-	return strings.ReplaceAll(fullPath, common.RomDirectory+"/", "")
+	return gameTrackerPathFor(filepath.Join(romDirectoryPath, filename))
+}
+
+// gameTrackerPathFor is how the game tracker refers to a ROM: relative to the ROM directory, or by its full path for
+// games archived outside of it.
+func gameTrackerPathFor(romPath string) string {
+	return strings.ReplaceAll(romPath, GetRomDirectory()+"/", "")
+}
+
+// gameTrackerFullPath turns a game tracker path back into a path on the SD card.
+func gameTrackerFullPath(filePath string) string {
+	if filepath.IsAbs(filePath) {
+		return filePath
+	}
+	return filepath.Join(GetRomDirectory(), filePath)
 }
 
 func MigrateGameTrackerData(filename, oldPath, newPath string) bool {
@@ -48,15 +61,103 @@ func MigrateGameTrackerData(filename, oldPath, newPath string) bool {
 		zap.String("oldPath", oldPath),
 		zap.String("newPath", newPath))
 
-	return executeGameTrackerMigration(db, filename, oldPath, newPath, logger)
+	if !executeGameTrackerMigration(db, filename, oldPath, newPath, logger) {
+		return false
+	}
+
+	publishLibraryChange(models.LibraryChange{
+		Kind:         models.LibraryChangeKinds.GameTrackerChanged,
+		Path:         newPath,
+		PreviousPath: oldPath,
+	})
+	return true
 }
 
-func updateGameTrackerForRename(oldFilename, newFilename string, romDirectory shared.RomDirectory, logger *zap.Logger) {
+func updateGameTrackerForRename(op *operation, oldFilename, newFilename string, romDirectory shared.RomDirectory, logger *zap.Logger) {
 	oldPath := buildGameTrackerPath(romDirectory.Path, oldFilename)
 	newPath := buildGameTrackerPath(romDirectory.Path, newFilename+filepath.Ext(oldFilename))
 
 	logger.Debug("Updating game tracker for rename", zap.String("old", oldPath), zap.String("new", newPath))
-	MigrateGameTrackerData(newFilename, oldPath, newPath)
+	op.migrateGameTracker(removeFileExtension(oldFilename), newFilename, oldPath, newPath)
+}
+
+// migrateGameTrackerTree points the game tracker rows for a ROM at where it was moved to. A folder can hold a row for
+// every disc of a multi-disc game, so every row below the old path is moved along.
+func migrateGameTrackerTree(op *operation, oldRomPath, newRomPath string) {
+	logger := common.GetLoggerInstance()
+
+	if !DoesFileExists(GetGameTrackerDBPath()) {
+		return
+	}
+
+	oldPath := gameTrackerPathFor(oldRomPath)
+	newPath := gameTrackerPathFor(newRomPath)
+
+	roms, err := findGameTrackerRoms(oldPath)
+	if err != nil {
+		logger.Error("Failed to find game tracker data to migrate", zap.String("path", oldPath), zap.Error(err))
+		return
+	}
+
+	for _, rom := range roms {
+		romPath := newPath + strings.TrimPrefix(rom.filePath, oldPath)
+		op.migrateGameTracker(rom.name, rom.name, rom.filePath, romPath)
+	}
+}
+
+// gameTrackerPlayTime adds up the play time the game tracker has for a ROM, or for every disc of a multi-disc game.
+func gameTrackerPlayTime(romPath string) int {
+	if !DoesFileExists(GetGameTrackerDBPath()) {
+		return 0
+	}
+
+	db, err := openGameTrackerDB()
+	if err != nil {
+		return 0
+	}
+	defer closeDB(db)
+
+	path := gameTrackerPathFor(romPath)
+
+	var playTime sql.NullInt64
+	err = db.QueryRow("SELECT SUM(play_activity.play_time) FROM rom "+
+		"JOIN play_activity ON rom.id = play_activity.rom_id "+
+		"WHERE rom.file_path = ? OR instr(rom.file_path, ?) = 1", path, path+"/").Scan(&playTime)
+	if err != nil {
+		common.GetLoggerInstance().Error("Failed to add up play time", zap.String("path", path), zap.Error(err))
+		return 0
+	}
+	return int(playTime.Int64)
+}
+
+type gameTrackerRom struct {
+	name     string
+	filePath string
+}
+
+// findGameTrackerRoms lists the game tracker rows for a path and everything below it.
+func findGameTrackerRoms(path string) ([]gameTrackerRom, error) {
+	db, err := openGameTrackerDB()
+	if err != nil {
+		return nil, err
+	}
+	defer closeDB(db)
+
+	rows, err := db.Query("SELECT name, file_path FROM rom WHERE file_path = ? OR instr(file_path, ?) = 1", path, path+"/")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roms []gameTrackerRom
+	for rows.Next() {
+		var rom gameTrackerRom
+		if err := rows.Scan(&rom.name, &rom.filePath); err != nil {
+			return nil, err
+		}
+		roms = append(roms, rom)
+	}
+	return roms, rows.Err()
 }
 
 func findRomID(tx *sql.Tx, romPath string) (string, error) {
@@ -133,6 +234,11 @@ func ClearGameTracker(romName string, romDirectory shared.RomDirectory) bool {
 	}
 
 	logger.Info("Game tracker data cleared successfully")
+
+	publishLibraryChange(models.LibraryChange{
+		Kind: models.LibraryChangeKinds.GameTrackerChanged,
+		Path: romPath,
+	})
 	return true
 }
 
@@ -165,24 +271,21 @@ func closeDB(db *sql.DB) {
 func FindRomHomeFromAggregate(gameAggregate models.PlayHistoryAggregate, showArchives bool) string {
 	gamePath := gameAggregate.Path
 	if DoesFileExists(gamePath) {
-		if showArchives {
-			return "(+) "
+		if !showArchives {
+			return ""
 		}
-		return ""
+		if _, archiveName := indexScope(gamePath); archiveName != "" {
+			return "(" + string(CleanArchiveName(archiveName)[0]) + ") "
+		}
+		return "(+) "
 	}
 
-	archiveList, err := GetArchiveFileListBasic()
-	if err == nil {
-		for _, archiveName := range archiveList {
-			gameSubPath := strings.ReplaceAll(gamePath, GetRomDirectory(), "")
-			archivePath := filepath.Join(GetRomDirectory(), archiveName, gameSubPath)
-			if DoesFileExists(archivePath) {
-				if showArchives {
-					return "(" + string(CleanArchiveName(archiveName)[0]) + ") "
-				}
-				return ""
-			}
+	// Games archived before their play history followed them are still known by where they were
+	if archiveName, archived := ArchivedGameHome(gamePath); archived {
+		if showArchives {
+			return "(" + string(CleanArchiveName(archiveName)[0]) + ") "
 		}
+		return ""
 	}
 
 	return "(-) "
@@ -272,6 +375,10 @@ func GenerateCurrentGameStats() (map[string][]models.PlayHistoryAggregate, map[s
         				  "GROUP BY rom.id " +
         				  "HAVING play_time_total > 0 " +
         				  "ORDER BY play_time_total DESC")
+	if err != nil {
+		logger.Error("Failed to query game tracker", zap.Error(err))
+		return nil, nil, 0
+	}
 	defer rows.Close()
 
 	gamePlayMap := make(map[string][]models.PlayHistoryAggregate)
@@ -375,17 +482,26 @@ func extractMultiDiscName(romName string, filePath string) (string, string, bool
 	if (strings.Contains(romName, "(Disc") || strings.Contains(romName, "(Disk")) {
 		pathList := strings.Split(filePath, "/")
 		if len(pathList) >= 2 {
-			return pathList[len(pathList)-2], filepath.Join(GetRomDirectory(), filePath, ".."), true
+			return UncompressedName(pathList[len(pathList)-2]), filepath.Join(gameTrackerFullPath(filePath), ".."), true
 		}
 	}
-	return romName, filepath.Join(GetRomDirectory(), filePath), false
+	return romName, gameTrackerFullPath(filePath), false
 }
 
+// extractPlayConsoleName returns the platform folder a game was played from, which for archived games is the one
+// inside the archive.
 func extractPlayConsoleName(romFilePath string) string {
+	if platformPath, archiveName := indexScope(gameTrackerFullPath(romFilePath)); archiveName != "" && platformPath != "" {
+		return filepath.Base(platformPath)
+	}
 	return strings.Split(romFilePath, "/")[0]
 }
 
 func extractItemConsoleName(gameItem shared.Item) string {
+	if platformPath, archiveName := indexScope(gameItem.Path); archiveName != "" && platformPath != "" {
+		return filepath.Base(platformPath)
+	}
+
 	pathSplit := strings.Split(gameItem.Path, "/")
 	if len(pathSplit) < 5 {
 		return ""
@@ -406,17 +522,3 @@ func ConvertSecondsToHumanReadableAbbreviated(gameTimeSeconds int) string {
 	seconds := gameTimeSeconds%60
 	return fmt.Sprintf("%dH %dM %dS", hours, minutes, seconds)
 }
-
-func FilterPlayList(itemList []models.PlayHistoryAggregate, keywords ...string) []models.PlayHistoryAggregate {
-	if len(keywords) == 0 {
-		return itemList
-	}
-
-	var filteredItems []models.PlayHistoryAggregate
-	for _, item := range itemList {
-		if matchesAnyKeyword(item.Name, keywords) {
-			filteredItems = append(filteredItems, item)
-		}
-	}
-	return filteredItems
-}
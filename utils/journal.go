@@ -0,0 +1,329 @@
+package utils
+
+import (
+	"fmt"
+	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
+	"github.com/spf13/afero"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+	"path/filepath"
+	"time"
+)
+
+const operationJournalFile = "operation_journal.yml"
+
+const (
+	journalStepMove        = "move"
+	journalStepGameTracker = "game_tracker"
+	journalStepReferences  = "references"
+	journalStepCompress    = "compress"
+	journalStepExtract     = "extract"
+)
+
+// JournalStep is a single reversible piece of a composite operation. Done is only set once the step has finished,
+// so a step recorded without it may or may not have happened when the device lost power.
+type JournalStep struct {
+	Kind    string   `yaml:"kind"`
+	From    string   `yaml:"from"`
+	To      string   `yaml:"to"`
+	OldName string   `yaml:"old_name,omitempty"`
+	Paths   []string `yaml:"paths,omitempty"`
+	Done    bool     `yaml:"done"`
+}
+
+type OperationJournal struct {
+	Operation string        `yaml:"operation"`
+	StartedAt time.Time     `yaml:"started_at"`
+	Steps     []JournalStep `yaml:"steps"`
+}
+
+type operation struct {
+	journal OperationJournal
+}
+
+// beginOperation starts journaling a composite operation. Any journal left behind by an interrupted run is rolled back
+// first so two operations never share the journal.
+func beginOperation(name string) (*operation, error) {
+	if DoesFileExists(GetOperationJournalPath()) {
+		if _, err := RecoverInterruptedOperation(); err != nil {
+			return nil, fmt.Errorf("failed to recover interrupted operation: %w", err)
+		}
+	}
+
+	op := &operation{
+		journal: OperationJournal{
+			Operation: name,
+			StartedAt: time.Now(),
+		},
+	}
+
+	if err := op.persist(); err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+func (op *operation) move(sourcePath, destinationPath string) error {
+	op.journal.Steps = append(op.journal.Steps, JournalStep{
+		Kind: journalStepMove,
+		From: sourcePath,
+		To:   destinationPath,
+	})
+
+	if err := op.persist(); err != nil {
+		op.dropLastStep()
+		return err
+	}
+
+	if err := MoveFile(sourcePath, destinationPath); err != nil {
+		op.dropLastStep()
+		return err
+	}
+
+	return op.markLastStepDone()
+}
+
+// compress zips paths, relative to baseDir, into zipPath and then removes them.
+func (op *operation) compress(baseDir string, paths []string, zipPath string) error {
+	op.journal.Steps = append(op.journal.Steps, JournalStep{
+		Kind:  journalStepCompress,
+		From:  baseDir,
+		To:    zipPath,
+		Paths: paths,
+	})
+
+	if err := op.persist(); err != nil {
+		op.dropLastStep()
+		return err
+	}
+
+	if err := compressPaths(baseDir, paths, zipPath); err != nil {
+		op.dropLastStep()
+		return err
+	}
+
+	if err := removePaths(baseDir, paths); err != nil {
+		// Leaves the step in place so the rollback puts back whatever was already removed
+		return err
+	}
+
+	return op.markLastStepDone()
+}
+
+// extract unzips zipPath into baseDir and then removes the zip.
+func (op *operation) extract(zipPath string, baseDir string) error {
+	paths, err := compressedArchiveEntries(zipPath)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		if DoesFileExists(filepath.Join(baseDir, path)) {
+			return fmt.Errorf("%s already exists", filepath.Join(baseDir, path))
+		}
+	}
+
+	op.journal.Steps = append(op.journal.Steps, JournalStep{
+		Kind:  journalStepExtract,
+		From:  zipPath,
+		To:    baseDir,
+		Paths: paths,
+	})
+
+	if err := op.persist(); err != nil {
+		op.dropLastStep()
+		return err
+	}
+
+	if err := extractArchive(zipPath, baseDir); err != nil {
+		return err
+	}
+
+	if err := fileSystem.Remove(zipPath); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", filepath.Base(zipPath), err)
+	}
+
+	return op.markLastStepDone()
+}
+
+// migrateGameTracker moves the game tracker rows for a ROM. It reports false when there was nothing to migrate,
+// which is not an error since most ROMs have never been played.
+func (op *operation) migrateGameTracker(oldName, newName, oldPath, newPath string) bool {
+	op.journal.Steps = append(op.journal.Steps, JournalStep{
+		Kind:    journalStepGameTracker,
+		From:    oldPath,
+		To:      newPath,
+		OldName: oldName,
+	})
+
+	if err := op.persist(); err != nil {
+		op.dropLastStep()
+		return false
+	}
+
+	if !MigrateGameTrackerData(newName, oldPath, newPath) {
+		op.dropLastStep()
+		return false
+	}
+
+	_ = op.markLastStepDone()
+	return true
+}
+
+// moveReferences points the collections and recently played entries for a ROM at where it was moved to.
+func (op *operation) moveReferences(oldPath, newPath string) error {
+	op.journal.Steps = append(op.journal.Steps, JournalStep{
+		Kind: journalStepReferences,
+		From: oldPath,
+		To:   newPath,
+	})
+
+	if err := op.persist(); err != nil {
+		op.dropLastStep()
+		return err
+	}
+
+	if err := moveLibraryReferences(oldPath, newPath); err != nil {
+		op.dropLastStep()
+		return fmt.Errorf("failed to update collections and recently played: %w", err)
+	}
+
+	return op.markLastStepDone()
+}
+
+// rollback undoes every step in reverse order and removes the journal.
+func (op *operation) rollback() {
+	logger := common.GetLoggerInstance()
+	logger.Info("Rolling back operation", zap.String("operation", op.journal.Operation))
+
+	rollbackSteps(op.journal.Steps)
+	op.finish()
+}
+
+// commit marks the operation as complete by removing the journal.
+func (op *operation) commit() {
+	op.finish()
+}
+
+func (op *operation) finish() {
+	logger := common.GetLoggerInstance()
+
+	if err := fileSystem.Remove(GetOperationJournalPath()); err != nil {
+		logger.Error("Failed to remove operation journal", zap.Error(err))
+	}
+}
+
+func (op *operation) dropLastStep() {
+	op.journal.Steps = op.journal.Steps[:len(op.journal.Steps)-1]
+	_ = op.persist()
+}
+
+func (op *operation) markLastStepDone() error {
+	op.journal.Steps[len(op.journal.Steps)-1].Done = true
+	return op.persist()
+}
+
+func (op *operation) persist() error {
+	data, err := yaml.Marshal(op.journal)
+	if err != nil {
+		return fmt.Errorf("failed to encode operation journal: %w", err)
+	}
+
+	if err := afero.WriteFile(fileSystem, GetOperationJournalPath(), data, defaultFilePerm); err != nil {
+		return fmt.Errorf("failed to write operation journal: %w", err)
+	}
+
+	return nil
+}
+
+// RecoverInterruptedOperation rolls back an operation that was cut short, e.g. by the device powering off mid-rename.
+// It returns the name of the repaired operation, or an empty string when there was nothing to repair.
+func RecoverInterruptedOperation() (string, error) {
+	logger := common.GetLoggerInstance()
+
+	if !DoesFileExists(GetOperationJournalPath()) {
+		return "", nil
+	}
+
+	data, err := afero.ReadFile(fileSystem, GetOperationJournalPath())
+	if err != nil {
+		return "", fmt.Errorf("failed to read operation journal: %w", err)
+	}
+
+	var journal OperationJournal
+	if err := yaml.Unmarshal(data, &journal); err != nil {
+		logger.Error("Discarding unreadable operation journal", zap.Error(err))
+		return "", fileSystem.Remove(GetOperationJournalPath())
+	}
+
+	logger.Info("Recovering interrupted operation",
+		zap.String("operation", journal.Operation),
+		zap.Time("started_at", journal.StartedAt))
+
+	rollbackSteps(journal.Steps)
+
+	if err := fileSystem.Remove(GetOperationJournalPath()); err != nil {
+		return journal.Operation, fmt.Errorf("failed to remove operation journal: %w", err)
+	}
+
+	return journal.Operation, nil
+}
+
+func rollbackSteps(steps []JournalStep) {
+	logger := common.GetLoggerInstance()
+
+	for i := len(steps) - 1; i >= 0; i-- {
+		step := steps[i]
+
+		switch step.Kind {
+		case journalStepMove:
+			// An unfinished move may have happened right before power was lost, so trust the filesystem over the flag.
+			if DoesFileExists(step.From) || !DoesFileExists(step.To) {
+				continue
+			}
+
+			if err := MoveFile(step.To, step.From); err != nil {
+				logger.Error("Failed to roll back move", zap.String("from", step.To), zap.String("to", step.From), zap.Error(err))
+			}
+		case journalStepGameTracker:
+			MigrateGameTrackerData(step.OldName, step.To, step.From)
+		case journalStepReferences:
+			if err := moveLibraryReferences(step.To, step.From); err != nil {
+				logger.Error("Failed to roll back collection entries", zap.String("from", step.To), zap.String("to", step.From), zap.Error(err))
+			}
+		case journalStepCompress:
+			// Zips are only given their name once complete, so one that exists holds everything that was removed
+			_ = fileSystem.Remove(partialArchivePath(step.To))
+			if !DoesFileExists(step.To) {
+				continue
+			}
+
+			if err := extractArchive(step.To, step.From); err != nil {
+				logger.Error("Failed to roll back compression", zap.String("archive", step.To), zap.Error(err))
+				continue
+			}
+
+			if err := fileSystem.Remove(step.To); err != nil {
+				logger.Error("Failed to remove rolled back archive", zap.String("archive", step.To), zap.Error(err))
+			}
+		case journalStepExtract:
+			// The zip is only removed once extracted in full, so while it exists the extracted files can just go
+			if DoesFileExists(step.From) {
+				if err := removePaths(step.To, step.Paths); err != nil {
+					logger.Error("Failed to roll back extraction", zap.String("archive", step.From), zap.Error(err))
+				}
+				continue
+			}
+
+			if err := compressPaths(step.To, step.Paths, step.From); err != nil {
+				logger.Error("Failed to roll back extraction", zap.String("archive", step.From), zap.Error(err))
+				continue
+			}
+
+			if err := removePaths(step.To, step.Paths); err != nil {
+				logger.Error("Failed to remove extracted files", zap.String("archive", step.From), zap.Error(err))
+			}
+		}
+	}
+}
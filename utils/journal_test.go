@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestOperationRollback(t *testing.T) {
+	useFakeSDCard(t)
+
+	romPath := filepath.Join(testPlatform, "Tetris.gb")
+	artPath := filepath.Join(testPlatform, ".media", "Tetris.png")
+	writeTestFile(t, romPath, "rom")
+	writeTestFile(t, artPath, "art")
+
+	op, err := beginOperation("Rename Tetris.gb")
+	if err != nil {
+		t.Fatalf("beginOperation: %v", err)
+	}
+
+	if err := op.move(romPath, filepath.Join(testPlatform, "Tetris DX.gb")); err != nil {
+		t.Fatalf("moving ROM: %v", err)
+	}
+	if err := op.move(artPath, filepath.Join(testPlatform, ".media", "Tetris DX.png")); err != nil {
+		t.Fatalf("moving art: %v", err)
+	}
+	if err := op.move(filepath.Join(testPlatform, "Missing.gb"), filepath.Join(testPlatform, "Other.gb")); err == nil {
+		t.Fatal("moving a missing file succeeded")
+	}
+
+	op.rollback()
+
+	assertExists(t, romPath, artPath)
+	assertMissing(t, filepath.Join(testPlatform, "Tetris DX.gb"), filepath.Join(testPlatform, ".media", "Tetris DX.png"), GetOperationJournalPath())
+}
+
+func TestRecoverInterruptedOperation(t *testing.T) {
+	useFakeSDCard(t)
+
+	romPath := filepath.Join(testPlatform, "Tetris.gb")
+	writeTestFile(t, romPath, "rom")
+	writeTestFile(t, filepath.Join(testCollections, "Puzzle.txt"), "/Roms/Game Boy (GB)/Tetris.gb\n")
+
+	op, err := beginOperation("Rename Tetris.gb")
+	if err != nil {
+		t.Fatalf("beginOperation: %v", err)
+	}
+
+	renamedPath := filepath.Join(testPlatform, "Tetris DX.gb")
+	if err := op.move(romPath, renamedPath); err != nil {
+		t.Fatalf("moving ROM: %v", err)
+	}
+	if err := op.moveReferences(romPath, renamedPath); err != nil {
+		t.Fatalf("moving references: %v", err)
+	}
+
+	// The device loses power here, leaving the journal behind for the next start
+	name, err := RecoverInterruptedOperation()
+	if err != nil {
+		t.Fatalf("RecoverInterruptedOperation: %v", err)
+	}
+	if name != "Rename Tetris.gb" {
+		t.Errorf("recovered %q, want %q", name, "Rename Tetris.gb")
+	}
+
+	assertExists(t, romPath)
+	assertMissing(t, renamedPath, GetOperationJournalPath())
+	if got, want := readTestFile(t, filepath.Join(testCollections, "Puzzle.txt")), "/Roms/Game Boy (GB)/Tetris.gb\n"; got != want {
+		t.Errorf("collection after recovery: %q, want %q", got, want)
+	}
+
+	if name, err := RecoverInterruptedOperation(); name != "" || err != nil {
+		t.Errorf("second recovery returned %q, %v", name, err)
+	}
+}
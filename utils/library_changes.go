@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"nextui-game-manager/models"
+	"sync"
+)
+
+var (
+	librarySubscribers      []func(models.LibraryChange)
+	librarySubscribersMutex sync.RWMutex
+)
+
+// SubscribeLibraryChanges registers a handler that is called, on the publishing goroutine, after every change a util
+// makes to ROMs, collections or the game tracker.
+func SubscribeLibraryChanges(handler func(change models.LibraryChange)) {
+	librarySubscribersMutex.Lock()
+	defer librarySubscribersMutex.Unlock()
+
+	librarySubscribers = append(librarySubscribers, handler)
+}
+
+func publishLibraryChange(change models.LibraryChange) {
+	librarySubscribersMutex.RLock()
+	subscribers := librarySubscribers
+	librarySubscribersMutex.RUnlock()
+
+	for _, subscriber := range subscribers {
+		subscriber(change)
+	}
+}
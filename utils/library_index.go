@@ -0,0 +1,949 @@
+package utils
+
+import (
+	"cmp"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"github.com/spf13/afero"
+	"go.uber.org/zap"
+	"nextui-game-manager/models"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const libraryIndexSchema = `
+CREATE TABLE IF NOT EXISTS directories (
+	path        TEXT PRIMARY KEY,
+	mtime       INTEGER NOT NULL,
+	media_mtime INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS entries (
+	path              TEXT PRIMARY KEY,
+	parent            TEXT NOT NULL,
+	filename          TEXT NOT NULL,
+	display_name      TEXT NOT NULL,
+	tag               TEXT NOT NULL,
+	platform_path     TEXT NOT NULL,
+	archive           TEXT NOT NULL,
+	collection_path   TEXT NOT NULL,
+	is_directory      INTEGER NOT NULL,
+	is_multi_disc     INTEGER NOT NULL,
+	is_self_contained INTEGER NOT NULL,
+	is_rom            INTEGER NOT NULL,
+	size              INTEGER NOT NULL,
+	mtime             INTEGER NOT NULL,
+	has_art           INTEGER NOT NULL,
+	has_save          INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS entries_parent ON entries (parent);
+CREATE INDEX IF NOT EXISTS entries_platform ON entries (platform_path);
+CREATE INDEX IF NOT EXISTS entries_collection_path ON entries (collection_path);
+CREATE TABLE IF NOT EXISTS platform_saves (
+	platform_path TEXT PRIMARY KEY,
+	mtime         INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS collections (
+	file  TEXT PRIMARY KEY,
+	name  TEXT NOT NULL,
+	mtime INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS collection_games (
+	file  TEXT NOT NULL,
+	entry TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS collection_games_entry ON collection_games (entry);
+`
+
+const indexedEntryColumns = "path, filename, display_name, tag, platform_path, archive, is_directory, is_multi_disc, " +
+	"is_self_contained, size, mtime, has_art, has_save"
+
+type sqlExecutor interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+func init() {
+	SubscribeLibraryChanges(updateLibraryIndex)
+}
+
+func openLibraryIndex() (*sql.DB, error) {
+	logger := common.GetLoggerInstance()
+
+	if GetLibraryIndexPath() == "" {
+		return nil, fmt.Errorf("no library index configured")
+	}
+
+	db, err := sql.Open("sqlite3", GetLibraryIndexPath())
+	if err != nil {
+		logger.Error("Failed to open library index", zap.Error(err))
+		return nil, err
+	}
+
+	if _, err := db.Exec(libraryIndexSchema); err != nil {
+		closeDB(db)
+		logger.Error("Failed to prepare library index", zap.Error(err))
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// RefreshLibraryIndex brings the whole index up to date. Only directories whose mtime moved since the last refresh are
+// listed again, so an unchanged SD card costs one stat per folder.
+func RefreshLibraryIndex() error {
+	db, err := openLibraryIndex()
+	if err != nil {
+		return err
+	}
+	defer closeDB(db)
+
+	return refreshLibraryIndex(db)
+}
+
+func refreshLibraryIndex(db *sql.DB) error {
+	if err := refreshIndexedTree(db, GetRomDirectory()); err != nil {
+		return fmt.Errorf("failed to index ROMs: %w", err)
+	}
+
+	for _, location := range GetArchiveLocations() {
+		if err := refreshIndexedTree(db, location); err != nil {
+			return fmt.Errorf("failed to index archive location %s: %w", location, err)
+		}
+	}
+
+	if err := refreshIndexedSaves(db); err != nil {
+		return fmt.Errorf("failed to index saves: %w", err)
+	}
+
+	if err := refreshIndexedCollections(db); err != nil {
+		return fmt.Errorf("failed to index collections: %w", err)
+	}
+
+	return nil
+}
+
+func refreshIndexedTree(db *sql.DB, dirPath string) error {
+	if err := refreshIndexedDirectory(db, dirPath); err != nil {
+		return err
+	}
+
+	rows, err := db.Query("SELECT path FROM entries WHERE parent = ? AND is_directory = 1 AND is_rom = 0", dirPath)
+	if err != nil {
+		return err
+	}
+
+	var children []string
+	for rows.Next() {
+		var child string
+		if err := rows.Scan(&child); err != nil {
+			rows.Close()
+			return err
+		}
+		children = append(children, child)
+	}
+	rows.Close()
+
+	for _, child := range children {
+		if err := refreshIndexedTree(db, child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// refreshIndexedDirectory lists a directory again if it, or the art folder next to its ROMs, changed since it was
+// last indexed.
+func refreshIndexedDirectory(db *sql.DB, dirPath string) error {
+	info, err := fileSystem.Stat(dirPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return forgetIndexedTree(db, dirPath)
+		}
+		return err
+	}
+
+	modTime := info.ModTime().UnixNano()
+	mediaModTime := modTimeOf(filepath.Join(dirPath, ".media"))
+
+	var indexedModTime, indexedMediaModTime int64
+	err = db.QueryRow("SELECT mtime, media_mtime FROM directories WHERE path = ?", dirPath).
+		Scan(&indexedModTime, &indexedMediaModTime)
+	if err == nil && indexedModTime == modTime && indexedMediaModTime == mediaModTime {
+		return nil
+	}
+
+	return indexDirectory(db, dirPath, modTime, mediaModTime)
+}
+
+func indexDirectory(db *sql.DB, dirPath string, modTime int64, mediaModTime int64) error {
+	logger := common.GetLoggerInstance()
+
+	entries, err := GetFileList(dirPath)
+	if err != nil {
+		return err
+	}
+
+	art := make(map[string]bool)
+	if mediaEntries, err := GetFileList(filepath.Join(dirPath, ".media")); err == nil {
+		for _, entry := range mediaEntries {
+			art[entry.Name()] = true
+		}
+	}
+
+	platformPath, archive := indexScope(dirPath)
+
+	var saveItems []shared.Item
+	if platformPath != "" {
+		saveItems, _ = listDirectoryItems(platformSaveDirectory(platformPath))
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	current := make(map[string]bool)
+	for _, entry := range entries {
+		current[filepath.Join(dirPath, entry.Name())] = true
+	}
+
+	previous, err := indexedChildDirectories(tx, dirPath)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range previous {
+		if !current[path] {
+			if err := forgetIndexedTree(tx, path); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM entries WHERE parent = ?", dirPath); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !isIndexable(dirPath, entry) {
+			continue
+		}
+
+		item := buildItem(dirPath, entry)
+		entryPlatform, entryArchive := indexScope(item.Path)
+		if entryPlatform == "" {
+			entryPlatform, entryArchive = platformPath, archive
+		}
+
+		isRom := entryPlatform != "" && entryPlatform != item.Path &&
+			(!item.IsDirectory || item.IsMultiDiscDirectory || item.IsSelfContainedDirectory)
+
+		size := entry.Size()
+		if isRom && item.IsDirectory {
+			size = directorySize(item.Path)
+		}
+
+		hasSave := isRom && findSaveFile(saveItems, UncompressedName(item.Filename)).Filename != ""
+
+		_, err := tx.Exec("INSERT OR REPLACE INTO entries (path, parent, filename, display_name, tag, platform_path, "+
+			"archive, collection_path, is_directory, is_multi_disc, is_self_contained, is_rom, size, mtime, has_art, "+
+			"has_save) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			item.Path, dirPath, item.Filename, item.DisplayName, item.Tag, entryPlatform, entryArchive,
+			normalizeCollectionGamePath(item), item.IsDirectory, item.IsMultiDiscDirectory,
+			item.IsSelfContainedDirectory, isRom, size, entry.ModTime().UnixNano(),
+			art[removeFileExtension(item.Filename)+".png"], hasSave)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec("INSERT OR REPLACE INTO directories (path, mtime, media_mtime) VALUES (?, ?, ?)",
+		dirPath, modTime, mediaModTime); err != nil {
+		return err
+	}
+
+	logger.Debug("Indexed directory", zap.String("directory", dirPath), zap.Int("entries", len(entries)))
+
+	return tx.Commit()
+}
+
+// isIndexable skips hidden files, except the archive folders that sit hidden in the root of the ROM directory.
+func isIndexable(dirPath string, entry os.FileInfo) bool {
+	if !strings.HasPrefix(entry.Name(), ".") {
+		return true
+	}
+
+	return dirPath == GetRomDirectory() && entry.IsDir() && entry.Name() != ".media"
+}
+
+// indexScope works out which platform folder and archive a path belongs to from where it sits under the ROM directory
+// or an archive location.
+func indexScope(path string) (platformPath string, archive string) {
+	if location := externalArchiveLocation(path); location != "" {
+		parts := strings.Split(strings.TrimPrefix(path, location+string(filepath.Separator)), string(filepath.Separator))
+		archive = filepath.Join(location, parts[0])
+		if len(parts) < 2 {
+			return "", archive
+		}
+		return filepath.Join(archive, parts[1]), archive
+	}
+
+	relativePath, err := filepath.Rel(GetRomDirectory(), path)
+	if err != nil || relativePath == "." || strings.HasPrefix(relativePath, "..") {
+		return "", ""
+	}
+
+	parts := strings.Split(relativePath, string(filepath.Separator))
+	if !strings.HasPrefix(parts[0], ".") {
+		return filepath.Join(GetRomDirectory(), parts[0]), ""
+	}
+
+	if len(parts) < 2 {
+		return "", parts[0]
+	}
+	return filepath.Join(GetRomDirectory(), parts[0], parts[1]), parts[0]
+}
+
+func indexedChildDirectories(tx *sql.Tx, dirPath string) ([]string, error) {
+	rows, err := tx.Query("SELECT path FROM entries WHERE parent = ? AND is_directory = 1", dirPath)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, rows.Err()
+}
+
+// forgetIndexedTree drops a path and everything indexed below it.
+func forgetIndexedTree(db sqlExecutor, path string) error {
+	prefix := path + string(filepath.Separator)
+
+	if _, err := db.Exec("DELETE FROM entries WHERE path = ? OR instr(path, ?) = 1", path, prefix); err != nil {
+		return err
+	}
+	_, err := db.Exec("DELETE FROM directories WHERE path = ? OR instr(path, ?) = 1", path, prefix)
+	return err
+}
+
+// platformSaveDirectory is the save folder for a platform. Saves of archived games are kept in their archive.
+func platformSaveDirectory(platformPath string) string {
+	tag := cleanTag(itemTagPattern.FindString(filepath.Base(platformPath)))
+	if _, archive := indexScope(platformPath); archive != "" {
+		return filepath.Join(GetArchiveRoot(archive), archivedGameDataFolder, "Saves", tag)
+	}
+	return filepath.Join(GetSaveFileDirectory(), tag)
+}
+
+// refreshIndexedSaves rechecks save presence for platforms whose save folder changed since the last refresh.
+func refreshIndexedSaves(db *sql.DB) error {
+	rows, err := db.Query("SELECT DISTINCT platform_path FROM entries WHERE is_rom = 1")
+	if err != nil {
+		return err
+	}
+
+	var platforms []string
+	for rows.Next() {
+		var platform string
+		if err := rows.Scan(&platform); err != nil {
+			rows.Close()
+			return err
+		}
+		platforms = append(platforms, platform)
+	}
+	rows.Close()
+
+	for _, platform := range platforms {
+		saveDirectory := platformSaveDirectory(platform)
+		modTime := modTimeOf(saveDirectory)
+
+		var indexedModTime int64
+		err := db.QueryRow("SELECT mtime FROM platform_saves WHERE platform_path = ?", platform).Scan(&indexedModTime)
+		if err == nil && indexedModTime == modTime {
+			continue
+		}
+
+		saveItems, _ := listDirectoryItems(saveDirectory)
+		if err := indexPlatformSaves(db, platform, saveItems, modTime); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func indexPlatformSaves(db *sql.DB, platform string, saveItems []shared.Item, modTime int64) error {
+	rows, err := db.Query("SELECT path, filename FROM entries WHERE platform_path = ? AND is_rom = 1", platform)
+	if err != nil {
+		return err
+	}
+
+	saves := make(map[string]bool)
+	for rows.Next() {
+		var path, filename string
+		if err := rows.Scan(&path, &filename); err != nil {
+			rows.Close()
+			return err
+		}
+		saves[path] = findSaveFile(saveItems, UncompressedName(filename)).Filename != ""
+	}
+	rows.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for path, hasSave := range saves {
+		if _, err := tx.Exec("UPDATE entries SET has_save = ? WHERE path = ?", hasSave, path); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec("INSERT OR REPLACE INTO platform_saves (platform_path, mtime) VALUES (?, ?)",
+		platform, modTime); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// refreshIndexedCollections rereads collection files whose mtime moved and drops the ones that are gone.
+func refreshIndexedCollections(db *sql.DB) error {
+	items, err := listCollectionItems()
+	if err != nil {
+		return err
+	}
+
+	present := make(map[string]bool)
+	for _, item := range items {
+		present[item.Path] = true
+		modTime := modTimeOf(item.Path)
+
+		var indexedModTime int64
+		err := db.QueryRow("SELECT mtime FROM collections WHERE file = ?", item.Path).Scan(&indexedModTime)
+		if err == nil && indexedModTime == modTime {
+			continue
+		}
+
+		if err := indexCollection(db, item.Path); err != nil {
+			return err
+		}
+	}
+
+	rows, err := db.Query("SELECT file FROM collections")
+	if err != nil {
+		return err
+	}
+
+	var stale []string
+	for rows.Next() {
+		var file string
+		if err := rows.Scan(&file); err != nil {
+			rows.Close()
+			return err
+		}
+		if !present[file] {
+			stale = append(stale, file)
+		}
+	}
+	rows.Close()
+
+	for _, file := range stale {
+		if err := forgetCollection(db, file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func indexCollection(db *sql.DB, file string) error {
+	name := removeFileExtension(filepath.Base(file))
+
+	collection, err := ReadCollection(models.Collection{DisplayName: name, CollectionFile: file})
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM collection_games WHERE file = ?", file); err != nil {
+		return err
+	}
+
+	for _, game := range collection.Games {
+		if _, err := tx.Exec("INSERT INTO collection_games (file, entry) VALUES (?, ?)", file, game.Path); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec("INSERT OR REPLACE INTO collections (file, name, mtime) VALUES (?, ?, ?)",
+		file, name, modTimeOf(file)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func forgetCollection(db sqlExecutor, file string) error {
+	if _, err := db.Exec("DELETE FROM collection_games WHERE file = ?", file); err != nil {
+		return err
+	}
+	_, err := db.Exec("DELETE FROM collections WHERE file = ?", file)
+	return err
+}
+
+// updateLibraryIndex applies a library change straight away. SD cards only keep mtimes to the nearest couple of
+// seconds, so a directory touched by a change is forgotten rather than trusted to look different on the next refresh.
+func updateLibraryIndex(change models.LibraryChange) {
+	logger := common.GetLoggerInstance()
+
+	db, err := openLibraryIndex()
+	if err != nil {
+		return
+	}
+	defer closeDB(db)
+
+	switch change.Kind {
+	case models.LibraryChangeKinds.RomRenamed,
+		models.LibraryChangeKinds.RomArchived,
+		models.LibraryChangeKinds.RomRestored,
+		models.LibraryChangeKinds.RomDeleted:
+		for _, path := range []string{change.Path, change.PreviousPath} {
+			if path == "" {
+				continue
+			}
+
+			platformPath, _ := indexScope(path)
+			_, directoryErr := db.Exec("DELETE FROM directories WHERE path = ?", filepath.Dir(path))
+			_, savesErr := db.Exec("DELETE FROM platform_saves WHERE platform_path = ?", platformPath)
+			err = errors.Join(err, directoryErr, savesErr)
+		}
+	case models.LibraryChangeKinds.CollectionSaved,
+		models.LibraryChangeKinds.CollectionRenamed,
+		models.LibraryChangeKinds.CollectionDeleted:
+		if change.PreviousPath != "" {
+			err = forgetCollection(db, change.PreviousPath)
+		}
+
+		if err == nil && DoesFileExists(change.Path) {
+			err = indexCollection(db, change.Path)
+		} else if err == nil {
+			err = forgetCollection(db, change.Path)
+		}
+	}
+
+	if err != nil {
+		logger.Error("Failed to update library index", zap.String("path", change.Path), zap.Error(err))
+	}
+}
+
+// ListLibraryDirectory returns the items of a directory the way the file browser would, refreshing that directory in
+// the index first. It falls back to listing the SD card if the index cannot be used.
+func ListLibraryDirectory(dirPath string) ([]shared.Item, error) {
+	roms, err := ListIndexedDirectory(dirPath)
+	if err != nil {
+		common.GetLoggerInstance().Error("Library index unavailable, listing directory instead",
+			zap.String("directory", dirPath), zap.Error(err))
+		return listDirectoryItems(dirPath)
+	}
+
+	var items []shared.Item
+	for _, rom := range roms {
+		items = append(items, rom.Item)
+	}
+	return items, nil
+}
+
+// ListIndexedDirectory returns everything the index holds for a directory, refreshing it first.
+func ListIndexedDirectory(dirPath string) ([]models.IndexedRom, error) {
+	db, err := openLibraryIndex()
+	if err != nil {
+		return nil, err
+	}
+	defer closeDB(db)
+
+	if err := refreshIndexedDirectory(db, dirPath); err != nil {
+		return nil, err
+	}
+
+	roms, err := queryIndexedRoms(db, "SELECT "+indexedEntryColumns+" FROM entries WHERE parent = ?", dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	collections, err := queryCollectionMembership(db,
+		"SELECT e.path, c.name FROM entries e "+
+			"JOIN collection_games g ON g.entry = e.collection_path "+
+			"JOIN collections c ON c.file = g.file "+
+			"WHERE e.parent = ?", dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range roms {
+		roms[i].Collections = collections[roms[i].Item.Path]
+	}
+
+	slices.SortFunc(roms, func(a, b models.IndexedRom) int {
+		return strings.Compare(strings.ToLower(a.Item.Filename), strings.ToLower(b.Item.Filename))
+	})
+
+	return roms, nil
+}
+
+// ListLibraryPlatforms returns the platform folders in the ROM directory, leaving out those without a single ROM when
+// hideEmpty is set.
+func ListLibraryPlatforms(hideEmpty bool) ([]shared.RomDirectory, error) {
+	logger := common.GetLoggerInstance()
+
+	items, err := ListLibraryDirectory(GetRomDirectory())
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := indexedRomCounts()
+	if err != nil && hideEmpty {
+		logger.Error("Library index unavailable, counting ROMs on the SD card instead", zap.Error(err))
+	}
+
+	var platforms []shared.RomDirectory
+	for _, item := range items {
+		if !item.IsDirectory || strings.HasPrefix(item.Filename, ".") {
+			continue
+		}
+
+		if hideEmpty {
+			if counts != nil && counts[item.Path] == 0 {
+				continue
+			}
+
+			if counts == nil {
+				if roms, err := getRomFilesRecursive(item.Path); err != nil || len(roms) == 0 {
+					continue
+				}
+			}
+		}
+
+		platforms = append(platforms, CreateRomDirectoryFromItem(item))
+	}
+
+	return platforms, nil
+}
+
+func indexedRomCounts() (map[string]int, error) {
+	db, err := openLibraryIndex()
+	if err != nil {
+		return nil, err
+	}
+	defer closeDB(db)
+
+	rows, err := db.Query("SELECT platform_path, COUNT(*) FROM entries WHERE is_rom = 1 AND archive = '' " +
+		"GROUP BY platform_path")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var platform string
+		var count int
+		if err := rows.Scan(&platform, &count); err != nil {
+			return nil, err
+		}
+		counts[platform] = count
+	}
+	return counts, rows.Err()
+}
+
+// findIndexedRomsWithoutArt answers FindRomsWithoutArt from the index.
+func findIndexedRomsWithoutArt() (map[shared.RomDirectory][]shared.Item, error) {
+	db, err := openLibraryIndex()
+	if err != nil {
+		return nil, err
+	}
+	defer closeDB(db)
+
+	if err := refreshLibraryIndex(db); err != nil {
+		return nil, err
+	}
+
+	roms, err := queryIndexedRoms(db, "SELECT "+indexedEntryColumns+" FROM entries "+
+		"WHERE is_rom = 1 AND has_art = 0 AND archive = '' ORDER BY platform_path, lower(path)")
+	if err != nil {
+		return nil, err
+	}
+
+	romDirectories := make(map[shared.RomDirectory][]shared.Item)
+	for _, rom := range roms {
+		if rom.Platform.Tag == "(PORTS)" {
+			continue
+		}
+		romDirectories[rom.Platform] = append(romDirectories[rom.Platform], rom.Item)
+	}
+
+	return romDirectories, nil
+}
+
+// SearchLibrary finds the ROMs in every platform and archive that match the query, and the collections whose names do.
+// ROMs come back grouped by platform with the ones still in the library ahead of archived ones, best match first.
+func SearchLibrary(query SearchQuery, gamePlayMap map[string][]models.PlayHistoryAggregate) ([]models.IndexedRom, []models.Collection, error) {
+	db, err := openLibraryIndex()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeDB(db)
+
+	if err := refreshLibraryIndex(db); err != nil {
+		return nil, nil, err
+	}
+
+	roms, err := queryIndexedRoms(db, "SELECT "+indexedEntryColumns+" FROM entries WHERE is_rom = 1")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	membership, err := queryCollectionMembership(db,
+		"SELECT e.path, c.name FROM entries e "+
+			"JOIN collection_games g ON g.entry = e.collection_path "+
+			"JOIN collections c ON c.file = g.file "+
+			"WHERE e.is_rom = 1")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range roms {
+		roms[i].Collections = membership[roms[i].Item.Path]
+	}
+
+	roms = slices.DeleteFunc(roms, func(rom models.IndexedRom) bool {
+		return !query.Matches(rom, gamePlayMap)
+	})
+
+	slices.SortFunc(roms, func(a, b models.IndexedRom) int {
+		if c := strings.Compare(a.Platform.DisplayName, b.Platform.DisplayName); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Archive, b.Archive); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(query.Score(searchName(b.Item)), query.Score(searchName(a.Item))); c != 0 {
+			return c
+		}
+		return strings.Compare(strings.ToLower(a.Item.Filename), strings.ToLower(b.Item.Filename))
+	})
+
+	rows, err := db.Query("SELECT file, name FROM collections ORDER BY name")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var collections []models.Collection
+	for rows.Next() {
+		var collection models.Collection
+		if err := rows.Scan(&collection.CollectionFile, &collection.DisplayName); err != nil {
+			return nil, nil, err
+		}
+
+		if query.MatchesName(collection.DisplayName) {
+			collections = append(collections, collection)
+		}
+	}
+
+	return roms, collections, rows.Err()
+}
+
+// ListLibraryRoms returns every ROM still in the library, leaving out archived ones, refreshing the index first.
+func ListLibraryRoms() ([]models.IndexedRom, error) {
+	db, err := openLibraryIndex()
+	if err != nil {
+		return nil, err
+	}
+	defer closeDB(db)
+
+	if err := refreshLibraryIndex(db); err != nil {
+		return nil, err
+	}
+
+	return queryIndexedRoms(db, "SELECT "+indexedEntryColumns+" FROM entries WHERE is_rom = 1 AND archive = '' "+
+		"ORDER BY lower(filename)")
+}
+
+// GetLibraryStatistics totals the library per platform. Archived ROMs are counted against the platform they came from
+// and only add to the archived count and the size.
+func GetLibraryStatistics() (models.LibraryStatistics, error) {
+	db, err := openLibraryIndex()
+	if err != nil {
+		return models.LibraryStatistics{}, err
+	}
+	defer closeDB(db)
+
+	if err := refreshLibraryIndex(db); err != nil {
+		return models.LibraryStatistics{}, err
+	}
+
+	roms, err := queryIndexedRoms(db, "SELECT "+indexedEntryColumns+" FROM entries WHERE is_rom = 1")
+	if err != nil {
+		return models.LibraryStatistics{}, err
+	}
+
+	platforms := make(map[string]*models.PlatformStatistics)
+	var statistics models.LibraryStatistics
+
+	for _, rom := range roms {
+		name := filepath.Base(rom.Platform.Path)
+		platform, ok := platforms[name]
+		if !ok {
+			platform = &models.PlatformStatistics{
+				Platform: platformDirectory(filepath.Join(GetRomDirectory(), name)),
+			}
+			platforms[name] = platform
+		}
+
+		platform.Size += rom.Size
+		statistics.Size += rom.Size
+
+		if rom.Archive != "" {
+			platform.Archived++
+			statistics.Archived++
+			continue
+		}
+
+		platform.Roms++
+		statistics.Roms++
+
+		if !rom.HasArt {
+			platform.MissingArt++
+			statistics.MissingArt++
+		}
+
+		if rom.HasSave {
+			platform.WithSaves++
+			statistics.WithSaves++
+		}
+	}
+
+	for _, platform := range platforms {
+		statistics.Platforms = append(statistics.Platforms, *platform)
+	}
+
+	slices.SortFunc(statistics.Platforms, func(a, b models.PlatformStatistics) int {
+		return strings.Compare(a.Platform.DisplayName, b.Platform.DisplayName)
+	})
+
+	return statistics, nil
+}
+
+func queryIndexedRoms(db *sql.DB, query string, args ...any) ([]models.IndexedRom, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roms []models.IndexedRom
+	for rows.Next() {
+		var rom models.IndexedRom
+		var platformPath string
+		var modTime int64
+
+		err := rows.Scan(&rom.Item.Path, &rom.Item.Filename, &rom.Item.DisplayName, &rom.Item.Tag, &platformPath,
+			&rom.Archive, &rom.Item.IsDirectory, &rom.Item.IsMultiDiscDirectory, &rom.Item.IsSelfContainedDirectory,
+			&rom.Size, &modTime, &rom.HasArt, &rom.HasSave)
+		if err != nil {
+			return nil, err
+		}
+
+		rom.Platform = platformDirectory(platformPath)
+		rom.ModTime = time.Unix(0, modTime)
+		roms = append(roms, rom)
+	}
+
+	return roms, rows.Err()
+}
+
+func queryCollectionMembership(db *sql.DB, query string, args ...any) (map[string][]string, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	membership := make(map[string][]string)
+	for rows.Next() {
+		var path, name string
+		if err := rows.Scan(&path, &name); err != nil {
+			return nil, err
+		}
+		membership[path] = append(membership[path], name)
+	}
+
+	for _, names := range membership {
+		slices.Sort(names)
+	}
+
+	return membership, rows.Err()
+}
+
+func platformDirectory(platformPath string) shared.RomDirectory {
+	if platformPath == "" {
+		return shared.RomDirectory{}
+	}
+
+	name := filepath.Base(platformPath)
+	tag := itemTagPattern.FindString(name)
+
+	displayName := name
+	if tag != "" {
+		displayName = strings.TrimSpace(strings.TrimSuffix(name, tag))
+	}
+
+	return shared.RomDirectory{
+		DisplayName: displayName,
+		Tag:         tag,
+		Path:        platformPath,
+	}
+}
+
+func directorySize(dirPath string) int64 {
+	var size int64
+
+	_ = afero.Walk(fileSystem, dirPath, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+
+	return size
+}
+
+func modTimeOf(path string) int64 {
+	info, err := fileSystem.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.ModTime().UnixNano()
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// useLibraryIndex gives the fake SD card a library index in a temporary folder. The index needs the cgo SQLite
+// driver, so the test is skipped in builds without it.
+func useLibraryIndex(t *testing.T) *sql.DB {
+	t.Helper()
+
+	testLayout := GetLayout()
+	testLayout.LibraryIndexPath = filepath.Join(t.TempDir(), libraryIndexFile)
+	SetLayout(testLayout)
+
+	db, err := openLibraryIndex()
+	if err != nil {
+		t.Skipf("library index unavailable: %v", err)
+	}
+	t.Cleanup(func() { closeDB(db) })
+
+	return db
+}
+
+func TestRefreshLibraryIndex(t *testing.T) {
+	useFakeSDCard(t)
+
+	writeTestFile(t, filepath.Join(testPlatform, "Tetris.gb"), "rom")
+	writeTestFile(t, filepath.Join(testPlatform, "Dr. Mario.gb"), "rom")
+	writeTestFile(t, filepath.Join(testPlatform, ".media", "Tetris.png"), "art")
+	writeTestFile(t, filepath.Join(testSaves, "GB", "Tetris.gb.sav"), "save")
+	writeTestFile(t, filepath.Join(testCollections, "Puzzle.txt"), "/Roms/Game Boy (GB)/Tetris.gb\n")
+
+	db := useLibraryIndex(t)
+	if err := refreshLibraryIndex(db); err != nil {
+		t.Fatalf("refreshLibraryIndex: %v", err)
+	}
+
+	roms, err := ListIndexedDirectory(testPlatform)
+	if err != nil {
+		t.Fatalf("ListIndexedDirectory: %v", err)
+	}
+	if len(roms) != 2 || roms[0].Item.Filename != "Dr. Mario.gb" || roms[1].Item.Filename != "Tetris.gb" {
+		t.Fatalf("index lists %+v", roms)
+	}
+	if tetris := roms[1]; !tetris.HasArt || !tetris.HasSave || len(tetris.Collections) != 1 || tetris.Collections[0] != "Puzzle" {
+		t.Errorf("Tetris is indexed as %+v", tetris)
+	}
+	if drMario := roms[0]; drMario.HasArt || drMario.HasSave || len(drMario.Collections) != 0 {
+		t.Errorf("Dr. Mario is indexed as %+v", drMario)
+	}
+
+	if err := fileSystem.Remove(filepath.Join(testPlatform, "Dr. Mario.gb")); err != nil {
+		t.Fatalf("removing Dr. Mario: %v", err)
+	}
+	writeTestFile(t, filepath.Join(testPlatform, "Tetris DX.gbc"), "rom")
+	later := time.Now().Add(time.Minute)
+	if err := fileSystem.Chtimes(testPlatform, later, later); err != nil {
+		t.Fatalf("touching %s: %v", testPlatform, err)
+	}
+
+	if err := refreshLibraryIndex(db); err != nil {
+		t.Fatalf("refreshLibraryIndex: %v", err)
+	}
+
+	roms, err = ListIndexedDirectory(testPlatform)
+	if err != nil {
+		t.Fatalf("ListIndexedDirectory: %v", err)
+	}
+	if len(roms) != 2 || roms[0].Item.Filename != "Tetris DX.gbc" || roms[1].Item.Filename != "Tetris.gb" {
+		t.Errorf("index lists %+v after the folder changed", roms)
+	}
+}
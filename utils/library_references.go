@@ -0,0 +1,343 @@
+package utils
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
+	"github.com/spf13/afero"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+	"nextui-game-manager/models"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const archivedReferencesFile = "archived_references.yml"
+
+// archivedReference remembers where an archived game was listed in a collection or the recently played list, so the
+// entry can be put back when the game is restored. Archived games are taken out of those lists since NextUI would
+// otherwise still show and launch them from the archive.
+type archivedReference struct {
+	ArchivedPath string `yaml:"archived_path"`
+	OriginalPath string `yaml:"original_path"`
+	File         string `yaml:"file"`
+	Line         string `yaml:"line"`
+	Index        int    `yaml:"index"`
+}
+
+// moveLibraryReferences updates the collections and the recently played list after a ROM, multi-disc folder or
+// archive moved from oldPath to newPath. Moves within the library rewrite the entries in place, archiving takes them
+// out and remembers them, and restoring puts them back. Calling it again with the paths swapped undoes it.
+func moveLibraryReferences(oldPath string, newPath string) error {
+	wasArchived := isArchivedPath(oldPath)
+	isArchived := isArchivedPath(newPath)
+
+	switch {
+	case !wasArchived && !isArchived:
+		oldEntry, newEntry := collectionEntryFor(oldPath), collectionEntryFor(newPath)
+		if err := moveCollectionOrderEntries(oldEntry, newEntry); err != nil {
+			return err
+		}
+		return rewriteReferenceFiles(func(file string, lines []string) ([]string, bool) {
+			lines, changed := rewriteReferenceLines(lines, oldEntry, newEntry)
+			if changed {
+				lines = sortCollectionLines(file, lines)
+			}
+			return lines, changed
+		})
+	case !wasArchived && isArchived:
+		return archiveReferences(oldPath, newPath)
+	case wasArchived && !isArchived:
+		return restoreReferences(oldPath, newPath)
+	default:
+		return moveArchivedReferences(oldPath, newPath)
+	}
+}
+
+func archiveReferences(oldPath string, archivedPath string) error {
+	references, err := loadArchivedReferences()
+	if err != nil {
+		return err
+	}
+
+	oldEntry := collectionEntryFor(oldPath)
+
+	err = rewriteReferenceFiles(func(file string, lines []string) ([]string, bool) {
+		var kept []string
+		for index, line := range lines {
+			if containsEntryPath(referenceLinePath(line), oldEntry) {
+				references = append(references, archivedReference{
+					ArchivedPath: archivedPath,
+					OriginalPath: oldPath,
+					File:         file,
+					Line:         line,
+					Index:        index,
+				})
+				continue
+			}
+			kept = append(kept, line)
+		}
+		return kept, len(kept) != len(lines)
+	})
+	if err != nil {
+		return err
+	}
+
+	return saveArchivedReferences(references)
+}
+
+func restoreReferences(archivedPath string, restoredPath string) error {
+	references, err := loadArchivedReferences()
+	if err != nil {
+		return err
+	}
+
+	var restoring []archivedReference
+	references = slices.DeleteFunc(references, func(reference archivedReference) bool {
+		if containsEntryPath(reference.ArchivedPath, archivedPath) {
+			restoring = append(restoring, reference)
+			return true
+		}
+		return false
+	})
+
+	if len(restoring) == 0 {
+		return nil
+	}
+
+	// Put entries back in the order they were listed so their indexes still line up
+	slices.SortStableFunc(restoring, func(a, b archivedReference) int {
+		return cmp.Compare(a.Index, b.Index)
+	})
+
+	err = rewriteReferenceFiles(func(file string, lines []string) ([]string, bool) {
+		changed := false
+		for _, reference := range restoring {
+			if reference.File != file {
+				continue
+			}
+
+			gamePath, _ := movedEntryPath(reference.ArchivedPath, archivedPath, restoredPath)
+			linePath := referenceLinePath(reference.Line)
+			newLinePath, _ := movedEntryPath(linePath, collectionEntryFor(reference.OriginalPath), collectionEntryFor(gamePath))
+			line := newLinePath + strings.TrimPrefix(reference.Line, linePath)
+
+			if slices.ContainsFunc(lines, func(existing string) bool { return referenceLinePath(existing) == newLinePath }) {
+				continue
+			}
+
+			lines = slices.Insert(lines, min(reference.Index, len(lines)), line)
+			changed = true
+		}
+
+		// Games added while these were archived can have shifted where a kept sort order puts them
+		if changed {
+			lines = sortCollectionLines(file, lines)
+		}
+		return lines, changed
+	})
+	if err != nil {
+		return err
+	}
+
+	return saveArchivedReferences(references)
+}
+
+// moveArchivedReferences follows an archived game, or a whole archive, to its new place inside the archives.
+func moveArchivedReferences(oldPath string, newPath string) error {
+	references, err := loadArchivedReferences()
+	if err != nil || len(references) == 0 {
+		return err
+	}
+
+	changed := false
+	for i, reference := range references {
+		if archivedPath, moved := movedEntryPath(reference.ArchivedPath, oldPath, newPath); moved {
+			references[i].ArchivedPath = archivedPath
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+	return saveArchivedReferences(references)
+}
+
+// forgetArchivedReferences drops the remembered collection and recently played entries of games below archivedPath
+// that are gone for good.
+func forgetArchivedReferences(archivedPath string) error {
+	references, err := loadArchivedReferences()
+	if err != nil || len(references) == 0 {
+		return err
+	}
+
+	kept := slices.DeleteFunc(slices.Clone(references), func(reference archivedReference) bool {
+		return containsEntryPath(reference.ArchivedPath, archivedPath)
+	})
+
+	if len(kept) == len(references) {
+		return nil
+	}
+	return saveArchivedReferences(kept)
+}
+
+// rewriteReferenceFiles hands the lines of every collection and of the recently played list to rewrite and writes
+// back the files it changed.
+func rewriteReferenceFiles(rewrite func(file string, lines []string) ([]string, bool)) error {
+	logger := common.GetLoggerInstance()
+
+	collections, err := listCollectionItems()
+	if err != nil {
+		logger.Info("No collections to update", zap.Error(err))
+	}
+
+	var files []string
+	for _, collection := range collections {
+		files = append(files, collection.Path)
+	}
+	files = append(files, GetRecentlyPlayedFile())
+
+	for _, file := range files {
+		if file == "" || !DoesFileExists(file) {
+			continue
+		}
+
+		lines, err := readReferenceLines(file)
+		if err != nil {
+			return err
+		}
+
+		lines, changed := rewrite(file, lines)
+		if !changed {
+			continue
+		}
+
+		if err := writeReferenceLines(file, lines); err != nil {
+			return err
+		}
+
+		if file != GetRecentlyPlayedFile() {
+			publishLibraryChange(models.LibraryChange{
+				Kind: models.LibraryChangeKinds.CollectionSaved,
+				Path: file,
+			})
+		}
+	}
+
+	return nil
+}
+
+func rewriteReferenceLines(lines []string, oldEntry string, newEntry string) ([]string, bool) {
+	changed := false
+	for i, line := range lines {
+		linePath := referenceLinePath(line)
+		if newLinePath, moved := movedEntryPath(linePath, oldEntry, newEntry); moved {
+			lines[i] = newLinePath + strings.TrimPrefix(line, linePath)
+			changed = true
+		}
+	}
+	return lines, changed
+}
+
+// movedEntryPath works out where path is after oldPath moved to newPath, for the path itself and anything inside it.
+// A multi-disc game's playlist is named after its folder, so it is renamed along with it.
+func movedEntryPath(path string, oldPath string, newPath string) (string, bool) {
+	if path == oldPath {
+		return newPath, true
+	}
+
+	rest, inside := strings.CutPrefix(path, oldPath+"/")
+	if !inside {
+		return "", false
+	}
+
+	if removeFileExtension(rest) == filepath.Base(oldPath) {
+		rest = filepath.Base(newPath) + filepath.Ext(rest)
+	}
+
+	return newPath + "/" + rest, true
+}
+
+func containsEntryPath(path string, root string) bool {
+	return path == root || strings.HasPrefix(path, root+"/")
+}
+
+// referenceLinePath is the path of a collection or recently played entry. Recently played entries may have a tab
+// and an alias after the path.
+func referenceLinePath(line string) string {
+	path, _, _ := strings.Cut(line, "\t")
+	return path
+}
+
+// collectionEntryFor turns a path on the SD card into the form collections and the recently played list use.
+func collectionEntryFor(path string) string {
+	return strings.Replace(path, GetRomDirectory()+"/", "/Roms/", 1)
+}
+
+func isArchivedPath(path string) bool {
+	_, archive := indexScope(path)
+	return archive != ""
+}
+
+func readReferenceLines(file string) ([]string, error) {
+	handle, err := fileSystem.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", file, err)
+	}
+	defer handle.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(handle)
+	for scanner.Scan() {
+		if line := strings.TrimRight(scanner.Text(), "\r"); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return lines, nil
+}
+
+func writeReferenceLines(file string, lines []string) error {
+	var content strings.Builder
+	for _, line := range lines {
+		content.WriteString(line + "\n")
+	}
+
+	if err := afero.WriteFile(fileSystem, file, []byte(content.String()), defaultFilePerm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return nil
+}
+
+func loadArchivedReferences() ([]archivedReference, error) {
+	if !DoesFileExists(GetArchivedReferencesPath()) {
+		return nil, nil
+	}
+
+	data, err := afero.ReadFile(fileSystem, GetArchivedReferencesPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archived references: %w", err)
+	}
+
+	var references []archivedReference
+	if err := yaml.Unmarshal(data, &references); err != nil {
+		return nil, fmt.Errorf("failed to parse archived references: %w", err)
+	}
+
+	return references, nil
+}
+
+func saveArchivedReferences(references []archivedReference) error {
+	data, err := yaml.Marshal(references)
+	if err != nil {
+		return fmt.Errorf("failed to encode archived references: %w", err)
+	}
+
+	return afero.WriteFile(fileSystem, GetArchivedReferencesPath(), data, defaultFilePerm)
+}
@@ -0,0 +1,309 @@
+package utils
+
+import (
+	"fmt"
+	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"github.com/spf13/afero"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+	"nextui-game-manager/models"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	recentlyPlayedPinsFile = "recently_played_pins.yml"
+
+	// recentlyPlayedLimit is how many games NextUI keeps in its recently played list.
+	recentlyPlayedLimit = 24
+)
+
+func init() {
+	SubscribeLibraryChanges(followRecentlyPlayedPinChanges)
+}
+
+// ReadRecentlyPlayed parses NextUI's recently played list. A missing list reads as empty.
+func ReadRecentlyPlayed() ([]models.RecentlyPlayedEntry, error) {
+	if !DoesFileExists(GetRecentlyPlayedFile()) {
+		return nil, nil
+	}
+
+	lines, err := readReferenceLines(GetRecentlyPlayedFile())
+	if err != nil {
+		return nil, err
+	}
+
+	pins, err := loadRecentlyPlayedPins()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []models.RecentlyPlayedEntry
+	for _, line := range lines {
+		path, alias, _ := strings.Cut(line, "\t")
+		entries = append(entries, models.RecentlyPlayedEntry{
+			Path:   path,
+			Alias:  alias,
+			Pinned: slices.Contains(pins, path),
+		})
+	}
+	return entries, nil
+}
+
+// SaveRecentlyPlayed writes the recently played list in the given order, with pinned entries moved to the top, and
+// remembers which entries are pinned.
+func SaveRecentlyPlayed(entries []models.RecentlyPlayedEntry) error {
+	entries = slices.Clone(entries)
+	slices.SortStableFunc(entries, func(a, b models.RecentlyPlayedEntry) int {
+		switch {
+		case a.Pinned == b.Pinned:
+			return 0
+		case a.Pinned:
+			return -1
+		default:
+			return 1
+		}
+	})
+
+	var lines []string
+	var pins []string
+	for _, entry := range entries {
+		line := entry.Path
+		if entry.Alias != "" {
+			line += "\t" + entry.Alias
+		}
+		lines = append(lines, line)
+
+		if entry.Pinned {
+			pins = append(pins, entry.Path)
+		}
+	}
+
+	if err := writeReferenceLines(GetRecentlyPlayedFile(), lines); err != nil {
+		return err
+	}
+
+	return saveRecentlyPlayedPins(pins)
+}
+
+// RecentlyPlayedName is the name NextUI shows for an entry.
+func RecentlyPlayedName(entry models.RecentlyPlayedEntry) string {
+	if entry.Alias != "" {
+		return entry.Alias
+	}
+	return removeFileExtension(filepath.Base(entry.Path))
+}
+
+// RecentlyPlayedArtPath returns the art for an entry, or an empty path when it has none.
+func RecentlyPlayedArtPath(entry models.RecentlyPlayedEntry) string {
+	return collectionGameArtPath(shared.Item{Path: entry.Path})
+}
+
+// RecentlyPlayedExists reports whether the game an entry launches is still on the SD card.
+func RecentlyPlayedExists(entry models.RecentlyPlayedEntry) bool {
+	return DoesFileExists(collectionEntryPath(entry.Path))
+}
+
+// PruneRecentlyPlayed drops entries whose game is gone and returns how many were dropped.
+func PruneRecentlyPlayed() (int, error) {
+	entries, err := ReadRecentlyPlayed()
+	if err != nil {
+		return 0, err
+	}
+
+	kept := slices.DeleteFunc(slices.Clone(entries), func(entry models.RecentlyPlayedEntry) bool {
+		return !RecentlyPlayedExists(entry)
+	})
+
+	pruned := len(entries) - len(kept)
+	if pruned == 0 {
+		return 0, nil
+	}
+
+	return pruned, SaveRecentlyPlayed(kept)
+}
+
+// RebuildRecentlyPlayed replaces the recently played list with the most recently played games from the game tracker.
+// Pinned entries stay on top and aliases are kept for games that were already listed.
+func RebuildRecentlyPlayed() (int, error) {
+	entries, err := ReadRecentlyPlayed()
+	if err != nil {
+		return 0, err
+	}
+
+	paths, err := recentGameTrackerPaths()
+	if err != nil {
+		return 0, err
+	}
+
+	var rebuilt []models.RecentlyPlayedEntry
+	for _, entry := range entries {
+		if entry.Pinned && RecentlyPlayedExists(entry) {
+			rebuilt = append(rebuilt, entry)
+		}
+	}
+
+	for _, path := range paths {
+		if len(rebuilt) >= recentlyPlayedLimit {
+			break
+		}
+
+		if slices.ContainsFunc(rebuilt, func(entry models.RecentlyPlayedEntry) bool { return entry.Path == path }) {
+			continue
+		}
+
+		entry := models.RecentlyPlayedEntry{Path: path}
+		if index := slices.IndexFunc(entries, func(existing models.RecentlyPlayedEntry) bool { return existing.Path == path }); index != -1 {
+			entry.Alias = entries[index].Alias
+		}
+
+		if RecentlyPlayedExists(entry) {
+			rebuilt = append(rebuilt, entry)
+		}
+	}
+
+	return len(rebuilt), SaveRecentlyPlayed(rebuilt)
+}
+
+// ApplyRecentlyPlayedPins puts pinned games back on top of the recently played list, since NextUI moves whatever was
+// played last to the top and drops games that fall off the end. Pins on games that are gone are forgotten.
+func ApplyRecentlyPlayedPins() error {
+	pins, err := loadRecentlyPlayedPins()
+	if err != nil || len(pins) == 0 {
+		return err
+	}
+
+	entries, err := ReadRecentlyPlayed()
+	if err != nil {
+		return err
+	}
+
+	var pinned []models.RecentlyPlayedEntry
+	for _, pin := range pins {
+		entry := models.RecentlyPlayedEntry{Path: pin, Pinned: true}
+		if index := slices.IndexFunc(entries, func(existing models.RecentlyPlayedEntry) bool { return existing.Path == pin }); index != -1 {
+			entry = entries[index]
+		} else if !RecentlyPlayedExists(entry) {
+			continue
+		}
+		pinned = append(pinned, entry)
+	}
+
+	rest := slices.DeleteFunc(slices.Clone(entries), func(entry models.RecentlyPlayedEntry) bool { return entry.Pinned })
+	ordered := append(pinned, rest...)
+	ordered = ordered[:min(len(ordered), recentlyPlayedLimit)]
+
+	if slices.Equal(ordered, entries) && len(pinned) == len(pins) {
+		return nil
+	}
+	return SaveRecentlyPlayed(ordered)
+}
+
+// recentGameTrackerPaths lists the games in the game tracker as recently played entries, most recently played first.
+// Multi-disc games are listed by their playlist the way NextUI lists them, and archived games are left out.
+func recentGameTrackerPaths() ([]string, error) {
+	if !DoesFileExists(GetGameTrackerDBPath()) {
+		return nil, fmt.Errorf("no game tracker data")
+	}
+
+	db, err := openGameTrackerDB()
+	if err != nil {
+		return nil, err
+	}
+	defer closeDB(db)
+
+	rows, err := db.Query("SELECT rom.file_path, MAX(play_activity.created_at) AS last_played_at " +
+		"FROM rom " +
+		"JOIN play_activity ON rom.id = play_activity.rom_id " +
+		"GROUP BY rom.id " +
+		"ORDER BY last_played_at DESC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query game tracker: %w", err)
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var filePath string
+		var lastPlayed int
+		if err := rows.Scan(&filePath, &lastPlayed); err != nil {
+			return nil, fmt.Errorf("failed to read game tracker: %w", err)
+		}
+
+		// Archived games keep their play history but cannot be launched from the list
+		if _, archive := indexScope(gameTrackerFullPath(filePath)); archive != "" {
+			continue
+		}
+
+		path := "/Roms/" + filePath
+
+		folder := filepath.Dir(path)
+		playlist := filepath.Join(folder, filepath.Base(folder)+".m3u")
+		if DoesFileExists(collectionEntryPath(playlist)) {
+			path = playlist
+		}
+
+		if !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths, rows.Err()
+}
+
+func loadRecentlyPlayedPins() ([]string, error) {
+	if !DoesFileExists(GetRecentlyPlayedPinsPath()) {
+		return nil, nil
+	}
+
+	data, err := afero.ReadFile(fileSystem, GetRecentlyPlayedPinsPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recently played pins: %w", err)
+	}
+
+	var pins []string
+	if err := yaml.Unmarshal(data, &pins); err != nil {
+		return nil, fmt.Errorf("failed to parse recently played pins: %w", err)
+	}
+	return pins, nil
+}
+
+func saveRecentlyPlayedPins(pins []string) error {
+	data, err := yaml.Marshal(pins)
+	if err != nil {
+		return fmt.Errorf("failed to encode recently played pins: %w", err)
+	}
+
+	return afero.WriteFile(fileSystem, GetRecentlyPlayedPinsPath(), data, defaultFilePerm)
+}
+
+// followRecentlyPlayedPinChanges keeps pins on renamed games. Archived and deleted games lose their pin when the pins
+// are next applied, as there is nothing left to launch.
+func followRecentlyPlayedPinChanges(change models.LibraryChange) {
+	if change.Kind != models.LibraryChangeKinds.RomRenamed {
+		return
+	}
+
+	pins, err := loadRecentlyPlayedPins()
+	if err != nil || len(pins) == 0 {
+		return
+	}
+
+	changed := false
+	for i, pin := range pins {
+		if path, moved := movedEntryPath(pin, collectionEntryFor(change.PreviousPath), collectionEntryFor(change.Path)); moved {
+			pins[i] = path
+			changed = true
+		}
+	}
+
+	if !changed {
+		return
+	}
+
+	if err := saveRecentlyPlayedPins(pins); err != nil {
+		common.GetLoggerInstance().Error("Unable to update recently played pins", zap.Error(err))
+	}
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestApplyRecentlyPlayedPins(t *testing.T) {
+	useFakeSDCard(t)
+
+	var lines []string
+	for i := range recentlyPlayedLimit {
+		name := fmt.Sprintf("Game %02d.gb", i)
+		writeTestFile(t, filepath.Join(testPlatform, name), "rom")
+		lines = append(lines, "/Roms/Game Boy (GB)/"+name)
+	}
+	writeTestFile(t, testRecents, strings.Join(lines, "\n")+"\n")
+	writeTestFile(t, filepath.Join(testPlatform, "Tetris.gb"), "rom")
+
+	pins := []string{"/Roms/Game Boy (GB)/Tetris.gb", "/Roms/Game Boy (GB)/Deleted.gb"}
+	if err := saveRecentlyPlayedPins(pins); err != nil {
+		t.Fatalf("saveRecentlyPlayedPins: %v", err)
+	}
+
+	if err := ApplyRecentlyPlayedPins(); err != nil {
+		t.Fatalf("ApplyRecentlyPlayedPins: %v", err)
+	}
+
+	entries, err := ReadRecentlyPlayed()
+	if err != nil {
+		t.Fatalf("ReadRecentlyPlayed: %v", err)
+	}
+	if len(entries) != recentlyPlayedLimit || entries[0].Path != pins[0] || !entries[0].Pinned {
+		t.Errorf("recently played %+v, want Tetris pinned on top of %d games", entries, recentlyPlayedLimit)
+	}
+	if entries[len(entries)-1].Path != lines[len(lines)-2] {
+		t.Errorf("last game %s, want the oldest to fall off the end", entries[len(entries)-1].Path)
+	}
+
+	if kept, _ := loadRecentlyPlayedPins(); !slices.Equal(kept, pins[:1]) {
+		t.Errorf("pins %v, want the deleted game's pin forgotten", kept)
+	}
+}
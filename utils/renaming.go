@@ -3,40 +3,40 @@ package utils
 import (
 	"fmt"
 	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
-	"github.com/UncleJunVIP/nextui-pak-shared-functions/filebrowser"
 	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
 	"go.uber.org/zap"
 	"nextui-game-manager/models"
-	"os"
 	"path/filepath"
 	"strings"
 )
 
-func renameSaveFile(oldFilename, newFilename string, romDirectory shared.RomDirectory) {
+func renameSaveFile(op *operation, oldFilename, newFilename string, romDirectory shared.RomDirectory) error {
 	logger := common.GetLoggerInstance()
 
 	tag := cleanTag(romDirectory.Tag)
 
 	saveDir := filepath.Join(GetSaveFileDirectory(), tag)
-	fb := filebrowser.NewFileBrowser(logger)
 
-	if err := fb.CWD(saveDir, true); err != nil {
-		logger.Error("Failed to access save directory", zap.String("dir", saveDir), zap.Error(err))
-		return
+	saveItems, err := listDirectoryItems(saveDir)
+	if err != nil {
+		logger.Info("No save directory to search", zap.String("dir", saveDir), zap.Error(err))
+		return nil
 	}
 
-	saveFile := findSaveFile(fb.Items, oldFilename)
+	saveFile := findSaveFile(saveItems, oldFilename)
 	if saveFile.Filename == "" {
 		logger.Info("No save file found to rename")
-		return
+		return nil
 	}
 
 	ext := strings.ReplaceAll(saveFile.Filename, removeFileExtension(oldFilename), "")
 	newSavePath := filepath.Join(saveDir, newFilename+ext)
 
-	if err := MoveFile(saveFile.Path, newSavePath); err != nil {
-		logger.Error("Failed to rename save file", zap.Error(err))
+	if err := op.move(saveFile.Path, newSavePath); err != nil {
+		return fmt.Errorf("failed to rename save file: %w", err)
 	}
+
+	return nil
 }
 
 func RenameCollection(collection models.Collection, name string) (models.Collection, error) {
@@ -44,26 +44,22 @@ func RenameCollection(collection models.Collection, name string) (models.Collect
 
 	newPath := filepath.Join(filepath.Dir(collection.CollectionFile), name+".txt")
 
-	if err := os.Rename(collection.CollectionFile, newPath); err != nil {
+	if err := fileSystem.Rename(collection.CollectionFile, newPath); err != nil {
 		logger.Error("Failed to rename collection file", zap.Error(err))
 		return models.Collection{}, fmt.Errorf("failed to rename collection: %w", err)
 	}
 
+	publishLibraryChange(models.LibraryChange{
+		Kind:         models.LibraryChangeKinds.CollectionRenamed,
+		Path:         newPath,
+		PreviousPath: collection.CollectionFile,
+	})
+
 	collection.DisplayName = name
 	collection.CollectionFile = newPath
 	return collection, nil
 }
 
-func renameCollectionEntries(game shared.Item, oldDisplayName string, romDirectory shared.RomDirectory) {
-	logger := common.GetLoggerInstance()
-
-	collections := findCollectionsContainingGame(game, logger)
-	for _, collection := range collections {
-		updateCollectionGamePath(collection, oldDisplayName, game, romDirectory)
-		SaveCollection(collection)
-	}
-}
-
 func RenameRom(game shared.Item, newFilename string, romDirectory shared.RomDirectory) (string, error) {
 	logger := common.GetLoggerInstance()
 
@@ -72,17 +68,43 @@ func RenameRom(game shared.Item, newFilename string, romDirectory shared.RomDire
 
 	logger.Debug("Renaming ROM", zap.String("from", oldPath), zap.String("to", newPath))
 
-	if err := MoveFile(oldPath, newPath); err != nil {
+	op, err := beginOperation(fmt.Sprintf("Rename %s", game.Filename))
+	if err != nil {
+		return "", err
+	}
+
+	if err := op.move(oldPath, newPath); err != nil {
+		op.rollback()
 		return "", fmt.Errorf("failed to rename ROM file: %w", err)
 	}
 
-	renameAssociatedFile(game.Filename, newFilename, newPath, ".cue")
-	renameAssociatedFile(game.Filename, newFilename, newPath, ".m3u")
+	steps := []func() error{
+		func() error { return renameAssociatedFile(op, game.Filename, newFilename, newPath, ".cue") },
+		func() error { return renameAssociatedFile(op, game.Filename, newFilename, newPath, ".m3u") },
+		func() error {
+			updateGameTrackerForRename(op, game.Filename, newFilename, romDirectory, logger)
+			return nil
+		},
+		func() error { return renameSaveFile(op, game.Filename, newFilename, romDirectory) },
+		func() error { return op.moveReferences(oldPath, newPath) },
+		func() error { return renameArtFile(op, game.Filename, newFilename, romDirectory) },
+	}
+
+	for _, step := range steps {
+		if err := step(); err != nil {
+			logger.Error("Rename failed, rolling back", zap.Error(err))
+			op.rollback()
+			return "", err
+		}
+	}
+
+	op.commit()
 
-	updateGameTrackerForRename(game.Filename, newFilename, romDirectory, logger)
-	renameSaveFile(game.Filename, newFilename, romDirectory)
-	// renameCollectionEntries(game, game.Filename, romDirectory) TODO need to finish this functionality
-	renameArtFile(game.Filename, newFilename, romDirectory, logger)
+	publishLibraryChange(models.LibraryChange{
+		Kind:         models.LibraryChangeKinds.RomRenamed,
+		Path:         newPath,
+		PreviousPath: oldPath,
+	})
 
 	return filepath.Base(newPath), nil
 }
@@ -92,24 +114,20 @@ func buildNewRomPath(romDirectoryPath, newFilename, oldFilename string) string {
 	return filepath.Join(romDirectoryPath, newFilename+ext)
 }
 
-func renameAssociatedFile(oldFilename string, newFilename string, newPath string, extension string) {
-	logger := common.GetLoggerInstance()
-
+func renameAssociatedFile(op *operation, oldFilename string, newFilename string, newPath string, extension string) error {
 	oldAssociatedFilename := removeFileExtension(oldFilename) + extension
 	oldAssociatedPath := filepath.Join(newPath, oldAssociatedFilename)
 
 	if !DoesFileExists(oldAssociatedPath) {
-		return
+		return nil
 	}
 
 	newAssociatedFilename := newFilename + extension
 	newAssociatedPath := filepath.Join(newPath, newAssociatedFilename)
 
-	if err := MoveFile(oldAssociatedPath, newAssociatedPath); err != nil {
-		logger.Error("Failed to rename associated file",
-			zap.String("from", oldAssociatedPath),
-			zap.String("to", newAssociatedPath),
-			zap.String("extension", extension),
-			zap.Error(err))
+	if err := op.move(oldAssociatedPath, newAssociatedPath); err != nil {
+		return fmt.Errorf("failed to rename associated %s file: %w", extension, err)
 	}
+
+	return nil
 }
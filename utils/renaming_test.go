@@ -0,0 +1,45 @@
+package utils
+
+import (
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenameRom(t *testing.T) {
+	useFakeSDCard(t)
+
+	writeTestFile(t, filepath.Join(testPlatform, "Tetris.gb"), "rom")
+	writeTestFile(t, filepath.Join(testPlatform, ".media", "Tetris.png"), "art")
+	writeTestFile(t, filepath.Join(testSaves, "GB", "Tetris.gb.sav"), "save")
+	writeTestFile(t, filepath.Join(testCollections, "Puzzle.txt"), "/Roms/Game Boy (GB)/Tetris.gb\n/Roms/Game Boy (GB)/Dr. Mario.gb\n")
+	writeTestFile(t, testRecents, "/Roms/Game Boy (GB)/Tetris.gb\tTetris\n")
+
+	game := shared.Item{DisplayName: "Tetris", Filename: "Tetris.gb", Path: filepath.Join(testPlatform, "Tetris.gb")}
+
+	filename, err := RenameRom(game, "Tetris DX", testRomDirectory)
+	if err != nil {
+		t.Fatalf("RenameRom: %v", err)
+	}
+	if filename != "Tetris DX.gb" {
+		t.Errorf("RenameRom returned %q, want %q", filename, "Tetris DX.gb")
+	}
+
+	assertMissing(t,
+		filepath.Join(testPlatform, "Tetris.gb"),
+		filepath.Join(testPlatform, ".media", "Tetris.png"),
+		filepath.Join(testSaves, "GB", "Tetris.gb.sav"),
+		GetOperationJournalPath())
+	assertExists(t,
+		filepath.Join(testPlatform, "Tetris DX.gb"),
+		filepath.Join(testPlatform, ".media", "Tetris DX.png"),
+		filepath.Join(testSaves, "GB", "Tetris DX.gb.sav"))
+
+	if got, want := readTestFile(t, filepath.Join(testCollections, "Puzzle.txt")),
+		"/Roms/Game Boy (GB)/Tetris DX.gb\n/Roms/Game Boy (GB)/Dr. Mario.gb\n"; got != want {
+		t.Errorf("collection after rename:\n%s\nwant:\n%s", got, want)
+	}
+	if got, want := readTestFile(t, testRecents), "/Roms/Game Boy (GB)/Tetris DX.gb\tTetris\n"; got != want {
+		t.Errorf("recently played after rename: %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,335 @@
+package utils
+
+import (
+	"cmp"
+	"fmt"
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"nextui-game-manager/models"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var filenameTagPattern = regexp.MustCompile(`\(([^()]*)\)`)
+
+var searchFilterNames = []string{"region", "has", "played", "in", "ext", "archived"}
+
+// SearchQuery is a parsed search such as `mario region:usa has:art -has:save played:>2h in:"RPG Backlog"`. Plain
+// words are matched fuzzily against the name, see FuzzyScore; quoted text must appear as is; every filter must hold; a
+// leading - inverts a word or filter.
+type SearchQuery struct {
+	words   []string
+	clauses []searchClause
+}
+
+type searchClause struct {
+	negate       bool
+	text         string
+	usesPlayTime bool
+	match        func(rom models.IndexedRom, playTime int) bool
+}
+
+type searchToken struct {
+	value  string
+	quoted bool
+}
+
+// ParseSearchQuery turns what was typed into the search box into a SearchQuery. The error explains what is wrong in a
+// form that can be shown on screen as is.
+func ParseSearchQuery(query string) (SearchQuery, error) {
+	tokens, err := tokenizeSearchQuery(query)
+	if err != nil {
+		return SearchQuery{}, err
+	}
+
+	var parsed SearchQuery
+	for _, token := range tokens {
+		if !token.quoted && !strings.HasPrefix(token.value, "-") && !strings.Contains(token.value, ":") {
+			parsed.words = append(parsed.words, token.value)
+			continue
+		}
+
+		clause, err := parseSearchClause(token)
+		if err != nil {
+			return SearchQuery{}, err
+		}
+		parsed.clauses = append(parsed.clauses, clause)
+	}
+
+	return parsed, nil
+}
+
+func tokenizeSearchQuery(query string) ([]searchToken, error) {
+	var tokens []searchToken
+	var current strings.Builder
+	inQuote := false
+	quoted := false
+
+	flush := func() {
+		if current.Len() > 0 || quoted {
+			tokens = append(tokens, searchToken{value: current.String(), quoted: quoted})
+		}
+		current.Reset()
+		quoted = false
+	}
+
+	for _, r := range query {
+		switch {
+		case r == '"':
+			if !inQuote && current.Len() == 0 {
+				quoted = true
+			}
+			inQuote = !inQuote
+		case unicode.IsSpace(r) && !inQuote:
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	if inQuote {
+		return nil, fmt.Errorf("a quote is missing its closing \"")
+	}
+
+	flush()
+	return tokens, nil
+}
+
+func parseSearchClause(token searchToken) (searchClause, error) {
+	value := token.value
+	clause := searchClause{}
+
+	if !token.quoted && strings.HasPrefix(value, "-") && len(value) > 1 {
+		clause.negate = true
+		value = value[1:]
+	}
+
+	name, argument, isFilter := strings.Cut(value, ":")
+	if token.quoted || !isFilter {
+		clause.text = value
+		clause.match = func(rom models.IndexedRom, _ int) bool {
+			return matchesAnyKeyword(rom.Item.Filename, []string{clause.text})
+		}
+		return clause, nil
+	}
+
+	name = strings.ToLower(name)
+	if !slices.Contains(searchFilterNames, name) {
+		return clause, fmt.Errorf("unknown filter %q, try %s", name+":", strings.Join(searchFilterNames, ", "))
+	}
+
+	if argument == "" {
+		return clause, fmt.Errorf("%s: needs a value, e.g. %s", name, searchFilterExample(name))
+	}
+
+	lowerArgument := strings.ToLower(argument)
+
+	switch name {
+	case "region":
+		clause.match = func(rom models.IndexedRom, _ int) bool { return romHasRegion(rom, lowerArgument) }
+	case "has":
+		switch lowerArgument {
+		case "art":
+			clause.match = func(rom models.IndexedRom, _ int) bool { return rom.HasArt }
+		case "save":
+			clause.match = func(rom models.IndexedRom, _ int) bool { return rom.HasSave }
+		case "collection":
+			clause.match = func(rom models.IndexedRom, _ int) bool { return len(rom.Collections) > 0 }
+		case "played":
+			clause.usesPlayTime = true
+			clause.match = func(_ models.IndexedRom, playTime int) bool { return playTime > 0 }
+		default:
+			return clause, fmt.Errorf("has: expects art, save, collection or played, not %q", argument)
+		}
+	case "played":
+		compare, err := parsePlayTimeFilter(lowerArgument)
+		if err != nil {
+			return clause, err
+		}
+		clause.usesPlayTime = true
+		clause.match = func(_ models.IndexedRom, playTime int) bool { return compare(playTime) }
+	case "in":
+		clause.match = func(rom models.IndexedRom, _ int) bool {
+			return slices.ContainsFunc(rom.Collections, func(collection string) bool {
+				return strings.EqualFold(collection, argument)
+			})
+		}
+	case "ext":
+		extension := "." + strings.TrimPrefix(lowerArgument, ".")
+		clause.match = func(rom models.IndexedRom, _ int) bool {
+			return !rom.Item.IsDirectory && strings.ToLower(filepath.Ext(rom.Item.Filename)) == extension
+		}
+	case "archived":
+		archived, ok := parseSearchBool(lowerArgument)
+		if !ok {
+			return clause, fmt.Errorf("archived: expects yes or no, not %q", argument)
+		}
+		clause.match = func(rom models.IndexedRom, _ int) bool { return (rom.Archive != "") == archived }
+	}
+
+	return clause, nil
+}
+
+// romHasRegion reports whether one of the bracketed tags in a ROM's filename, such as (USA, Europe), mentions the
+// lowercase region. Display names can have their tags stripped, and a multi-disc folder has no extension to remove.
+func romHasRegion(rom models.IndexedRom, region string) bool {
+	name := rom.Item.Filename
+	if !rom.Item.IsDirectory {
+		name = removeFileExtension(name)
+	}
+
+	return slices.ContainsFunc(filenameTagPattern.FindAllStringSubmatch(name, -1), func(tag []string) bool {
+		return strings.Contains(strings.ToLower(tag[1]), region)
+	})
+}
+
+// parsePlayTimeFilter reads values such as >2h, <=30m, 1h30m or yes. A bare duration means at least that long.
+func parsePlayTimeFilter(argument string) (func(playTime int) bool, error) {
+	if played, ok := parseSearchBool(argument); ok {
+		return func(playTime int) bool { return (playTime > 0) == played }, nil
+	}
+
+	operator := ">="
+	for _, candidate := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(argument, candidate) {
+			operator = candidate
+			argument = strings.TrimPrefix(argument, candidate)
+			break
+		}
+	}
+
+	duration, err := time.ParseDuration(argument)
+	if err != nil || duration < 0 {
+		return nil, fmt.Errorf("played: expects a time like >2h, <30m or 1h30m, not %q", argument)
+	}
+
+	seconds := int(duration.Seconds())
+
+	return func(playTime int) bool {
+		switch operator {
+		case ">":
+			return playTime > seconds
+		case "<":
+			return playTime < seconds
+		case "<=":
+			return playTime <= seconds
+		case "=":
+			return playTime == seconds
+		default:
+			return playTime >= seconds
+		}
+	}, nil
+}
+
+func parseSearchBool(value string) (bool, bool) {
+	switch value {
+	case "yes", "y", "true":
+		return true, true
+	case "no", "n", "false":
+		return false, true
+	}
+	return false, false
+}
+
+func searchFilterExample(name string) string {
+	switch name {
+	case "region":
+		return "region:usa"
+	case "has":
+		return "has:art"
+	case "played":
+		return "played:>2h"
+	case "in":
+		return "in:\"RPG Backlog\""
+	case "ext":
+		return "ext:zip"
+	default:
+		return "archived:yes"
+	}
+}
+
+// UsesPlayTime reports whether matching needs the game tracker, which is slow enough to only load when asked for.
+func (q SearchQuery) UsesPlayTime() bool {
+	return slices.ContainsFunc(q.clauses, func(clause searchClause) bool {
+		return clause.usesPlayTime
+	})
+}
+
+// Score rates how well a name matches the plain words of the query, 1 when there are none and 0 when it does not match.
+func (q SearchQuery) Score(name string) float64 {
+	return FuzzyScore(strings.Join(q.words, " "), name)
+}
+
+// Matches checks a ROM against the plain words and every clause. gamePlayMap comes from GenerateCurrentGameStats and
+// may be nil unless UsesPlayTime is true.
+func (q SearchQuery) Matches(rom models.IndexedRom, gamePlayMap map[string][]models.PlayHistoryAggregate) bool {
+	if q.Score(searchName(rom.Item)) == 0 {
+		return false
+	}
+
+	playTime := 0
+	if q.UsesPlayTime() {
+		aggregate, _ := CollectGameAggregateFromGame(rom.Item, gamePlayMap)
+		playTime = aggregate.PlayTimeTotal
+	}
+
+	for _, clause := range q.clauses {
+		if clause.match(rom, playTime) == clause.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchesName checks a name, such as a collection's, against the plain words of the query. Queries with filters never
+// match a name since there is no ROM to filter on.
+func (q SearchQuery) MatchesName(name string) bool {
+	if q.Score(name) == 0 {
+		return false
+	}
+
+	for _, clause := range q.clauses {
+		if clause.text == "" {
+			return false
+		}
+
+		if matchesAnyKeyword(name, []string{clause.text}) == clause.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// SearchLibraryDirectory lists a directory and keeps what matches the query, best match first. Without the index only
+// plain words match reliably, since art, saves and collections all read as missing.
+func SearchLibraryDirectory(dirPath string, query SearchQuery, gamePlayMap map[string][]models.PlayHistoryAggregate) ([]shared.Item, error) {
+	roms, err := ListIndexedDirectory(dirPath)
+	if err != nil {
+		items, listErr := listDirectoryItems(dirPath)
+		if listErr != nil {
+			return nil, listErr
+		}
+
+		roms = nil
+		for _, item := range items {
+			roms = append(roms, models.IndexedRom{Item: item})
+		}
+	}
+
+	roms = slices.DeleteFunc(roms, func(rom models.IndexedRom) bool {
+		return !query.Matches(rom, gamePlayMap)
+	})
+
+	slices.SortStableFunc(roms, func(a, b models.IndexedRom) int {
+		return cmp.Compare(query.Score(searchName(b.Item)), query.Score(searchName(a.Item)))
+	})
+
+	var items []shared.Item
+	for _, rom := range roms {
+		items = append(items, rom.Item)
+	}
+	return items, nil
+}
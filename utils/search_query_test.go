@@ -0,0 +1,160 @@
+package utils
+
+import (
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"nextui-game-manager/models"
+	"slices"
+	"testing"
+)
+
+func TestTokenizeSearchQuery(t *testing.T) {
+	tokens, err := tokenizeSearchQuery(`mario  in:"RPG Backlog" "super mario" -has:save ""`)
+	if err != nil {
+		t.Fatalf("tokenizeSearchQuery: %v", err)
+	}
+
+	want := []searchToken{
+		{value: "mario"},
+		{value: "in:RPG Backlog"},
+		{value: "super mario", quoted: true},
+		{value: "-has:save"},
+		{value: "", quoted: true},
+	}
+	if !slices.Equal(tokens, want) {
+		t.Errorf("tokens %+v, want %+v", tokens, want)
+	}
+
+	if _, err := tokenizeSearchQuery(`in:"RPG Backlog`); err == nil {
+		t.Error("an unclosed quote was accepted")
+	}
+}
+
+func TestParseSearchQueryRejectsBadFilters(t *testing.T) {
+	for _, query := range []string{
+		"color:red",
+		"has:",
+		"has:manual",
+		"played:soon",
+		"played:-5m",
+		"archived:maybe",
+		`"unclosed`,
+	} {
+		if _, err := ParseSearchQuery(query); err == nil {
+			t.Errorf("ParseSearchQuery(%q) succeeded", query)
+		}
+	}
+}
+
+func TestSearchQueryMatches(t *testing.T) {
+	tetris := models.IndexedRom{
+		Item:        shared.Item{DisplayName: "Tetris", Filename: "Tetris (USA, Europe) (Rev 1).gb"},
+		HasArt:      true,
+		Collections: []string{"Puzzle"},
+	}
+	archived := models.IndexedRom{
+		Item:    shared.Item{DisplayName: "Dr. Mario", Filename: "Dr. Mario (Japan).gb"},
+		Archive: ".Old",
+		HasSave: true,
+	}
+
+	for _, test := range []struct {
+		query           string
+		tetris, drMario bool
+	}{
+		{"tetris", true, false},
+		{"-tetris", false, true},
+		{`"tetris (usa"`, true, false},
+		{"region:europe", true, false},
+		{"region:rev", true, false},
+		{"-region:usa", false, true},
+		{"has:art", true, false},
+		{"has:save", false, true},
+		{"has:collection", true, false},
+		{"in:puzzle", true, false},
+		{"ext:GB", true, true},
+		{"ext:.gba", false, false},
+		{"archived:yes", false, true},
+		{"mario archived:no", false, false},
+	} {
+		query, err := ParseSearchQuery(test.query)
+		if err != nil {
+			t.Errorf("ParseSearchQuery(%q): %v", test.query, err)
+			continue
+		}
+		if query.UsesPlayTime() {
+			t.Errorf("%q asks for play time", test.query)
+		}
+		if got := query.Matches(tetris, nil); got != test.tetris {
+			t.Errorf("%q matches Tetris: %v, want %v", test.query, got, test.tetris)
+		}
+		if got := query.Matches(archived, nil); got != test.drMario {
+			t.Errorf("%q matches Dr. Mario: %v, want %v", test.query, got, test.drMario)
+		}
+	}
+}
+
+func TestSearchQueryMatchesRegionOfMultiDiscFolders(t *testing.T) {
+	query, err := ParseSearchQuery("region:usa")
+	if err != nil {
+		t.Fatalf("ParseSearchQuery: %v", err)
+	}
+
+	folder := models.IndexedRom{Item: shared.Item{DisplayName: "Super Mario Bros. 3", Filename: "Super Mario Bros. 3 (USA)", IsDirectory: true}}
+	if !query.Matches(folder, nil) {
+		t.Errorf("region:usa skipped %q", folder.Item.Filename)
+	}
+}
+
+func TestSearchQueryMatchesName(t *testing.T) {
+	for _, test := range []struct {
+		query string
+		want  bool
+	}{
+		{"puzzle", true},
+		{"-puzzle", false},
+		{"puzzle -rpg", true},
+		{"puzzle has:art", false},
+	} {
+		query, err := ParseSearchQuery(test.query)
+		if err != nil {
+			t.Fatalf("ParseSearchQuery(%q): %v", test.query, err)
+		}
+		if got := query.MatchesName("Puzzle Games"); got != test.want {
+			t.Errorf("%q matches the name: %v, want %v", test.query, got, test.want)
+		}
+	}
+}
+
+func TestParsePlayTimeFilter(t *testing.T) {
+	for _, test := range []struct {
+		argument string
+		playTime int
+		want     bool
+	}{
+		{">2h", 7201, true},
+		{">2h", 7200, false},
+		{"<30m", 1799, true},
+		{"<=30m", 1800, true},
+		{"=90s", 90, true},
+		{"1h30m", 5400, true},
+		{"1h30m", 5399, false},
+		{"yes", 1, true},
+		{"yes", 0, false},
+		{"no", 0, true},
+	} {
+		compare, err := parsePlayTimeFilter(test.argument)
+		if err != nil {
+			t.Errorf("parsePlayTimeFilter(%q): %v", test.argument, err)
+			continue
+		}
+		if got := compare(test.playTime); got != test.want {
+			t.Errorf("played:%s with %ds played = %v, want %v", test.argument, test.playTime, got, test.want)
+		}
+	}
+
+	for _, query := range []string{"played:>2h", "has:played", "-played:no"} {
+		if parsed, err := ParseSearchQuery(query); err != nil || !parsed.UsesPlayTime() {
+			t.Errorf("%q does not ask for play time: %v", query, err)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"github.com/spf13/afero"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+	"nextui-game-manager/models"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const smartCollectionsFile = "smart_collections.yml"
+
+func init() {
+	SubscribeLibraryChanges(followSmartCollectionChanges)
+}
+
+// LoadSmartCollections reads the smart collection definitions kept next to config.yml.
+func LoadSmartCollections() ([]models.SmartCollection, error) {
+	if !DoesFileExists(smartCollectionsFile) {
+		return nil, nil
+	}
+
+	data, err := afero.ReadFile(fileSystem, smartCollectionsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read smart collections: %w", err)
+	}
+
+	var smartCollections []models.SmartCollection
+	if err := yaml.Unmarshal(data, &smartCollections); err != nil {
+		return nil, fmt.Errorf("failed to parse smart collections: %w", err)
+	}
+
+	return smartCollections, nil
+}
+
+func saveSmartCollections(smartCollections []models.SmartCollection) error {
+	data, err := yaml.Marshal(smartCollections)
+	if err != nil {
+		return fmt.Errorf("failed to encode smart collections: %w", err)
+	}
+
+	return afero.WriteFile(fileSystem, smartCollectionsFile, data, defaultFilePerm)
+}
+
+// IsSmartCollection reports whether a collection is rewritten from rules, in which case games added or removed by
+// hand are lost on the next refresh.
+func IsSmartCollection(collection models.Collection) bool {
+	smartCollections, err := LoadSmartCollections()
+	if err != nil {
+		return false
+	}
+
+	return slices.ContainsFunc(smartCollections, func(smartCollection models.SmartCollection) bool {
+		return smartCollectionFile(smartCollection) == collection.CollectionFile
+	})
+}
+
+// RefreshSmartCollections picks the games for every smart collection and writes each one out as a NextUI collection.
+// Collections whose games have not changed are left alone. A definition with a mistake in it is skipped and reported
+// in the returned error without stopping the others.
+func RefreshSmartCollections() ([]models.Collection, error) {
+	logger := common.GetLoggerInstance()
+
+	smartCollections, err := LoadSmartCollections()
+	if err != nil || len(smartCollections) == 0 {
+		return nil, err
+	}
+
+	roms, err := ListLibraryRoms()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list library: %w", err)
+	}
+
+	var gamePlayMap map[string][]models.PlayHistoryAggregate
+	if slices.ContainsFunc(smartCollections, smartCollectionUsesPlayTime) {
+		gamePlayMap, _, _ = GenerateCurrentGameStats()
+	}
+
+	var collections []models.Collection
+	var errs []error
+
+	for _, smartCollection := range smartCollections {
+		collection, err := materializeSmartCollection(smartCollection, roms, gamePlayMap)
+		if err != nil {
+			logger.Error("Unable to refresh smart collection", zap.String("name", smartCollection.Name), zap.Error(err))
+			errs = append(errs, fmt.Errorf("%s: %w", smartCollection.Name, err))
+			continue
+		}
+
+		collections = append(collections, collection)
+	}
+
+	return collections, errors.Join(errs...)
+}
+
+func materializeSmartCollection(smartCollection models.SmartCollection, roms []models.IndexedRom, gamePlayMap map[string][]models.PlayHistoryAggregate) (models.Collection, error) {
+	if strings.TrimSpace(smartCollection.Name) == "" || strings.ContainsAny(smartCollection.Name, `/\`) {
+		return models.Collection{}, fmt.Errorf("invalid name %q", smartCollection.Name)
+	}
+
+	if smartCollection.Sort != "" {
+		if _, ok := models.GameListSortMap[smartCollection.Sort]; !ok {
+			return models.Collection{}, fmt.Errorf("unknown sort %q, try %s", smartCollection.Sort,
+				strings.Join(models.GameListSortKeys, ", "))
+		}
+	}
+
+	match, err := compileSmartCollectionRules(smartCollection.Rules)
+	if err != nil {
+		return models.Collection{}, err
+	}
+
+	var picked []models.IndexedRom
+	for _, rom := range roms {
+		aggregate, _ := CollectGameAggregateFromGame(rom.Item, gamePlayMap)
+		if match(rom, aggregate) {
+			picked = append(picked, rom)
+		}
+	}
+
+	sortIndexedRoms(picked, smartCollection.Sort, gamePlayMap)
+
+	if smartCollection.Limit > 0 && len(picked) > smartCollection.Limit {
+		picked = picked[:smartCollection.Limit]
+	}
+
+	collection := models.Collection{
+		DisplayName:    smartCollection.Name,
+		CollectionFile: smartCollectionFile(smartCollection),
+	}
+
+	for _, rom := range picked {
+		collection.Games = append(collection.Games, rom.Item)
+	}
+
+	if DoesFileExists(collection.CollectionFile) {
+		if existing, err := ReadCollection(collection); err == nil && sameCollectionGames(existing.Games, collection.Games) {
+			return collection, nil
+		}
+	}
+
+	return collection, SaveCollection(collection)
+}
+
+// compileSmartCollectionRules checks the rules once and returns a matcher for them.
+func compileSmartCollectionRules(rules models.SmartCollectionRules) (func(rom models.IndexedRom, aggregate models.PlayHistoryAggregate) bool, error) {
+	pattern := strings.ToLower(rules.Pattern)
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid pattern %q", rules.Pattern)
+	}
+
+	minPlayTime, err := parseSmartCollectionPlayTime("min_play_time", rules.MinPlayTime)
+	if err != nil {
+		return nil, err
+	}
+
+	maxPlayTime, err := parseSmartCollectionPlayTime("max_play_time", rules.MaxPlayTime)
+	if err != nil {
+		return nil, err
+	}
+
+	region := strings.ToLower(rules.Region)
+	playedSince := time.Now().AddDate(0, 0, -rules.PlayedWithinDays)
+
+	return func(rom models.IndexedRom, aggregate models.PlayHistoryAggregate) bool {
+		if len(rules.Platforms) > 0 && !slices.ContainsFunc(rules.Platforms, func(platform string) bool {
+			return matchesPlatform(rom.Platform, platform)
+		}) {
+			return false
+		}
+
+		if pattern != "" {
+			if matched, _ := filepath.Match(pattern, strings.ToLower(rom.Item.Filename)); !matched {
+				return false
+			}
+		}
+
+		if region != "" && !romHasRegion(rom, region) {
+			return false
+		}
+
+		if rules.MinPlayTime != "" && aggregate.PlayTimeTotal < minPlayTime {
+			return false
+		}
+
+		if rules.MaxPlayTime != "" && aggregate.PlayTimeTotal > maxPlayTime {
+			return false
+		}
+
+		if rules.PlayedWithinDays > 0 && (aggregate.PlayCountTotal == 0 || aggregate.LastPlayedTime.Before(playedSince)) {
+			return false
+		}
+
+		if rules.NeverPlayed && aggregate.PlayCountTotal > 0 {
+			return false
+		}
+
+		return !rules.MissingArt || !rom.HasArt
+	}, nil
+}
+
+func parseSmartCollectionPlayTime(name string, value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("%s expects a time like 2h or 30m, not %q", name, value)
+	}
+
+	return int(duration.Seconds()), nil
+}
+
+// matchesPlatform accepts a platform's tag with or without brackets, its display name or its folder name.
+func matchesPlatform(platform shared.RomDirectory, name string) bool {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	return strings.Trim(name, "()") == strings.Trim(strings.ToLower(platform.Tag), "()") ||
+		name == strings.ToLower(platform.DisplayName) ||
+		name == strings.ToLower(filepath.Base(platform.Path))
+}
+
+func smartCollectionUsesPlayTime(smartCollection models.SmartCollection) bool {
+	rules := smartCollection.Rules
+	return rules.MinPlayTime != "" || rules.MaxPlayTime != "" || rules.PlayedWithinDays > 0 || rules.NeverPlayed ||
+		GameListSortUsesPlayTime(smartCollection.Sort)
+}
+
+func smartCollectionFile(smartCollection models.SmartCollection) string {
+	return filepath.Join(GetCollectionDirectory(), smartCollection.Name+".txt")
+}
+
+func sameCollectionGames(existing []shared.Item, games []shared.Item) bool {
+	return slices.EqualFunc(existing, games, func(a, b shared.Item) bool {
+		return a.Path == normalizeCollectionGamePath(b)
+	})
+}
+
+// followSmartCollectionChanges keeps the definitions pointing at their collection when it is renamed in the UI and
+// drops them when it is deleted, so a deleted smart collection does not come back on the next refresh.
+func followSmartCollectionChanges(change models.LibraryChange) {
+	if change.Kind != models.LibraryChangeKinds.CollectionRenamed && change.Kind != models.LibraryChangeKinds.CollectionDeleted {
+		return
+	}
+
+	logger := common.GetLoggerInstance()
+
+	smartCollections, err := LoadSmartCollections()
+	if err != nil || len(smartCollections) == 0 {
+		return
+	}
+
+	previousFile := change.PreviousPath
+	if change.Kind == models.LibraryChangeKinds.CollectionDeleted {
+		previousFile = change.Path
+	}
+
+	index := slices.IndexFunc(smartCollections, func(smartCollection models.SmartCollection) bool {
+		return smartCollectionFile(smartCollection) == previousFile
+	})
+	if index == -1 {
+		return
+	}
+
+	if change.Kind == models.LibraryChangeKinds.CollectionDeleted {
+		smartCollections = slices.Delete(smartCollections, index, index+1)
+	} else {
+		smartCollections[index].Name = strings.TrimSuffix(filepath.Base(change.Path), filepath.Ext(change.Path))
+	}
+
+	if err := saveSmartCollections(smartCollections); err != nil {
+		logger.Error("Unable to update smart collections", zap.Error(err))
+	}
+}
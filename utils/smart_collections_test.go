@@ -0,0 +1,93 @@
+package utils
+
+import (
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"nextui-game-manager/models"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCompileSmartCollectionRules(t *testing.T) {
+	gbaDirectory := shared.RomDirectory{DisplayName: "Game Boy Advance", Tag: "(GBA)", Path: filepath.Join(testRoms, "Game Boy Advance (GBA)")}
+
+	tetris := models.IndexedRom{
+		Item:     shared.Item{DisplayName: "Tetris (USA)", Filename: "Tetris (USA).gb"},
+		Platform: testRomDirectory,
+		HasArt:   true,
+	}
+	metroid := models.IndexedRom{
+		Item:     shared.Item{DisplayName: "Metroid Fusion (Europe)", Filename: "Metroid Fusion (Europe).gba"},
+		Platform: gbaDirectory,
+	}
+
+	tetrisPlayed := models.PlayHistoryAggregate{PlayTimeTotal: 3 * 60 * 60, PlayCountTotal: 5, LastPlayedTime: time.Now().AddDate(0, 0, -2)}
+	metroidPlayed := models.PlayHistoryAggregate{}
+
+	for _, test := range []struct {
+		name            string
+		rules           models.SmartCollectionRules
+		tetris, metroid bool
+	}{
+		{"no rules", models.SmartCollectionRules{}, true, true},
+		{"platform tag", models.SmartCollectionRules{Platforms: []string{"gb"}}, true, false},
+		{"platform tag in brackets", models.SmartCollectionRules{Platforms: []string{"(GBA)"}}, false, true},
+		{"platform name", models.SmartCollectionRules{Platforms: []string{"Game Boy Advance"}}, false, true},
+		{"platform folder", models.SmartCollectionRules{Platforms: []string{"Game Boy (GB)", "NES"}}, true, false},
+		{"pattern", models.SmartCollectionRules{Pattern: "metroid*"}, false, true},
+		{"region", models.SmartCollectionRules{Region: "usa"}, true, false},
+		{"min play time", models.SmartCollectionRules{MinPlayTime: "2h"}, true, false},
+		{"max play time", models.SmartCollectionRules{MaxPlayTime: "1h"}, false, true},
+		{"played within days", models.SmartCollectionRules{PlayedWithinDays: 7}, true, false},
+		{"not played recently enough", models.SmartCollectionRules{PlayedWithinDays: 1}, false, false},
+		{"never played", models.SmartCollectionRules{NeverPlayed: true}, false, true},
+		{"missing art", models.SmartCollectionRules{MissingArt: true}, false, true},
+		{"every rule must hold", models.SmartCollectionRules{Platforms: []string{"gb"}, MissingArt: true}, false, false},
+	} {
+		match, err := compileSmartCollectionRules(test.rules)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got := match(tetris, tetrisPlayed); got != test.tetris {
+			t.Errorf("%s: picks Tetris %v, want %v", test.name, got, test.tetris)
+		}
+		if got := match(metroid, metroidPlayed); got != test.metroid {
+			t.Errorf("%s: picks Metroid Fusion %v, want %v", test.name, got, test.metroid)
+		}
+	}
+}
+
+func TestCompileSmartCollectionRulesReadsRegionsFromFilenames(t *testing.T) {
+	match, err := compileSmartCollectionRules(models.SmartCollectionRules{Region: "USA"})
+	if err != nil {
+		t.Fatalf("compileSmartCollectionRules: %v", err)
+	}
+
+	for _, test := range []struct {
+		rom  shared.Item
+		want bool
+	}{
+		{shared.Item{DisplayName: "Tetris", Filename: "Tetris (USA).gb"}, true},
+		{shared.Item{DisplayName: "Final Fantasy VII", Filename: "Final Fantasy VII (USA)", IsDirectory: true}, true},
+		{shared.Item{DisplayName: "Super Mario Bros. 3", Filename: "Super Mario Bros. 3 (USA)", IsDirectory: true}, true},
+		{shared.Item{DisplayName: "Final Fantasy VII (USA)", Filename: "Final Fantasy VII (Europe)", IsDirectory: true}, false},
+	} {
+		rom := models.IndexedRom{Item: test.rom, Platform: testRomDirectory}
+		if got := match(rom, models.PlayHistoryAggregate{}); got != test.want {
+			t.Errorf("picks %q: %v, want %v", test.rom.Filename, got, test.want)
+		}
+	}
+}
+
+func TestCompileSmartCollectionRulesRejectsMistakes(t *testing.T) {
+	for _, rules := range []models.SmartCollectionRules{
+		{Pattern: "[tetris"},
+		{MinPlayTime: "a while"},
+		{MaxPlayTime: "-1h"},
+	} {
+		if _, err := compileSmartCollectionRules(rules); err == nil {
+			t.Errorf("rules %+v were accepted", rules)
+		}
+	}
+}
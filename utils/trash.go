@@ -0,0 +1,265 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
+	"github.com/spf13/afero"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+	"nextui-game-manager/models"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const trashEntryFile = "entry.yml"
+
+// MoveToTrash moves the given paths into a single trash entry so they can be restored together. Paths that do not
+// exist are skipped; an error is returned if none of them could be trashed. The entry is written before anything is
+// moved, so files never sit in the trash without one.
+func MoveToTrash(kind string, description string, paths ...string) (models.TrashEntry, error) {
+	logger := common.GetLoggerInstance()
+
+	entry := models.TrashEntry{
+		ID:          strconv.FormatInt(time.Now().UnixNano(), 10),
+		Kind:        kind,
+		Description: description,
+		DeletedAt:   time.Now(),
+	}
+	entryDirectory := filepath.Join(GetTrashDirectory(), entry.ID)
+
+	for i, path := range paths {
+		if !DoesFileExists(path) {
+			continue
+		}
+
+		entry.Files = append(entry.Files, models.TrashedFile{
+			OriginalPath: path,
+			StoredPath:   filepath.Join(strconv.Itoa(i), filepath.Base(path)),
+		})
+	}
+
+	if len(entry.Files) == 0 {
+		return entry, fmt.Errorf("nothing to trash for %s", description)
+	}
+
+	if err := saveTrashEntry(entry); err != nil {
+		_ = fileSystem.RemoveAll(entryDirectory)
+		return entry, err
+	}
+
+	var trashed []models.TrashedFile
+	for _, file := range entry.Files {
+		if err := MoveFile(file.OriginalPath, filepath.Join(entryDirectory, file.StoredPath)); err != nil {
+			logger.Error("Failed to move file to trash", zap.String("path", file.OriginalPath), zap.Error(err))
+			continue
+		}
+		trashed = append(trashed, file)
+	}
+
+	if len(trashed) == len(entry.Files) {
+		return entry, nil
+	}
+
+	entry.Files = trashed
+	if len(trashed) > 0 {
+		err := saveTrashEntry(entry)
+		if err == nil {
+			return entry, nil
+		}
+		logger.Error("Failed to update trash entry, putting its files back", zap.String("entry", entry.ID), zap.Error(err))
+
+		// The entry on disk still lists every file, so one that cannot be put back can still be restored from the trash
+		for _, file := range trashed {
+			if err := MoveFile(filepath.Join(entryDirectory, file.StoredPath), file.OriginalPath); err != nil {
+				logger.Error("Failed to put trashed file back", zap.String("path", file.OriginalPath), zap.Error(err))
+				return entry, fmt.Errorf("unable to trash all of %s: %w", description, err)
+			}
+		}
+	}
+
+	_ = fileSystem.RemoveAll(entryDirectory)
+	return entry, fmt.Errorf("unable to trash %s", description)
+}
+
+func saveTrashEntry(entry models.TrashEntry) error {
+	data, err := yaml.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode trash entry: %w", err)
+	}
+
+	entryDirectory := filepath.Join(GetTrashDirectory(), entry.ID)
+	if err := EnsureDirectoryExists(entryDirectory); err != nil {
+		return fmt.Errorf("failed to create trash entry: %w", err)
+	}
+
+	if err := afero.WriteFile(fileSystem, filepath.Join(entryDirectory, trashEntryFile), data, defaultFilePerm); err != nil {
+		return fmt.Errorf("failed to write trash entry: %w", err)
+	}
+	return nil
+}
+
+// ListTrash returns everything in the trash, most recently deleted first.
+func ListTrash() ([]models.TrashEntry, error) {
+	logger := common.GetLoggerInstance()
+
+	entries, err := afero.ReadDir(fileSystem, GetTrashDirectory())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read trash: %w", err)
+	}
+
+	var trash []models.TrashEntry
+	for _, dir := range entries {
+		if !dir.IsDir() {
+			continue
+		}
+
+		data, err := afero.ReadFile(fileSystem, filepath.Join(GetTrashDirectory(), dir.Name(), trashEntryFile))
+		if err != nil {
+			logger.Error("Skipping unreadable trash entry", zap.String("entry", dir.Name()), zap.Error(err))
+			continue
+		}
+
+		var entry models.TrashEntry
+		if err := yaml.Unmarshal(data, &entry); err != nil {
+			logger.Error("Skipping unreadable trash entry", zap.String("entry", dir.Name()), zap.Error(err))
+			continue
+		}
+
+		trash = append(trash, entry)
+	}
+
+	slices.SortFunc(trash, func(a, b models.TrashEntry) int {
+		return b.DeletedAt.Compare(a.DeletedAt)
+	})
+
+	return trash, nil
+}
+
+func FindTrashEntry(id string) (models.TrashEntry, error) {
+	trash, err := ListTrash()
+	if err != nil {
+		return models.TrashEntry{}, err
+	}
+
+	for _, entry := range trash {
+		if entry.ID == id {
+			return entry, nil
+		}
+	}
+
+	return models.TrashEntry{}, fmt.Errorf("%s is no longer in the trash", id)
+}
+
+// RestoreFromTrash puts every file of an entry back where it was deleted from. Nothing is overwritten; a file whose
+// original location is taken stays in the trash and is reported, and the entry is kept listing only those files.
+func RestoreFromTrash(entry models.TrashEntry) error {
+	entryDirectory := filepath.Join(GetTrashDirectory(), entry.ID)
+
+	var errs []error
+	var remaining []models.TrashedFile
+	for _, file := range entry.Files {
+		if DoesFileExists(file.OriginalPath) {
+			errs = append(errs, fmt.Errorf("%s already exists", file.OriginalPath))
+			remaining = append(remaining, file)
+			continue
+		}
+
+		if err := EnsureDirectoryExists(filepath.Dir(file.OriginalPath)); err != nil {
+			errs = append(errs, err)
+			remaining = append(remaining, file)
+			continue
+		}
+
+		if err := MoveFile(filepath.Join(entryDirectory, file.StoredPath), file.OriginalPath); err != nil {
+			errs = append(errs, err)
+			remaining = append(remaining, file)
+		}
+	}
+
+	// The first file is the ROM or collection itself, the rest are its art
+	if len(entry.Files) > 0 && !slices.Contains(remaining, entry.Files[0]) {
+		switch entry.Kind {
+		case models.TrashKindRom:
+			publishLibraryChange(models.LibraryChange{
+				Kind: models.LibraryChangeKinds.RomRestored,
+				Path: entry.Files[0].OriginalPath,
+			})
+		case models.TrashKindCollection:
+			publishLibraryChange(models.LibraryChange{
+				Kind: models.LibraryChangeKinds.CollectionSaved,
+				Path: entry.Files[0].OriginalPath,
+			})
+		}
+	}
+
+	if len(errs) > 0 {
+		if len(remaining) < len(entry.Files) {
+			entry.Files = remaining
+			if err := saveTrashEntry(entry); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	return fileSystem.RemoveAll(entryDirectory)
+}
+
+func DeleteFromTrash(entry models.TrashEntry) error {
+	return fileSystem.RemoveAll(filepath.Join(GetTrashDirectory(), entry.ID))
+}
+
+func EmptyTrash() error {
+	return fileSystem.RemoveAll(GetTrashDirectory())
+}
+
+// PurgeExpiredTrash permanently deletes entries older than the retention period. A retention of zero keeps everything.
+func PurgeExpiredTrash(retentionDays int) (int, error) {
+	if retentionDays <= 0 {
+		return 0, nil
+	}
+
+	trash, err := ListTrash()
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -retentionDays)
+
+	purged := 0
+	var errs []error
+	for _, entry := range trash {
+		if entry.DeletedAt.After(cutoff) {
+			continue
+		}
+
+		if err := DeleteFromTrash(entry); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		purged++
+	}
+
+	return purged, errors.Join(errs...)
+}
+
+// TrashSize returns the number of bytes held by an entry.
+func TrashSize(entry models.TrashEntry) int64 {
+	var size int64
+
+	_ = afero.Walk(fileSystem, filepath.Join(GetTrashDirectory(), entry.ID), func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && info.Name() != trashEntryFile {
+			size += info.Size()
+		}
+		return nil
+	})
+
+	return size
+}
@@ -0,0 +1,240 @@
+package utils
+
+import (
+	"errors"
+	"github.com/spf13/afero"
+	"nextui-game-manager/models"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMoveToTrashAndRestore(t *testing.T) {
+	useFakeSDCard(t)
+
+	romPath := filepath.Join(testPlatform, "Tetris.gb")
+	artPath := filepath.Join(testPlatform, ".media", "Tetris.png")
+	writeTestFile(t, romPath, "rom")
+	writeTestFile(t, artPath, "art")
+
+	entry, err := MoveToTrash(models.TrashKindRom, "Tetris", romPath, artPath, filepath.Join(testPlatform, "Missing.gb"))
+	if err != nil {
+		t.Fatalf("MoveToTrash: %v", err)
+	}
+	if len(entry.Files) != 2 {
+		t.Errorf("trashed %d files, want 2", len(entry.Files))
+	}
+	assertMissing(t, romPath, artPath)
+
+	trash, err := ListTrash()
+	if err != nil {
+		t.Fatalf("ListTrash: %v", err)
+	}
+	if len(trash) != 1 || trash[0].ID != entry.ID || trash[0].Description != "Tetris" {
+		t.Fatalf("ListTrash returned %+v", trash)
+	}
+
+	if err := RestoreFromTrash(trash[0]); err != nil {
+		t.Fatalf("RestoreFromTrash: %v", err)
+	}
+
+	assertExists(t, romPath, artPath)
+	if trash, _ := ListTrash(); len(trash) != 0 {
+		t.Errorf("trash still holds %+v", trash)
+	}
+}
+
+func TestRestoreFromTrashKeepsNewerFiles(t *testing.T) {
+	useFakeSDCard(t)
+
+	romPath := filepath.Join(testPlatform, "Tetris.gb")
+	artPath := filepath.Join(testPlatform, ".media", "Tetris.png")
+	writeTestFile(t, romPath, "rom")
+	writeTestFile(t, artPath, "art")
+
+	entry, err := MoveToTrash(models.TrashKindRom, "Tetris", romPath, artPath)
+	if err != nil {
+		t.Fatalf("MoveToTrash: %v", err)
+	}
+
+	writeTestFile(t, artPath, "new art")
+	if err := RestoreFromTrash(entry); err == nil {
+		t.Error("restoring over newer art succeeded")
+	}
+
+	assertExists(t, romPath)
+	if got := readTestFile(t, artPath); got != "new art" {
+		t.Errorf("newer art was overwritten with %q", got)
+	}
+	if trash, _ := ListTrash(); len(trash) != 1 {
+		t.Errorf("the art that could not be restored left the trash: %+v", trash)
+	}
+}
+
+func TestRestoreFromTrashKeepsOnlyWhatWasLeftBehind(t *testing.T) {
+	useFakeSDCard(t)
+
+	romPath := filepath.Join(testPlatform, "Tetris.gb")
+	artPath := filepath.Join(testPlatform, ".media", "Tetris.png")
+	writeTestFile(t, romPath, "rom")
+	writeTestFile(t, artPath, "art")
+
+	entry, err := MoveToTrash(models.TrashKindRom, "Tetris", romPath, artPath)
+	if err != nil {
+		t.Fatalf("MoveToTrash: %v", err)
+	}
+
+	changes := recordLibraryChanges(t)
+
+	writeTestFile(t, artPath, "new art")
+	if err := RestoreFromTrash(entry); err == nil {
+		t.Fatal("restoring over newer art succeeded")
+	}
+
+	if len(*changes) != 1 || (*changes)[0].Kind != models.LibraryChangeKinds.RomRestored || (*changes)[0].Path != romPath {
+		t.Errorf("published %+v, want the restored ROM", *changes)
+	}
+
+	trash, _ := ListTrash()
+	if len(trash) != 1 || len(trash[0].Files) != 1 || trash[0].Files[0].OriginalPath != artPath {
+		t.Fatalf("trash holds %+v, want only the art", trash)
+	}
+
+	// Once the newer art is out of the way the rest of the entry restores cleanly
+	if err := fileSystem.Remove(artPath); err != nil {
+		t.Fatalf("removing newer art: %v", err)
+	}
+	if err := RestoreFromTrash(trash[0]); err != nil {
+		t.Fatalf("RestoreFromTrash: %v", err)
+	}
+	if got := readTestFile(t, artPath); got != "art" {
+		t.Errorf("restored art holds %q", got)
+	}
+	if trash, _ := ListTrash(); len(trash) != 0 {
+		t.Errorf("trash still holds %+v", trash)
+	}
+}
+
+func TestMoveToTrashWithNothingToTrash(t *testing.T) {
+	useFakeSDCard(t)
+
+	if _, err := MoveToTrash(models.TrashKindRom, "Tetris", filepath.Join(testPlatform, "Tetris.gb")); err == nil {
+		t.Error("trashing a missing file succeeded")
+	}
+	if trash, _ := ListTrash(); len(trash) != 0 {
+		t.Errorf("trash holds %+v", trash)
+	}
+}
+
+func TestPurgeExpiredTrash(t *testing.T) {
+	useFakeSDCard(t)
+
+	writeTestFile(t, filepath.Join(testPlatform, "Old.gb"), "rom")
+	writeTestFile(t, filepath.Join(testPlatform, "New.gb"), "rom")
+
+	old, err := MoveToTrash(models.TrashKindRom, "Old", filepath.Join(testPlatform, "Old.gb"))
+	if err != nil {
+		t.Fatalf("MoveToTrash: %v", err)
+	}
+	if _, err := MoveToTrash(models.TrashKindRom, "New", filepath.Join(testPlatform, "New.gb")); err != nil {
+		t.Fatalf("MoveToTrash: %v", err)
+	}
+
+	old.DeletedAt = time.Now().AddDate(0, 0, -31)
+	if err := saveTrashEntry(old); err != nil {
+		t.Fatalf("backdating trash entry: %v", err)
+	}
+
+	purged, err := PurgeExpiredTrash(30)
+	if err != nil {
+		t.Fatalf("PurgeExpiredTrash: %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("purged %d entries, want 1", purged)
+	}
+
+	trash, _ := ListTrash()
+	if len(trash) != 1 || trash[0].Description != "New" {
+		t.Errorf("trash holds %+v", trash)
+	}
+}
+
+// recordLibraryChanges collects every library change published until the test ends.
+func recordLibraryChanges(t *testing.T) *[]models.LibraryChange {
+	librarySubscribersMutex.Lock()
+	previous := librarySubscribers
+	librarySubscribersMutex.Unlock()
+	t.Cleanup(func() {
+		librarySubscribersMutex.Lock()
+		librarySubscribers = previous
+		librarySubscribersMutex.Unlock()
+	})
+
+	var changes []models.LibraryChange
+	SubscribeLibraryChanges(func(change models.LibraryChange) { changes = append(changes, change) })
+	return &changes
+}
+
+// failingTrashFs fails to move one file and, when told to, to write trash entries.
+type failingTrashFs struct {
+	afero.Fs
+	unmovable   string
+	failEntries bool
+}
+
+func (fs failingTrashFs) Rename(oldname, newname string) error {
+	if oldname == fs.unmovable {
+		return errors.New("permission denied")
+	}
+	return fs.Fs.Rename(oldname, newname)
+}
+
+func (fs failingTrashFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if fs.failEntries && filepath.Base(name) == trashEntryFile {
+		return nil, errors.New("no space left on device")
+	}
+	return fs.Fs.OpenFile(name, flag, perm)
+}
+
+func TestMoveToTrashOnlyListsWhatWasMoved(t *testing.T) {
+	useFakeSDCard(t)
+
+	romPath := filepath.Join(testPlatform, "Tetris.gb")
+	artPath := filepath.Join(testPlatform, ".media", "Tetris.png")
+	writeTestFile(t, romPath, "rom")
+	writeTestFile(t, artPath, "art")
+
+	SetFileSystem(failingTrashFs{Fs: fileSystem, unmovable: artPath})
+
+	entry, err := MoveToTrash(models.TrashKindRom, "Tetris", romPath, artPath)
+	if err != nil {
+		t.Fatalf("MoveToTrash: %v", err)
+	}
+
+	assertMissing(t, romPath)
+	assertExists(t, artPath)
+
+	trash, _ := ListTrash()
+	if len(trash) != 1 || len(trash[0].Files) != 1 || trash[0].Files[0].OriginalPath != romPath || trash[0].ID != entry.ID {
+		t.Errorf("trash holds %+v", trash)
+	}
+}
+
+func TestMoveToTrashLeavesFilesWhenTheEntryCannotBeWritten(t *testing.T) {
+	useFakeSDCard(t)
+
+	romPath := filepath.Join(testPlatform, "Tetris.gb")
+	writeTestFile(t, romPath, "rom")
+
+	SetFileSystem(failingTrashFs{Fs: fileSystem, failEntries: true})
+
+	if _, err := MoveToTrash(models.TrashKindRom, "Tetris", romPath); err == nil {
+		t.Fatal("trashing without an entry succeeded")
+	}
+
+	assertExists(t, romPath)
+	if entries, _ := afero.ReadDir(fileSystem, testTrash); len(entries) != 0 {
+		t.Errorf("trash was left with %d folders no entry lists", len(entries))
+	}
+}
@@ -0,0 +1,273 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"github.com/UncleJunVIP/nextui-pak-shared-functions/common"
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"github.com/spf13/afero"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+	"nextui-game-manager/models"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	undoHistoryFile = "undo_history.yml"
+	maxUndoEntries  = 30
+)
+
+func LoadUndoHistory() ([]models.UndoEntry, error) {
+	if !DoesFileExists(GetUndoHistoryPath()) {
+		return nil, nil
+	}
+
+	data, err := afero.ReadFile(fileSystem, GetUndoHistoryPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read undo history: %w", err)
+	}
+
+	var history []models.UndoEntry
+	if err := yaml.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("failed to parse undo history: %w", err)
+	}
+
+	return history, nil
+}
+
+func saveUndoHistory(history []models.UndoEntry) error {
+	data, err := yaml.Marshal(history)
+	if err != nil {
+		return fmt.Errorf("failed to encode undo history: %w", err)
+	}
+
+	return afero.WriteFile(fileSystem, GetUndoHistoryPath(), data, defaultFilePerm)
+}
+
+// RecordUndo adds an action to the front of the undo history, dropping the oldest entries past the limit.
+func RecordUndo(description string, steps ...models.UndoStep) {
+	logger := common.GetLoggerInstance()
+
+	if len(steps) == 0 {
+		return
+	}
+
+	history, err := LoadUndoHistory()
+	if err != nil {
+		logger.Error("Unable to load undo history, starting a new one", zap.Error(err))
+	}
+
+	history = append([]models.UndoEntry{{
+		ID:          time.Now().UnixNano(),
+		Description: description,
+		CreatedAt:   time.Now(),
+		Steps:       steps,
+	}}, history...)
+
+	if len(history) > maxUndoEntries {
+		history = history[:maxUndoEntries]
+	}
+
+	if err := saveUndoHistory(history); err != nil {
+		logger.Error("Unable to save undo history", zap.Error(err))
+	}
+}
+
+// UndoAction replays the inverse steps of an entry and removes it from the history.
+func UndoAction(entry models.UndoEntry) error {
+	logger := common.GetLoggerInstance()
+
+	var errs []error
+	for i := len(entry.Steps) - 1; i >= 0; i-- {
+		if err := undoStep(entry.Steps[i]); err != nil {
+			logger.Error("Failed to undo step", zap.String("kind", entry.Steps[i].Kind), zap.Error(err))
+			errs = append(errs, err)
+		}
+	}
+
+	history, err := LoadUndoHistory()
+	if err != nil {
+		return err
+	}
+
+	history = slices.DeleteFunc(history, func(e models.UndoEntry) bool {
+		return e.ID == entry.ID
+	})
+
+	if err := saveUndoHistory(history); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+func undoStep(step models.UndoStep) error {
+	switch step.Kind {
+	case models.UndoRenameRom:
+		game := shared.Item{
+			DisplayName: removeFileExtension(step.Filename),
+			Filename:    step.Filename,
+			Path:        filepath.Join(step.RomDirectory.Path, step.Filename),
+		}
+		_, err := RenameRom(game, step.PreviousFilename, step.RomDirectory)
+		return err
+	case models.UndoArchiveRom:
+		archiveRoot := GetArchiveRoot(step.Archive)
+		subdirectory := strings.ReplaceAll(step.RomDirectory.Path, GetRomDirectory(), "")
+		archivedDirectory := shared.RomDirectory{
+			DisplayName: step.RomDirectory.DisplayName,
+			Tag:         step.RomDirectory.Tag,
+			Path:        filepath.Join(archiveRoot, subdirectory),
+		}
+		game := shared.Item{
+			DisplayName: removeFileExtension(step.Filename),
+			Filename:    step.Filename,
+			Path:        filepath.Join(archivedDirectory.Path, step.Filename),
+		}
+		if compressed := CompressedArchiveName(game.Path); DoesFileExists(compressed) {
+			game.Filename = CompressedArchiveName(game.Filename)
+			game.Path = compressed
+		}
+		return RestoreRom(game, archivedDirectory, shared.RomDirectory{DisplayName: step.Archive, Path: archiveRoot})
+	case models.UndoRestoreCollection:
+		var games shared.Items
+		for _, entry := range step.CollectionEntries {
+			games = append(games, shared.Item{
+				DisplayName: removeFileExtension(filepath.Base(entry)),
+				Path:        entry,
+			})
+		}
+		return SaveCollection(models.Collection{
+			DisplayName:    removeFileExtension(filepath.Base(step.CollectionFile)),
+			CollectionFile: step.CollectionFile,
+			Games:          games,
+		})
+	case models.UndoRestoreRecents:
+		return writeReferenceLines(GetRecentlyPlayedFile(), step.RecentsLines)
+	case models.UndoRestoreTrash:
+		entry, err := FindTrashEntry(step.TrashID)
+		if err != nil {
+			return err
+		}
+		return RestoreFromTrash(entry)
+	case models.UndoRenameCollection, models.UndoRenameArchive:
+		if DoesFileExists(step.PreviousPath) {
+			return fmt.Errorf("%s already exists", step.PreviousPath)
+		}
+
+		if step.Kind == models.UndoRenameArchive {
+			return RenameArchive(step.Path, step.PreviousPath)
+		}
+
+		if err := MoveFile(step.Path, step.PreviousPath); err != nil {
+			return err
+		}
+
+		publishLibraryChange(models.LibraryChange{
+			Kind:         models.LibraryChangeKinds.CollectionRenamed,
+			Path:         step.PreviousPath,
+			PreviousPath: step.Path,
+		})
+		return nil
+	}
+
+	return fmt.Errorf("unknown undo step %s", step.Kind)
+}
+
+// DeleteArtWithUndo moves the art for each game to the trash as one entry so the deletion can be undone.
+func DeleteArtWithUndo(games []shared.Item, romDirectory shared.RomDirectory) {
+	logger := common.GetLoggerInstance()
+
+	var artPaths []string
+	for _, game := range games {
+		if artPath, err := FindExistingArt(game.Filename, RomDirectoryForItem(game, romDirectory)); err == nil && artPath != "" {
+			artPaths = append(artPaths, artPath)
+		}
+	}
+
+	description := fmt.Sprintf("Delete art for %s", games[0].DisplayName)
+	if len(games) > 1 {
+		description = fmt.Sprintf("Delete art for %d games", len(games))
+	}
+
+	entry, err := MoveToTrash(models.TrashKindArt, description, artPaths...)
+	if err != nil {
+		logger.Error("Failed to delete art", zap.Error(err))
+		return
+	}
+
+	RecordUndo(description, TrashUndoStep(entry))
+}
+
+func TrashUndoStep(entry models.TrashEntry) models.UndoStep {
+	return models.UndoStep{
+		Kind:    models.UndoRestoreTrash,
+		TrashID: entry.ID,
+	}
+}
+
+func RenameRomUndoStep(romDirectory shared.RomDirectory, oldFilename string, newFilename string) models.UndoStep {
+	return models.UndoStep{
+		Kind:             models.UndoRenameRom,
+		RomDirectory:     romDirectory,
+		Filename:         newFilename,
+		PreviousFilename: removeFileExtension(oldFilename),
+	}
+}
+
+func ArchiveRomUndoStep(game shared.Item, romDirectory shared.RomDirectory, archiveName string) models.UndoStep {
+	return models.UndoStep{
+		Kind:         models.UndoArchiveRom,
+		RomDirectory: romDirectory,
+		Filename:     game.Filename,
+		Archive:      archiveName,
+	}
+}
+
+// CollectionUndoStep snapshots the collection as it is on disk so it can be written back later.
+func CollectionUndoStep(collection models.Collection) models.UndoStep {
+	step := models.UndoStep{
+		Kind:           models.UndoRestoreCollection,
+		CollectionFile: collection.CollectionFile,
+	}
+
+	if loaded, err := ReadCollection(collection); err == nil {
+		collection = loaded
+	}
+
+	for _, game := range collection.Games {
+		step.CollectionEntries = append(step.CollectionEntries, normalizeCollectionGamePath(game))
+	}
+
+	return step
+}
+
+// RecentlyPlayedUndoStep remembers the recently played list as it is now so changes to it can be undone.
+func RecentlyPlayedUndoStep() models.UndoStep {
+	step := models.UndoStep{Kind: models.UndoRestoreRecents}
+
+	if lines, err := readReferenceLines(GetRecentlyPlayedFile()); err == nil {
+		step.RecentsLines = lines
+	}
+
+	return step
+}
+
+func RenameCollectionUndoStep(previous models.Collection, renamed models.Collection) models.UndoStep {
+	return models.UndoStep{
+		Kind:         models.UndoRenameCollection,
+		Path:         renamed.CollectionFile,
+		PreviousPath: previous.CollectionFile,
+	}
+}
+
+func RenameArchiveUndoStep(previousPath string, newPath string) models.UndoStep {
+	return models.UndoStep{
+		Kind:         models.UndoRenameArchive,
+		Path:         newPath,
+		PreviousPath: previousPath,
+	}
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	shared "github.com/UncleJunVIP/nextui-pak-shared-functions/models"
+	"path/filepath"
+	"testing"
+)
+
+func TestUndoRenameAndArchive(t *testing.T) {
+	useFakeSDCard(t)
+
+	romPath := filepath.Join(testPlatform, "Tetris.gb")
+	writeTestFile(t, romPath, "rom")
+
+	game := shared.Item{DisplayName: "Tetris", Filename: "Tetris.gb", Path: romPath}
+	filename, err := RenameRom(game, "Tetris DX", testRomDirectory)
+	if err != nil {
+		t.Fatalf("RenameRom: %v", err)
+	}
+	RecordUndo("Rename Tetris", RenameRomUndoStep(testRomDirectory, game.Filename, filename))
+
+	renamed := shared.Item{DisplayName: "Tetris DX", Filename: filename, Path: filepath.Join(testPlatform, filename)}
+	if err := ArchiveRom(renamed, testRomDirectory, "Old", false); err != nil {
+		t.Fatalf("ArchiveRom: %v", err)
+	}
+	RecordUndo("Archive Tetris DX", ArchiveRomUndoStep(renamed, testRomDirectory, "Old"))
+	assertExists(t, filepath.Join(testPak, undoHistoryFile))
+
+	history, err := LoadUndoHistory()
+	if err != nil {
+		t.Fatalf("LoadUndoHistory: %v", err)
+	}
+	if len(history) != 2 || history[0].Description != "Archive Tetris DX" {
+		t.Fatalf("undo history %+v, want the archive first", history)
+	}
+
+	for _, entry := range history {
+		if err := UndoAction(entry); err != nil {
+			t.Fatalf("undoing %s: %v", entry.Description, err)
+		}
+	}
+
+	assertExists(t, romPath)
+	assertMissing(t, renamed.Path, filepath.Join(testRoms, ".Old", "Game Boy (GB)", filename))
+	if history, _ := LoadUndoHistory(); len(history) != 0 {
+		t.Errorf("undo history still holds %+v", history)
+	}
+}